@@ -7,3 +7,5 @@ require (
 	github.com/google/flatbuffers v23.5.26+incompatible
 	github.com/paulmach/orb v0.10.0
 )
+
+require go.mongodb.org/mongo-driver v1.11.4 // indirect
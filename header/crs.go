@@ -50,7 +50,7 @@ func (crs *CRS) ToBuilder(b *flatbuffers.Builder) flatbuffers.UOffsetT {
 		}
 		if crs.CodeString != "" {
 			offset := b.CreateString(crs.CodeString)
-			defer flat.CrsAddWkt(b, offset)
+			defer flat.CrsAddCodeString(b, offset)
 		}
 		flat.CrsStart(b)
 	}()
@@ -1,6 +1,7 @@
 package header
 
 import (
+	"github.com/gogama/flatgeobuf-convert/interop"
 	"github.com/gogama/flatgeobuf-convert/props"
 	"github.com/gogama/flatgeobuf/flatgeobuf"
 	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
@@ -29,21 +30,131 @@ type Header struct {
 	Metadata      *string
 }
 
-func HeaderFromFlat(hdr flat.Header) *Header {
-	return nil // TODO
+// HeaderFromFlat converts a FlatGeobuf header table into a Header. It
+// returns an error, rather than panicking, if the table is corrupt.
+func HeaderFromFlat(hdr *flat.Header) (*Header, error) {
+	var result Header
+	err := interop.FlatBufferSafe(func() error {
+		result.Name = optString(hdr.Name())
+		// Grow the envelope as it is read, since the length of a corrupt
+		// vector may be far larger than its buffer.
+		for j, n := 0, hdr.EnvelopeLength(); j < n; j++ {
+			result.Envelope = append(result.Envelope, hdr.Envelope(j))
+		}
+		result.GeometryType = hdr.GeometryType()
+		result.HasZ = hdr.HasZ()
+		result.HasM = hdr.HasM()
+		result.HasT = hdr.HasT()
+		result.HasTM = hdr.HasTm()
+		schema, err := props.SchemaFromFlat(hdr)
+		if err != nil {
+			return err
+		}
+		result.Schema = schema
+		result.FeaturesCount = hdr.FeaturesCount()
+		indexNodeSize := hdr.IndexNodeSize()
+		result.IndexNodeSize = &indexNodeSize
+		var obj flat.Crs
+		if crs := hdr.Crs(&obj); crs != nil {
+			if result.CRS, err = CRSFromFlat(crs); err != nil {
+				return err
+			}
+		}
+		result.Title = optString(hdr.Title())
+		result.Description = optString(hdr.Description())
+		result.Metadata = optString(hdr.Metadata())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
 }
 
+// optString returns a pointer to a copy of b as a string, or nil if b
+// is nil, which is how an absent string field of a table reads.
+func optString(b []byte) *string {
+	if b == nil {
+		return nil
+	}
+	s := string(b)
+	return &s
+}
+
+// ToFlat converts the Header into a FlatGeobuf header table, backed by
+// a newly built buffer.
 func (hdr *Header) ToFlat() *flat.Header {
-	return &flat.Header{} // TODO
+	b := flatbuffers.NewBuilder(0)
+	flat.FinishHeaderBuffer(b, hdr.ToBuilder(b))
+	return flat.GetRootAsHeader(b.FinishedBytes(), 0)
 }
-func (hdr *Header) ToBuilder(b flatbuffers.Builder) flatbuffers.UOffsetT {
-	return 0 // TODO
+
+// ToBuilder writes the Header to b as a flat.Header table. Nil pointer
+// and slice fields are not written, so they read back as absent, and
+// a nil IndexNodeSize reads back as FlatGeobuf's default node size.
+func (hdr *Header) ToBuilder(b *flatbuffers.Builder) flatbuffers.UOffsetT {
+	func() {
+		if hdr.Name != nil {
+			offset := b.CreateString(*hdr.Name)
+			defer flat.HeaderAddName(b, offset)
+		}
+		if hdr.Envelope != nil {
+			flat.HeaderStartEnvelopeVector(b, len(hdr.Envelope))
+			for j := len(hdr.Envelope) - 1; j >= 0; j-- {
+				b.PrependFloat64(hdr.Envelope[j])
+			}
+			offset := b.EndVector(len(hdr.Envelope))
+			defer flat.HeaderAddEnvelope(b, offset)
+		}
+		defer func() {
+			flat.HeaderAddGeometryType(b, hdr.GeometryType)
+			flat.HeaderAddHasZ(b, hdr.HasZ)
+			flat.HeaderAddHasM(b, hdr.HasM)
+			flat.HeaderAddHasT(b, hdr.HasT)
+			flat.HeaderAddHasTm(b, hdr.HasTM)
+			flat.HeaderAddFeaturesCount(b, hdr.FeaturesCount)
+			if hdr.IndexNodeSize != nil {
+				flat.HeaderAddIndexNodeSize(b, *hdr.IndexNodeSize)
+			}
+		}()
+		if hdr.Schema != nil {
+			offset := hdr.Schema.ToBuilder(b)
+			defer flat.HeaderAddColumns(b, offset)
+		}
+		if hdr.CRS != nil {
+			offset := hdr.CRS.ToBuilder(b)
+			defer flat.HeaderAddCrs(b, offset)
+		}
+		if hdr.Title != nil {
+			offset := b.CreateString(*hdr.Title)
+			defer flat.HeaderAddTitle(b, offset)
+		}
+		if hdr.Description != nil {
+			offset := b.CreateString(*hdr.Description)
+			defer flat.HeaderAddDescription(b, offset)
+		}
+		if hdr.Metadata != nil {
+			offset := b.CreateString(*hdr.Metadata)
+			defer flat.HeaderAddMetadata(b, offset)
+		}
+		flat.HeaderStart(b)
+	}()
+	return flat.HeaderEnd(b)
 }
 
+// ColumnsLength implements flatgeobuf.Schema. A Header with a nil
+// Schema has no columns.
 func (hdr *Header) ColumnsLength() int {
-	return hdr.ColumnsLength() // TODO: is there a nil case?
+	if hdr.Schema == nil {
+		return 0
+	}
+	return hdr.Schema.ColumnsLength()
 }
 
+// Columns implements flatgeobuf.Schema.
 func (hdr *Header) Columns(obj *flat.Column, j int) bool {
-	return hdr.Columns(obj, j) // TODO: is there a nil case?
+	if hdr.Schema == nil {
+		return false
+	}
+	return hdr.Schema.Columns(obj, j)
 }
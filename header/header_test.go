@@ -0,0 +1,139 @@
+package header
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gogama/flatgeobuf-convert/interop"
+	"github.com/gogama/flatgeobuf-convert/props"
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// testColumns are the columns of testHeader. Every width, precision,
+// and scale is set, since unset ones read back as -1.
+var testColumns = []props.Column{
+	{Name: "id", Type: flat.ColumnTypeLong, Width: 20, Precision: 19, Scale: 0, Required: true, Unique: true, PrimaryKey: true},
+	{Name: "name", Type: flat.ColumnTypeString, Width: 32, Precision: -1, Scale: -1, Title: "Name", Description: "Street name", Metadata: `{"lang":"en"}`},
+}
+
+// testHeader returns a Header with every field set.
+func testHeader() *Header {
+	return &Header{
+		Name:          interop.AddrString("roads"),
+		Envelope:      []float64{-1, -2, 3, 4},
+		GeometryType:  flat.GeometryTypeLineString,
+		HasZ:          true,
+		HasM:          true,
+		HasT:          true,
+		HasTM:         true,
+		Schema:        props.NewSchema(testColumns),
+		FeaturesCount: 12,
+		IndexNodeSize: interop.AddrUInt16(0),
+		CRS: &CRS{
+			Org:         "EPSG",
+			Code:        4326,
+			Name:        "WGS 84",
+			Description: "World Geodetic System 1984",
+			WKT:         `GEOGCS["WGS 84"]`,
+			CodeString:  "4326",
+		},
+		Title:       interop.AddrString("Roads"),
+		Description: interop.AddrString("All roads"),
+		Metadata:    interop.AddrString(`{"source":"test"}`),
+	}
+}
+
+// checkHeader checks that got has the same fields as want, comparing
+// schemas by their columns.
+func checkHeader(t *testing.T, got, want *Header) {
+	t.Helper()
+	if got.Schema.ColumnsLength() != want.Schema.ColumnsLength() {
+		t.Fatalf("got %d columns, want %d", got.Schema.ColumnsLength(), want.Schema.ColumnsLength())
+	}
+	for j := 0; j < want.Schema.ColumnsLength(); j++ {
+		if g, w := got.Schema.Column(j), want.Schema.Column(j); g != w {
+			t.Errorf("column %d:\n got %+v\nwant %+v", j, g, w)
+		}
+	}
+	g, w := *got, *want
+	g.Schema, w.Schema = nil, nil
+	if !reflect.DeepEqual(g, w) {
+		t.Errorf("got %+v, want %+v", g, w)
+	}
+}
+
+func TestHeaderRoundTrip(t *testing.T) {
+	want := testHeader()
+	got, err := HeaderFromFlat(want.ToFlat())
+	if err != nil {
+		t.Fatalf("HeaderFromFlat: %v", err)
+	}
+	checkHeader(t, got, want)
+
+	// ToBuilder writes a table which can be embedded in a larger buffer.
+	b := flatbuffers.NewBuilder(0)
+	flat.FinishSizePrefixedHeaderBuffer(b, want.ToBuilder(b))
+	got, err = HeaderFromFlat(flat.GetSizePrefixedRootAsHeader(b.FinishedBytes(), 0))
+	if err != nil {
+		t.Fatalf("HeaderFromFlat: %v", err)
+	}
+	checkHeader(t, got, want)
+}
+
+func TestHeaderRoundTripEmpty(t *testing.T) {
+	got, err := HeaderFromFlat((&Header{}).ToFlat())
+	if err != nil {
+		t.Fatalf("HeaderFromFlat: %v", err)
+	}
+	// An unset index node size reads as the FlatGeobuf default.
+	want := &Header{Schema: props.NewSchema(nil), IndexNodeSize: interop.AddrUInt16(16)}
+	checkHeader(t, got, want)
+}
+
+func TestHeaderFromFlatCorrupt(t *testing.T) {
+	data := testHeader().ToFlat().Table().Bytes
+	var errs int
+	b := make([]byte, len(data))
+	for i := range data {
+		for _, x := range []byte{0x00, 0x7f, 0xff} {
+			copy(b, data)
+			b[i] = x
+			// HeaderFromFlat must return an error rather than panic.
+			if _, err := HeaderFromFlat(flat.GetRootAsHeader(b, 0)); err != nil {
+				errs++
+			}
+		}
+	}
+	if errs == 0 {
+		t.Error("no corrupt header produced an error")
+	}
+}
+
+func TestHeaderColumns(t *testing.T) {
+	var hdr Header
+	var obj flat.Column
+	if hdr.ColumnsLength() != 0 || hdr.Columns(&obj, 0) {
+		t.Errorf("nil Schema: got %d columns", hdr.ColumnsLength())
+	}
+	hdr.Schema = props.NewSchema(testColumns)
+	if hdr.ColumnsLength() != len(testColumns) {
+		t.Errorf("got %d columns, want %d", hdr.ColumnsLength(), len(testColumns))
+	}
+	for j := range testColumns {
+		if !hdr.Columns(&obj, j) || string(obj.Name()) != testColumns[j].Name {
+			t.Errorf("column %d: got %q, want %q", j, obj.Name(), testColumns[j].Name)
+		}
+	}
+	if hdr.Columns(&obj, len(testColumns)) || hdr.Columns(&obj, -1) {
+		t.Error("Columns: got true for out of range index")
+	}
+
+	// A Header is itself a flatgeobuf.Schema.
+	schema, err := props.SchemaFromFlat(&hdr)
+	if err != nil {
+		t.Fatalf("SchemaFromFlat: %v", err)
+	} else if schema.ColumnsLength() != len(testColumns) || schema.Column(1) != testColumns[1] {
+		t.Errorf("got schema %v", schema)
+	}
+}
@@ -1,6 +1,11 @@
 package orbgeometry
 
 import (
+	"errors"
+	"fmt"
+
+	"github.com/gogama/flatgeobuf-convert/header"
+	"github.com/gogama/flatgeobuf-convert/interop"
 	"github.com/gogama/flatgeobuf-convert/props"
 	"github.com/gogama/flatgeobuf/flatgeobuf"
 	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
@@ -8,29 +13,400 @@ import (
 	"github.com/paulmach/orb"
 )
 
+// Conversion between FlatGeobuf geometries and orb geometries.
+//
+// orb geometries are two-dimensional, so Z, M, T, and TM values are
+// discarded when reading a FlatGeobuf geometry, and never written. The
+// curve, surface, and triangle geometry types have no orb equivalent.
+
+var (
+	// ErrUnknownType is returned when a feature geometry has no type of
+	// its own and no header was given to supply one.
+	ErrUnknownType = errors.New("orbgeometry: geometry type is unknown")
+	// ErrUnsupported is returned for a geometry type which has no
+	// equivalent in the other package.
+	ErrUnsupported = errors.New("orbgeometry: unsupported geometry type")
+	// ErrInvalid is returned for a FlatGeobuf geometry whose structure
+	// is inconsistent, such as an odd number of XY values or ring ends
+	// which overrun the coordinates.
+	ErrInvalid = errors.New("orbgeometry: invalid geometry")
+)
+
+// FromFlat converts the geometry of a FlatGeobuf feature into an orb
+// geometry. The return value is nil if the feature has no geometry.
+//
+// A feature geometry only has a type of its own if the header geometry
+// type is Unknown, so FromFlat returns an error wrapping ErrUnknownType
+// for the features of files with a single geometry type. Use
+// FromFlatProps with the header for those.
 func FromFlat(f *flat.Feature) (orb.Geometry, error) {
-	return nil, nil
+	var g orb.Geometry
+	err := interop.FlatBufferSafe(func() (err error) {
+		g, err = geometryFromFeature(f, flat.GeometryTypeUnknown)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
 }
 
+// FromFlatProps converts the geometry and properties of a FlatGeobuf
+// feature. The schema s gives the columns of the property buffer: it is
+// either the header of the file, or nil to use the columns of the
+// feature itself. If s is a *flat.Header or a *header.Header, its
+// geometry type is used for a feature geometry which has none.
 func FromFlatProps(f *flat.Feature, s flatgeobuf.Schema) (orb.Geometry, *props.Props, error) {
-	// s can be either 'f' repeated, or a header.
-	return nil, nil, nil
+	t := flat.GeometryTypeUnknown
+	var g orb.Geometry
+	var p *props.Props
+	err := interop.FlatBufferSafe(func() (err error) {
+		switch hdr := s.(type) {
+		case nil:
+			s = f
+		case *flat.Header:
+			t = hdr.GeometryType()
+		case *header.Header:
+			t = hdr.GeometryType
+		}
+		if g, err = geometryFromFeature(f, t); err != nil {
+			return err
+		}
+		p = props.PropsFromFlat(s, f.PropertiesBytes())
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return g, p, nil
+}
+
+// ToFlat converts an orb geometry into a FlatGeobuf feature, backed by
+// a newly built buffer, with no properties. A nil geometry gives a
+// feature with no geometry.
+func ToFlat(g orb.Geometry) (*flat.Feature, error) {
+	return ToFlatProps(g, nil, false)
+}
+
+// ToFlatProps converts an orb geometry and property values into a
+// FlatGeobuf feature, backed by a newly built buffer. If putSchema is
+// true, the columns of the schema of p are written into the feature,
+// which FlatGeobuf allows for features whose schema differs from the
+// header. A nil p gives a feature with no properties.
+func ToFlatProps(g orb.Geometry, p *props.Props, putSchema bool) (*flat.Feature, error) {
+	b := flatbuffers.NewBuilder(0)
+	offset, err := ToBuilderProps(b, g, p, putSchema)
+	if err != nil {
+		return nil, err
+	}
+	flat.FinishFeatureBuffer(b, offset)
+	return flat.GetRootAsFeature(b.FinishedBytes(), 0), nil
+}
+
+// ToBuilder writes an orb geometry to b as a flat.Feature table with no
+// properties, and returns its offset.
+func ToBuilder(b *flatbuffers.Builder, g orb.Geometry) (flatbuffers.UOffsetT, error) {
+	return ToBuilderProps(b, g, nil, false)
+}
+
+// ToBuilderProps writes an orb geometry and property values to b as a
+// flat.Feature table, in the same way as ToFlatProps, and returns its
+// offset.
+func ToBuilderProps(b *flatbuffers.Builder, g orb.Geometry, p *props.Props, putSchema bool) (flatbuffers.UOffsetT, error) {
+	var geometry, properties, columns flatbuffers.UOffsetT
+	var err error
+	if g != nil {
+		if geometry, err = geometryToBuilder(b, g); err != nil {
+			return 0, err
+		}
+	}
+	if p != nil {
+		if properties, err = p.ToBuilder(b); err != nil {
+			return 0, err
+		}
+		if putSchema {
+			schema := p.Schema()
+			if schema == nil {
+				return 0, errors.New("orbgeometry: props schema cannot be converted to columns")
+			}
+			columns = schema.ToBuilder(b)
+		}
+	}
+	flat.FeatureStart(b)
+	if geometry != 0 {
+		flat.FeatureAddGeometry(b, geometry)
+	}
+	if properties != 0 {
+		flat.FeatureAddProperties(b, properties)
+	}
+	if columns != 0 {
+		flat.FeatureAddColumns(b, columns)
+	}
+	return flat.FeatureEnd(b), nil
+}
+
+func geometryFromFeature(f *flat.Feature, t flat.GeometryType) (orb.Geometry, error) {
+	var obj flat.Geometry
+	g := f.Geometry(&obj)
+	if g == nil {
+		return nil, nil
+	}
+	return geometryFromFlat(g, t)
+}
+
+// geometryFromFlat converts a geometry table whose type, if the table
+// does not give one, is t.
+func geometryFromFlat(g *flat.Geometry, t flat.GeometryType) (orb.Geometry, error) {
+	if own := g.Type(); own != flat.GeometryTypeUnknown {
+		t = own
+	}
+	switch t {
+	case flat.GeometryTypeUnknown:
+		return nil, ErrUnknownType
+	case flat.GeometryTypePoint:
+		points, err := pointsFromFlat(g)
+		if err != nil {
+			return nil, err
+		} else if len(points) != 1 {
+			return nil, fmt.Errorf("%w: point has %d coordinates", ErrInvalid, len(points))
+		}
+		return points[0], nil
+	case flat.GeometryTypeMultiPoint:
+		points, err := pointsFromFlat(g)
+		return orb.MultiPoint(points), err
+	case flat.GeometryTypeLineString:
+		points, err := pointsFromFlat(g)
+		return orb.LineString(points), err
+	case flat.GeometryTypeMultiLineString:
+		parts, err := splitFromFlat(g)
+		if err != nil {
+			return nil, err
+		}
+		mls := make(orb.MultiLineString, len(parts))
+		for i := range parts {
+			mls[i] = orb.LineString(parts[i])
+		}
+		return mls, nil
+	case flat.GeometryTypePolygon:
+		return polygonFromFlat(g)
+	case flat.GeometryTypeMultiPolygon:
+		n, err := partsLength(g)
+		if err != nil {
+			return nil, err
+		}
+		mp := make(orb.MultiPolygon, 0, n)
+		var part flat.Geometry
+		for j := 0; j < n; j++ {
+			g.Parts(&part, j)
+			if pt := part.Type(); pt != flat.GeometryTypeUnknown && pt != flat.GeometryTypePolygon {
+				return nil, fmt.Errorf("%w: multipolygon part %d has type %s", ErrInvalid, j, pt)
+			}
+			polygon, err := polygonFromFlat(&part)
+			if err != nil {
+				return nil, err
+			}
+			mp = append(mp, polygon)
+		}
+		return mp, nil
+	case flat.GeometryTypeGeometryCollection:
+		n, err := partsLength(g)
+		if err != nil {
+			return nil, err
+		}
+		c := make(orb.Collection, 0, n)
+		var part flat.Geometry
+		for j := 0; j < n; j++ {
+			g.Parts(&part, j)
+			geometry, err := geometryFromFlat(&part, flat.GeometryTypeUnknown)
+			if err != nil {
+				return nil, err
+			}
+			c = append(c, geometry)
+		}
+		return c, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, t)
+	}
+}
+
+func polygonFromFlat(g *flat.Geometry) (orb.Polygon, error) {
+	parts, err := splitFromFlat(g)
+	if err != nil {
+		return nil, err
+	}
+	polygon := make(orb.Polygon, len(parts))
+	for i := range parts {
+		polygon[i] = orb.Ring(parts[i])
+	}
+	return polygon, nil
+}
+
+// pointsFromFlat returns the XY coordinates of a geometry table.
+func pointsFromFlat(g *flat.Geometry) ([]orb.Point, error) {
+	n := g.XyLength()
+	if n%2 != 0 {
+		return nil, fmt.Errorf("%w: odd number of XY values: %d", ErrInvalid, n)
+	} else if n > len(g.Table().Bytes)/flatbuffers.SizeFloat64 {
+		// Don't trust a corrupt length to size the allocation.
+		return nil, fmt.Errorf("%w: %d XY values overrun the buffer", ErrInvalid, n)
+	}
+	points := make([]orb.Point, n/2)
+	for i := range points {
+		points[i] = orb.Point{g.Xy(2 * i), g.Xy(2*i + 1)}
+	}
+	return points, nil
+}
+
+// splitFromFlat returns the XY coordinates of a geometry table split
+// into parts at its ends, or as one part if it has no ends.
+func splitFromFlat(g *flat.Geometry) ([][]orb.Point, error) {
+	points, err := pointsFromFlat(g)
+	if err != nil {
+		return nil, err
+	}
+	n := g.EndsLength()
+	if n == 0 {
+		return [][]orb.Point{points}, nil
+	} else if n > len(points) {
+		return nil, fmt.Errorf("%w: %d ends for %d coordinates", ErrInvalid, n, len(points))
+	}
+	parts := make([][]orb.Point, n)
+	start := 0
+	for j := range parts {
+		end := int(g.Ends(j))
+		if end <= start || end > len(points) {
+			return nil, fmt.Errorf("%w: end %d is %d, after %d, with %d coordinates", ErrInvalid, j, end, start, len(points))
+		}
+		parts[j] = points[start:end:end]
+		start = end
+	}
+	if start != len(points) {
+		return nil, fmt.Errorf("%w: last end is %d, with %d coordinates", ErrInvalid, start, len(points))
+	}
+	return parts, nil
+}
+
+func partsLength(g *flat.Geometry) (int, error) {
+	n := g.PartsLength()
+	if n > len(g.Table().Bytes)/flatbuffers.SizeUOffsetT {
+		return 0, fmt.Errorf("%w: %d parts overrun the buffer", ErrInvalid, n)
+	}
+	return n, nil
+}
+
+// geometryToBuilder writes an orb geometry to b as a flat.Geometry
+// table. A Ring is written as a Polygon, and a Bound as the Polygon it
+// covers.
+func geometryToBuilder(b *flatbuffers.Builder, g orb.Geometry) (flatbuffers.UOffsetT, error) {
+	switch g := g.(type) {
+	case orb.Point:
+		return simpleToBuilder(b, flat.GeometryTypePoint, []orb.Point{g}), nil
+	case orb.MultiPoint:
+		return simpleToBuilder(b, flat.GeometryTypeMultiPoint, g), nil
+	case orb.LineString:
+		return simpleToBuilder(b, flat.GeometryTypeLineString, g), nil
+	case orb.MultiLineString:
+		parts := make([][]orb.Point, len(g))
+		for i := range g {
+			parts[i] = g[i]
+		}
+		return splitToBuilder(b, flat.GeometryTypeMultiLineString, parts), nil
+	case orb.Ring:
+		return polygonToBuilder(b, orb.Polygon{g}), nil
+	case orb.Polygon:
+		return polygonToBuilder(b, g), nil
+	case orb.Bound:
+		return polygonToBuilder(b, g.ToPolygon()), nil
+	case orb.MultiPolygon:
+		parts := make([]flatbuffers.UOffsetT, len(g))
+		for i := range g {
+			parts[i] = polygonToBuilder(b, g[i])
+		}
+		return partsToBuilder(b, flat.GeometryTypeMultiPolygon, parts), nil
+	case orb.Collection:
+		parts := make([]flatbuffers.UOffsetT, len(g))
+		for i := range g {
+			var err error
+			if parts[i], err = geometryToBuilder(b, g[i]); err != nil {
+				return 0, err
+			}
+		}
+		return partsToBuilder(b, flat.GeometryTypeGeometryCollection, parts), nil
+	default:
+		return 0, fmt.Errorf("%w: %T", ErrUnsupported, g)
+	}
+}
+
+func polygonToBuilder(b *flatbuffers.Builder, polygon orb.Polygon) flatbuffers.UOffsetT {
+	parts := make([][]orb.Point, len(polygon))
+	for i := range polygon {
+		parts[i] = polygon[i]
+	}
+	return splitToBuilder(b, flat.GeometryTypePolygon, parts)
 }
 
-func ToFlat(g orb.Geometry) flat.Feature {
-	return flat.Feature{}
+func simpleToBuilder(b *flatbuffers.Builder, t flat.GeometryType, points []orb.Point) flatbuffers.UOffsetT {
+	xy := xyToBuilder(b, points)
+	flat.GeometryStart(b)
+	if xy != 0 {
+		flat.GeometryAddXy(b, xy)
+	}
+	flat.GeometryAddType(b, t)
+	return flat.GeometryEnd(b)
 }
 
-func ToFlatProps(g orb.Geometry, p *props.Props, putSchema bool) flat.Feature {
-	// putSchema tells you whether the schema should be echoed into the
-	// feature, or omitted.
-	return flat.Feature{}
+// splitToBuilder writes a geometry table whose coordinates are split
+// into parts. The ends are only written if there is more than one part,
+// as FlatGeobuf allows.
+func splitToBuilder(b *flatbuffers.Builder, t flat.GeometryType, parts [][]orb.Point) flatbuffers.UOffsetT {
+	var points []orb.Point
+	for _, part := range parts {
+		points = append(points, part...)
+	}
+	var ends flatbuffers.UOffsetT
+	if len(parts) > 1 {
+		flat.GeometryStartEndsVector(b, len(parts))
+		end := len(points)
+		for i := len(parts) - 1; i >= 0; i-- {
+			b.PrependUint32(uint32(end))
+			end -= len(parts[i])
+		}
+		ends = b.EndVector(len(parts))
+	}
+	xy := xyToBuilder(b, points)
+	flat.GeometryStart(b)
+	if ends != 0 {
+		flat.GeometryAddEnds(b, ends)
+	}
+	if xy != 0 {
+		flat.GeometryAddXy(b, xy)
+	}
+	flat.GeometryAddType(b, t)
+	return flat.GeometryEnd(b)
 }
 
-func ToBuilder(b flatbuffers.Builder, g orb.Geometry) flatbuffers.UOffsetT {
-	return 0
+func partsToBuilder(b *flatbuffers.Builder, t flat.GeometryType, parts []flatbuffers.UOffsetT) flatbuffers.UOffsetT {
+	b.StartVector(flatbuffers.SizeUOffsetT, len(parts), flatbuffers.SizeUOffsetT)
+	for i := len(parts) - 1; i >= 0; i-- {
+		b.PrependUOffsetT(parts[i])
+	}
+	vector := b.EndVector(len(parts))
+	flat.GeometryStart(b)
+	flat.GeometryAddType(b, t)
+	flat.GeometryAddParts(b, vector)
+	return flat.GeometryEnd(b)
 }
 
-func ToBuilderProps(b flatbuffers.Builder, g orb.Geometry, p *props.Props, putSchema bool) flatbuffers.UOffsetT {
-	return 0
+// xyToBuilder writes the XY vector of points, or nothing if there are
+// no points.
+func xyToBuilder(b *flatbuffers.Builder, points []orb.Point) flatbuffers.UOffsetT {
+	if len(points) == 0 {
+		return 0
+	}
+	flat.GeometryStartXyVector(b, 2*len(points))
+	for i := len(points) - 1; i >= 0; i-- {
+		b.PrependFloat64(points[i][1])
+		b.PrependFloat64(points[i][0])
+	}
+	return b.EndVector(2 * len(points))
 }
@@ -0,0 +1,251 @@
+package orbgeometry
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gogama/flatgeobuf-convert/header"
+	"github.com/gogama/flatgeobuf-convert/props"
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+	flatbuffers "github.com/google/flatbuffers/go"
+	"github.com/paulmach/orb"
+)
+
+var (
+	square = orb.Ring{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}
+	hole   = orb.Ring{{1, 1}, {2, 1}, {2, 2}, {1, 1}}
+	island = orb.Ring{{10, 10}, {11, 10}, {11, 11}, {10, 10}}
+)
+
+// testGeometries are geometries of every supported type, and the flat
+// geometry type they are written as.
+var testGeometries = []struct {
+	name string
+	g    orb.Geometry
+	t    flat.GeometryType
+}{
+	{"point", orb.Point{1.5, -2}, flat.GeometryTypePoint},
+	{"multipoint", orb.MultiPoint{{1, 2}, {3, 4}}, flat.GeometryTypeMultiPoint},
+	{"empty multipoint", orb.MultiPoint{}, flat.GeometryTypeMultiPoint},
+	{"linestring", orb.LineString{{1, 2}, {3, 4}, {5, 6}}, flat.GeometryTypeLineString},
+	{"multilinestring", orb.MultiLineString{{{1, 2}, {3, 4}}, {{5, 6}, {7, 8}, {9, 10}}}, flat.GeometryTypeMultiLineString},
+	{"single multilinestring", orb.MultiLineString{{{1, 2}, {3, 4}}}, flat.GeometryTypeMultiLineString},
+	{"polygon", orb.Polygon{square}, flat.GeometryTypePolygon},
+	{"polygon with hole", orb.Polygon{square, hole}, flat.GeometryTypePolygon},
+	{"multipolygon", orb.MultiPolygon{{square, hole}, {island}}, flat.GeometryTypeMultiPolygon},
+	{"collection", orb.Collection{orb.Point{1, 2}, orb.Polygon{square, hole}, orb.Collection{orb.LineString{{0, 0}, {1, 1}}}}, flat.GeometryTypeGeometryCollection},
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, testCase := range testGeometries {
+		t.Run(testCase.name, func(t *testing.T) {
+			f, err := ToFlat(testCase.g)
+			if err != nil {
+				t.Fatalf("ToFlat: %v", err)
+			}
+			if got := f.Geometry(nil).Type(); got != testCase.t {
+				t.Errorf("got flat type %s, want %s", got, testCase.t)
+			}
+			g, err := FromFlat(f)
+			if err != nil {
+				t.Fatalf("FromFlat: %v", err)
+			} else if !reflect.DeepEqual(g, testCase.g) {
+				t.Errorf("got %#v, want %#v", g, testCase.g)
+			}
+		})
+	}
+}
+
+func TestRingAndBound(t *testing.T) {
+	for _, g := range []orb.Geometry{square, orb.Bound{Min: orb.Point{0, 0}, Max: orb.Point{4, 4}}} {
+		f, err := ToFlat(g)
+		if err != nil {
+			t.Fatalf("ToFlat(%T): %v", g, err)
+		}
+		got, err := FromFlat(f)
+		if err != nil {
+			t.Fatalf("FromFlat: %v", err)
+		} else if polygon, ok := got.(orb.Polygon); !ok || len(polygon) != 1 || len(polygon[0]) != 5 {
+			t.Errorf("%T: got %#v, want a polygon", g, got)
+		}
+	}
+}
+
+func TestNoGeometry(t *testing.T) {
+	f, err := ToFlat(nil)
+	if err != nil {
+		t.Fatalf("ToFlat: %v", err)
+	}
+	if g, err := FromFlat(f); g != nil || err != nil {
+		t.Errorf("got %v, %v, want nil, nil", g, err)
+	}
+}
+
+type unsupported struct{ orb.Point }
+
+func TestUnsupported(t *testing.T) {
+	if _, err := ToFlat(unsupported{}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("ToFlat: got %v, want ErrUnsupported", err)
+	}
+	if _, err := ToFlat(orb.Collection{orb.Point{}, unsupported{}}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("ToFlat of collection: got %v, want ErrUnsupported", err)
+	}
+	f := featureWithGeometry(flat.GeometryTypeCircularString, []float64{0, 0, 1, 1, 2, 0}, nil)
+	if _, err := FromFlat(f); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("FromFlat: got %v, want ErrUnsupported", err)
+	}
+}
+
+// featureWithGeometry returns a feature whose geometry has type t, the
+// XY values xy, and the ends ends.
+func featureWithGeometry(t flat.GeometryType, xy []float64, ends []uint32) *flat.Feature {
+	b := flatbuffers.NewBuilder(0)
+	var endsVector flatbuffers.UOffsetT
+	if ends != nil {
+		flat.GeometryStartEndsVector(b, len(ends))
+		for i := len(ends) - 1; i >= 0; i-- {
+			b.PrependUint32(ends[i])
+		}
+		endsVector = b.EndVector(len(ends))
+	}
+	flat.GeometryStartXyVector(b, len(xy))
+	for i := len(xy) - 1; i >= 0; i-- {
+		b.PrependFloat64(xy[i])
+	}
+	xyVector := b.EndVector(len(xy))
+	flat.GeometryStart(b)
+	if ends != nil {
+		flat.GeometryAddEnds(b, endsVector)
+	}
+	flat.GeometryAddXy(b, xyVector)
+	flat.GeometryAddType(b, t)
+	geometry := flat.GeometryEnd(b)
+	flat.FeatureStart(b)
+	flat.FeatureAddGeometry(b, geometry)
+	flat.FinishFeatureBuffer(b, flat.FeatureEnd(b))
+	return flat.GetRootAsFeature(b.FinishedBytes(), 0)
+}
+
+func TestUnknownType(t *testing.T) {
+	f := featureWithGeometry(flat.GeometryTypeUnknown, []float64{0, 0, 1, 1}, nil)
+	if _, err := FromFlat(f); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("FromFlat: got %v, want ErrUnknownType", err)
+	}
+	want := orb.LineString{{0, 0}, {1, 1}}
+	hdr := &header.Header{GeometryType: flat.GeometryTypeLineString, Schema: props.NewSchema(nil)}
+	for _, s := range []any{hdr, hdr.ToFlat()} {
+		var g orb.Geometry
+		var err error
+		switch s := s.(type) {
+		case *header.Header:
+			g, _, err = FromFlatProps(f, s)
+		case *flat.Header:
+			g, _, err = FromFlatProps(f, s)
+		}
+		if err != nil || !reflect.DeepEqual(g, want) {
+			t.Errorf("FromFlatProps with %T: got %v, %v, want %v", s, g, err, want)
+		}
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	testCases := []struct {
+		name string
+		t    flat.GeometryType
+		xy   []float64
+		ends []uint32
+	}{
+		{"odd XY values", flat.GeometryTypeLineString, []float64{0, 0, 1}, nil},
+		{"point with two coordinates", flat.GeometryTypePoint, []float64{0, 0, 1, 1}, nil},
+		{"point with none", flat.GeometryTypePoint, []float64{}, nil},
+		{"ends overrun", flat.GeometryTypePolygon, []float64{0, 0, 1, 1}, []uint32{1, 3}},
+		{"ends short", flat.GeometryTypeMultiLineString, []float64{0, 0, 1, 1, 2, 2}, []uint32{1, 2}},
+		{"ends not increasing", flat.GeometryTypeMultiLineString, []float64{0, 0, 1, 1, 2, 2}, []uint32{2, 2, 3}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			f := featureWithGeometry(testCase.t, testCase.xy, testCase.ends)
+			if g, err := FromFlat(f); !errors.Is(err, ErrInvalid) {
+				t.Errorf("got %v, %v, want ErrInvalid", g, err)
+			}
+		})
+	}
+}
+
+func TestCorrupt(t *testing.T) {
+	f, err := ToFlat(testGeometries[len(testGeometries)-1].g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := f.Table().Bytes
+	b := make([]byte, len(data))
+	var errs int
+	for i := range data {
+		for _, x := range []byte{0x00, 0x7f, 0xff} {
+			copy(b, data)
+			b[i] = x
+			// FromFlat must return an error rather than panic.
+			if _, err := FromFlat(flat.GetRootAsFeature(b, 0)); err != nil {
+				errs++
+			}
+		}
+	}
+	if errs == 0 {
+		t.Error("no corrupt feature produced an error")
+	}
+}
+
+func TestProps(t *testing.T) {
+	schema := props.NewSchema([]props.Column{
+		{Name: "id", Type: flat.ColumnTypeLong},
+		{Name: "name", Type: flat.ColumnTypeString},
+	})
+	p := props.NewProps(schema)
+	if err := p.SetLong(0, 7); err != nil {
+		t.Fatal(err)
+	} else if err = p.SetString(1, "x"); err != nil {
+		t.Fatal(err)
+	}
+	point := orb.Point{1, 2}
+	for _, putSchema := range []bool{false, true} {
+		f, err := ToFlatProps(point, p, putSchema)
+		if err != nil {
+			t.Fatalf("putSchema %t: ToFlatProps: %v", putSchema, err)
+		}
+		if got := f.ColumnsLength(); putSchema != (got == 2) {
+			t.Errorf("putSchema %t: got %d feature columns", putSchema, got)
+		}
+		// With its own columns, a feature needs no header.
+		var s *header.Header
+		if !putSchema {
+			s = &header.Header{Schema: schema}
+		}
+		var g orb.Geometry
+		var q *props.Props
+		if s != nil {
+			g, q, err = FromFlatProps(f, s)
+		} else {
+			g, q, err = FromFlatProps(f, nil)
+		}
+		if err != nil {
+			t.Fatalf("putSchema %t: FromFlatProps: %v", putSchema, err)
+		} else if g != point {
+			t.Errorf("putSchema %t: got geometry %v, want %v", putSchema, g, point)
+		}
+		if equal, err := props.Equal(p, q); err != nil || !equal {
+			t.Errorf("putSchema %t: got props %v, %v, want equal", putSchema, q, err)
+		}
+	}
+
+	b := flatbuffers.NewBuilder(0)
+	offset, err := ToBuilderProps(b, nil, p, false)
+	if err != nil {
+		t.Fatalf("ToBuilderProps: %v", err)
+	}
+	flat.FinishSizePrefixedFeatureBuffer(b, offset)
+	f := flat.GetSizePrefixedRootAsFeature(b.FinishedBytes(), 0)
+	if g, q, err := FromFlatProps(f, schema); err != nil || g != nil || q == nil {
+		t.Errorf("FromFlatProps without geometry: got %v, %v, %v", g, q, err)
+	}
+}
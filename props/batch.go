@@ -0,0 +1,243 @@
+package props
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// Bitmap is a growable set of bits, indexed from zero, stored in 64-bit
+// words with bit i in word i/64 at position i%64.
+type Bitmap []uint64
+
+// Get reports whether bit i is set. Bits beyond the end of the bitmap
+// are not set.
+func (m Bitmap) Get(i int) bool {
+	w := i / 64
+	return w < len(m) && m[w]&(1<<(i%64)) != 0
+}
+
+// Count returns the number of set bits.
+func (m Bitmap) Count() int {
+	n := 0
+	for _, w := range m {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// set sets bit i, growing the bitmap if needed.
+func (m *Bitmap) set(i int) {
+	for i/64 >= len(*m) {
+		*m = append(*m, 0)
+	}
+	(*m)[i/64] |= 1 << (i % 64)
+}
+
+// truncate clears every bit from bit n onwards.
+func (m *Bitmap) truncate(n int) {
+	w := (n + 63) / 64
+	if w < len(*m) {
+		*m = (*m)[:w]
+	}
+	if n%64 != 0 && w > 0 && w <= len(*m) {
+		(*m)[w-1] &= 1<<(n%64) - 1
+	}
+}
+
+// Vector holds the values of one column for every row of a Batch.
+//
+// Only the value slice which matches the column type is used, and it
+// has one element per row: Bools for Bool columns, Int8s for Byte
+// columns, Uint8s for UByte columns, and so on up to Float64s for
+// Double columns. Strings holds the values of String, Json, and
+// DateTime columns, as their stored text, and Binaries holds the values
+// of Binary columns. Rows with no value have the zero value.
+type Vector struct {
+	// Type is the column type.
+	Type flat.ColumnType
+	// Valid has bit i set if row i has a value for the column. Rows
+	// where the column is absent or null have the bit clear.
+	Valid Bitmap
+
+	Bools    []bool
+	Int8s    []int8
+	Uint8s   []uint8
+	Int16s   []int16
+	Uint16s  []uint16
+	Int32s   []int32
+	Uint32s  []uint32
+	Int64s   []int64
+	Uint64s  []uint64
+	Float32s []float32
+	Float64s []float64
+	Strings  []string
+	Binaries [][]byte
+}
+
+// appendRaw appends the encoded value raw, or the zero value if raw is
+// nil, as the value of row i.
+func (v *Vector) appendRaw(i int, raw []byte) {
+	var zero [8]byte
+	if raw != nil {
+		v.Valid.set(i)
+	} else if !isText(v.Type) {
+		raw = zero[:]
+	}
+	switch v.Type {
+	case flat.ColumnTypeBool:
+		v.Bools = append(v.Bools, raw[0] != 0)
+	case flat.ColumnTypeByte:
+		v.Int8s = append(v.Int8s, int8(rawInt64(v.Type, raw)))
+	case flat.ColumnTypeUByte:
+		v.Uint8s = append(v.Uint8s, uint8(rawInt64(v.Type, raw)))
+	case flat.ColumnTypeShort:
+		v.Int16s = append(v.Int16s, int16(rawInt64(v.Type, raw)))
+	case flat.ColumnTypeUShort:
+		v.Uint16s = append(v.Uint16s, uint16(rawInt64(v.Type, raw)))
+	case flat.ColumnTypeInt:
+		v.Int32s = append(v.Int32s, int32(rawInt64(v.Type, raw)))
+	case flat.ColumnTypeUInt:
+		v.Uint32s = append(v.Uint32s, uint32(rawInt64(v.Type, raw)))
+	case flat.ColumnTypeLong:
+		v.Int64s = append(v.Int64s, rawInt64(v.Type, raw))
+	case flat.ColumnTypeULong:
+		v.Uint64s = append(v.Uint64s, rawUint64(v.Type, raw))
+	case flat.ColumnTypeFloat:
+		v.Float32s = append(v.Float32s, float32(rawFloat64(v.Type, raw)))
+	case flat.ColumnTypeDouble:
+		v.Float64s = append(v.Float64s, rawFloat64(v.Type, raw))
+	case flat.ColumnTypeString, flat.ColumnTypeJson, flat.ColumnTypeDateTime:
+		var s string
+		if raw != nil {
+			s = string(raw[flatbuffers.SizeUint32:])
+		}
+		v.Strings = append(v.Strings, s)
+	case flat.ColumnTypeBinary:
+		var b []byte
+		if raw != nil {
+			b = append([]byte{}, raw[flatbuffers.SizeUint32:]...)
+		}
+		v.Binaries = append(v.Binaries, b)
+	}
+}
+
+// truncate discards every row from row n onwards.
+func (v *Vector) truncate(n int) {
+	v.Valid.truncate(n)
+	v.Bools = truncate(v.Bools, n)
+	v.Int8s = truncate(v.Int8s, n)
+	v.Uint8s = truncate(v.Uint8s, n)
+	v.Int16s = truncate(v.Int16s, n)
+	v.Uint16s = truncate(v.Uint16s, n)
+	v.Int32s = truncate(v.Int32s, n)
+	v.Uint32s = truncate(v.Uint32s, n)
+	v.Int64s = truncate(v.Int64s, n)
+	v.Uint64s = truncate(v.Uint64s, n)
+	v.Float32s = truncate(v.Float32s, n)
+	v.Float64s = truncate(v.Float64s, n)
+	v.Strings = truncate(v.Strings, n)
+	v.Binaries = truncate(v.Binaries, n)
+}
+
+func truncate[T any](s []T, n int) []T {
+	if n < len(s) {
+		return s[:n]
+	}
+	return s
+}
+
+// Batch decodes the properties of many features into column-oriented
+// typed slices, one Vector per column of a schema, for vectorized
+// processing without the interface conversions of GetValue.
+//
+// Batch is not safe for concurrent use.
+type Batch struct {
+	schema  *Schema
+	n       int
+	vectors []Vector
+	// scratch is the Props used to read property buffers passed to
+	// AppendFlat.
+	scratch Props
+}
+
+// NewBatch creates an empty Batch for properties under schema. A nil
+// schema is treated as a schema with no columns.
+func NewBatch(schema *Schema) *Batch {
+	schema = orEmpty(schema)
+	b := &Batch{
+		schema:  schema,
+		vectors: make([]Vector, schema.ColumnsLength()),
+	}
+	for col := range b.vectors {
+		b.vectors[col].Type = schema.Type(col)
+	}
+	return b
+}
+
+// Schema returns the schema of the Batch.
+func (b *Batch) Schema() *Schema {
+	return b.schema
+}
+
+// Len returns the number of rows in the Batch.
+func (b *Batch) Len() int {
+	return b.n
+}
+
+// Vector returns the values of a column for every row in the Batch. The
+// returned Vector belongs to the Batch, and is only valid until the
+// next call to Append, AppendFlat, or Reset.
+func (b *Batch) Vector(col int) *Vector {
+	return &b.vectors[col]
+}
+
+// Append decodes p into a new row at the end of the Batch. The schema of
+// p must have the same column types as the schema of the Batch.
+//
+// Append returns an error, and leaves the Batch unchanged, if the
+// column types don't match or if the property buffer of p is corrupt.
+func (b *Batch) Append(p *Props) error {
+	if p.numColumns() != len(b.vectors) {
+		return fmt.Errorf("%w: props has %d columns but batch schema has %d", ErrSchemaMismatch, p.numColumns(), len(b.vectors))
+	}
+	for col := range b.vectors {
+		if t := p.columnType(col); t != b.vectors[col].Type {
+			return &TypeError{Feature: p.feature, Col: col, Name: p.columnName(col), Expected: b.vectors[col].Type, Actual: t}
+		}
+	}
+	for col := range b.vectors {
+		raw, err := p.rawValue(col)
+		if err != nil {
+			for k := 0; k < col; k++ {
+				b.vectors[k].truncate(b.n)
+			}
+			return err
+		}
+		b.vectors[col].appendRaw(b.n, raw)
+	}
+	b.n++
+	return nil
+}
+
+// AppendFlat decodes a property buffer under the schema of the Batch,
+// such as the properties of a feature read from a FlatGeobuf file, into
+// a new row at the end of the Batch, in the same way as Append. The
+// Batch does not retain data.
+func (b *Batch) AppendFlat(data []byte) error {
+	b.scratch.Reset(b.schema, data)
+	err := b.Append(&b.scratch)
+	b.scratch.Reset(b.schema, nil)
+	return err
+}
+
+// Reset removes every row from the Batch, keeping the memory allocated
+// for the vectors so it can be reused for the next batch.
+func (b *Batch) Reset() {
+	for col := range b.vectors {
+		b.vectors[col].truncate(0)
+	}
+	b.n = 0
+}
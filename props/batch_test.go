@@ -0,0 +1,238 @@
+package props
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+)
+
+func TestBitmap(t *testing.T) {
+	var m Bitmap
+	set := []int{0, 63, 64, 130}
+	for _, i := range set {
+		m.set(i)
+	}
+	check := func(name string, want ...int) {
+		t.Helper()
+		isSet := make(map[int]bool, len(want))
+		for _, i := range want {
+			isSet[i] = true
+		}
+		for i := 0; i < 200; i++ {
+			if m.Get(i) != isSet[i] {
+				t.Errorf("%s: bit %d: got %t, want %t", name, i, m.Get(i), isSet[i])
+			}
+		}
+		if m.Count() != len(want) {
+			t.Errorf("%s: got Count %d, want %d", name, m.Count(), len(want))
+		}
+	}
+	check("set", set...)
+	if len(m) != 3 {
+		t.Errorf("got %d words, want 3", len(m))
+	}
+	m.truncate(200)
+	check("truncate beyond end", set...)
+	m.truncate(64)
+	check("truncate at word boundary", 0, 63)
+	if len(m) != 1 {
+		t.Errorf("got %d words, want 1", len(m))
+	}
+	m.truncate(1)
+	check("truncate within word", 0)
+	m.set(130)
+	check("set after truncate", 0, 130)
+	m.truncate(0)
+	check("truncate to zero")
+	m.set(5)
+	check("set after truncate to zero", 5)
+}
+
+// batchSchema has a column of every type.
+var batchSchema = jsonSchema
+
+// batchRows are the rows appended to a Batch by TestBatch. A nil value
+// sets the column to null, and a missing one leaves it absent.
+var batchRows = []map[int]any{
+	{
+		0: true, 1: int8(-1), 2: uint8(2), 3: int16(-3), 4: uint16(4), 5: int32(-5), 6: uint32(6),
+		7: int64(-7), 8: uint64(8), 9: float32(9.5), 10: 10.5, 11: "eleven", 12: "[12]",
+		13: "2024-01-13", 14: []byte{14},
+	},
+	{0: nil, 1: nil, 5: nil, 11: nil, 14: nil},
+	{0: false, 3: int16(30), 8: uint64(80), 11: "", 12: "{}", 14: []byte{}},
+}
+
+// setRow sets the values of row in p.
+func setRow(t *testing.T, p *Props, row map[int]any) {
+	t.Helper()
+	for col, v := range row {
+		var err error
+		if s, ok := v.(string); ok && p.columnType(col) == flat.ColumnTypeJson {
+			err = p.SetJSON(col, s)
+		} else if ok && p.columnType(col) == flat.ColumnTypeDateTime {
+			err = p.SetDateTimeString(col, s)
+		} else {
+			err = p.SetValue(col, v)
+		}
+		if err != nil {
+			t.Fatalf("column %d: %v", col, err)
+		}
+	}
+}
+
+// checkBatch checks that every Vector of b holds the values of rows,
+// with the zero value and a clear validity bit where a row has none.
+func checkBatch(t *testing.T, b *Batch, rows []map[int]any) {
+	t.Helper()
+	if b.Len() != len(rows) {
+		t.Fatalf("got Len %d, want %d", b.Len(), len(rows))
+	}
+	for col := 0; col < b.Schema().ColumnsLength(); col++ {
+		v := b.Vector(col)
+		if v.Type != b.Schema().Type(col) {
+			t.Errorf("column %d: got type %s, want %s", col, v.Type, b.Schema().Type(col))
+		}
+		values := reflect.ValueOf(*v).FieldByName(vectorField[v.Type])
+		if values.Len() != len(rows) {
+			t.Errorf("column %d: got %d values, want %d", col, values.Len(), len(rows))
+			continue
+		}
+		for i, row := range rows {
+			want, ok := row[col]
+			valid := ok && want != nil
+			if v.Valid.Get(i) != valid {
+				t.Errorf("column %d, row %d: got Valid %t, want %t", col, i, v.Valid.Get(i), valid)
+			}
+			if !valid {
+				want = reflect.Zero(values.Type().Elem()).Interface()
+			}
+			if got := values.Index(i).Interface(); !reflect.DeepEqual(got, want) {
+				t.Errorf("column %d, row %d: got %#v, want %#v", col, i, got, want)
+			}
+		}
+		for i := len(rows); i < len(rows)+64; i++ {
+			if v.Valid.Get(i) {
+				t.Errorf("column %d: got Valid bit %d set beyond last row", col, i)
+			}
+		}
+	}
+}
+
+// vectorField is the name of the Vector field holding the values of
+// each column type.
+var vectorField = map[flat.ColumnType]string{
+	flat.ColumnTypeBool:     "Bools",
+	flat.ColumnTypeByte:     "Int8s",
+	flat.ColumnTypeUByte:    "Uint8s",
+	flat.ColumnTypeShort:    "Int16s",
+	flat.ColumnTypeUShort:   "Uint16s",
+	flat.ColumnTypeInt:      "Int32s",
+	flat.ColumnTypeUInt:     "Uint32s",
+	flat.ColumnTypeLong:     "Int64s",
+	flat.ColumnTypeULong:    "Uint64s",
+	flat.ColumnTypeFloat:    "Float32s",
+	flat.ColumnTypeDouble:   "Float64s",
+	flat.ColumnTypeString:   "Strings",
+	flat.ColumnTypeJson:     "Strings",
+	flat.ColumnTypeDateTime: "Strings",
+	flat.ColumnTypeBinary:   "Binaries",
+}
+
+func TestBatch(t *testing.T) {
+	b := NewBatch(batchSchema)
+	checkBatch(t, b, nil)
+	for _, row := range batchRows {
+		p := NewProps(batchSchema)
+		setRow(t, p, row)
+		if err := b.Append(p); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	checkBatch(t, b, batchRows)
+
+	// Binary values are copies.
+	data := []byte{1, 2}
+	p := NewProps(batchSchema)
+	if err := p.SetBinary(14, data); err != nil {
+		t.Fatal(err)
+	} else if err = b.Append(p); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetBinary(14, []byte{3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.Vector(14).Binaries[3]; !bytes.Equal(got, data) {
+		t.Errorf("got %v after changing Props, want %v", got, data)
+	}
+}
+
+func TestBatchReset(t *testing.T) {
+	b := NewBatch(batchSchema)
+	for _, row := range batchRows {
+		p := NewProps(batchSchema)
+		setRow(t, p, row)
+		if err := b.Append(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	strings := b.Vector(11).Strings
+	b.Reset()
+	checkBatch(t, b, nil)
+
+	// Rows appended after Reset reuse the vectors, and see none of the
+	// values or validity bits of the earlier rows.
+	reversed := []map[int]any{batchRows[2], batchRows[1]}
+	for _, row := range reversed {
+		p := NewProps(batchSchema)
+		setRow(t, p, row)
+		data, err := p.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := b.AppendFlat(data); err != nil {
+			t.Fatalf("AppendFlat: %v", err)
+		}
+	}
+	checkBatch(t, b, reversed)
+	if &b.Vector(11).Strings[0] != &strings[0] {
+		t.Error("Reset did not keep vector memory")
+	}
+}
+
+func TestBatchAppendErrors(t *testing.T) {
+	b := NewBatch(batchSchema)
+	p := NewProps(batchSchema)
+	setRow(t, p, batchRows[0])
+	if err := b.Append(p); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Append(NewProps(parseSchema)); !errors.Is(err, ErrSchemaMismatch) {
+		t.Errorf("Append: got %v for wrong number of columns, want ErrSchemaMismatch", err)
+	}
+	other := NewProps(NewSchema([]Column{
+		{Name: "a", Type: flat.ColumnTypeInt},
+		{Name: "b", Type: flat.ColumnTypeString},
+		{Name: "c", Type: flat.ColumnTypeLong},
+	}))
+	var typeErr *TypeError
+	b2 := NewBatch(parseSchema)
+	if err := b2.Append(other); !errors.As(err, &typeErr) || typeErr.Col != 2 {
+		t.Errorf("Append: got %v, want *TypeError for column 2", err)
+	}
+
+	// A corrupt property buffer leaves every vector as it was.
+	corrupt := PropsFromFlat(parseSchema, bytesJoin(intEntry(0, 1), stringEntry(1, 1, "x"), intEntry(0, 2)), WithParseMode(ParseStrict))
+	if err := b2.Append(corrupt); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Append: got %v, want ErrCorrupt", err)
+	}
+	checkBatch(t, b2, nil)
+	checkBatch(t, b, batchRows[:1])
+}
+
+func bytesJoin(b ...[]byte) []byte {
+	return bytes.Join(b, nil)
+}
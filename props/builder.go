@@ -0,0 +1,143 @@
+package props
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+)
+
+// maxColumns is the maximum number of columns in a schema. Property
+// buffers address columns with a 16-bit unsigned index, so the highest
+// addressable column index is math.MaxUint16.
+const maxColumns = math.MaxUint16 + 1
+
+// SchemaBuilder builds a Schema using a fluent API, checking the
+// structure of the schema when Build is called.
+//
+// Call Column to add a column, then call the column attribute methods,
+// such as Title or Required, to set attributes of the column most
+// recently added. For example:
+//
+//	schema, err := props.NewSchemaBuilder().
+//		Column("id", flat.ColumnTypeLong).PrimaryKey().
+//		Column("name", flat.ColumnTypeString).Width(64).Required().
+//		Column("updated", flat.ColumnTypeDateTime).
+//		Build()
+//
+// Mistakes made while building are not reported until Build is called.
+type SchemaBuilder struct {
+	cols []Column
+	errs []error
+}
+
+// NewSchemaBuilder creates a new SchemaBuilder with no columns.
+func NewSchemaBuilder() *SchemaBuilder {
+	return &SchemaBuilder{}
+}
+
+// Column adds a new column with the given name and type. Subsequent
+// attribute method calls apply to this column.
+func (b *SchemaBuilder) Column(name string, columnType flat.ColumnType) *SchemaBuilder {
+	return b.AddColumn(Column{Name: name, Type: columnType})
+}
+
+// AddColumn adds a new column with all its attributes. Subsequent
+// attribute method calls apply to this column.
+func (b *SchemaBuilder) AddColumn(col Column) *SchemaBuilder {
+	b.cols = append(b.cols, col)
+	return b
+}
+
+// Title sets the title of the current column.
+func (b *SchemaBuilder) Title(title string) *SchemaBuilder {
+	return b.set("Title", func(c *Column) { c.Title = title })
+}
+
+// Description sets the description of the current column.
+func (b *SchemaBuilder) Description(description string) *SchemaBuilder {
+	return b.set("Description", func(c *Column) { c.Description = description })
+}
+
+// Width sets the width of the current column.
+func (b *SchemaBuilder) Width(width int32) *SchemaBuilder {
+	return b.set("Width", func(c *Column) { c.Width = width })
+}
+
+// Precision sets the precision of the current column.
+func (b *SchemaBuilder) Precision(precision int32) *SchemaBuilder {
+	return b.set("Precision", func(c *Column) { c.Precision = precision })
+}
+
+// Scale sets the scale of the current column.
+func (b *SchemaBuilder) Scale(scale int32) *SchemaBuilder {
+	return b.set("Scale", func(c *Column) { c.Scale = scale })
+}
+
+// Required marks the current column as required, i.e. not nullable.
+func (b *SchemaBuilder) Required() *SchemaBuilder {
+	return b.set("Required", func(c *Column) { c.Required = true })
+}
+
+// Unique marks the current column as unique.
+func (b *SchemaBuilder) Unique() *SchemaBuilder {
+	return b.set("Unique", func(c *Column) { c.Unique = true })
+}
+
+// PrimaryKey marks the current column as the primary key.
+func (b *SchemaBuilder) PrimaryKey() *SchemaBuilder {
+	return b.set("PrimaryKey", func(c *Column) { c.PrimaryKey = true })
+}
+
+// Metadata sets the metadata of the current column.
+func (b *SchemaBuilder) Metadata(metadata string) *SchemaBuilder {
+	return b.set("Metadata", func(c *Column) { c.Metadata = metadata })
+}
+
+func (b *SchemaBuilder) set(method string, f func(*Column)) *SchemaBuilder {
+	if len(b.cols) == 0 {
+		b.errs = append(b.errs, fmt.Errorf("%w: %s called before any column was added", ErrInvalidSchema, method))
+	} else {
+		f(&b.cols[len(b.cols)-1])
+	}
+	return b
+}
+
+// Build checks the structure of the columns added so far and, if they
+// are valid, returns a new Schema containing them.
+//
+// Build returns an error wrapping ErrInvalidSchema if any column has an
+// empty name or an invalid flat.ColumnType, if two columns have the
+// same name, or if there are too many columns to be addressed by the
+// 16-bit column index used in FlatGeobuf property buffers.
+//
+// The builder may continue to be used after Build, and changes made to
+// it do not affect Schemas already built.
+func (b *SchemaBuilder) Build() (*Schema, error) {
+	errs := append(b.errs[:len(b.errs):len(b.errs)], checkColumns(b.cols)...)
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return newSchema(b.cols), nil
+}
+
+func checkColumns(cols []Column) (errs []error) {
+	if len(cols) > maxColumns {
+		errs = append(errs, fmt.Errorf("%w: %d columns exceeds maximum of %d", ErrInvalidSchema, len(cols), maxColumns))
+	}
+	names := make(map[string]int, len(cols))
+	for i := range cols {
+		if cols[i].Name == "" {
+			errs = append(errs, fmt.Errorf("%w: column %d has empty name", ErrInvalidSchema, i))
+		} else if j, dup := names[cols[i].Name]; dup {
+			errs = append(errs, fmt.Errorf("%w: column %d has same name as column %d: %q", ErrInvalidSchema, i, j, cols[i].Name))
+		} else {
+			names[cols[i].Name] = i
+		}
+		if _, ok := flat.EnumNamesColumnType[cols[i].Type]; !ok {
+			errs = append(errs, fmt.Errorf("%w: column %d (%q) has invalid type: %s", ErrInvalidSchema, i, cols[i].Name, cols[i].Type))
+		}
+	}
+	return
+}
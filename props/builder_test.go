@@ -0,0 +1,129 @@
+package props
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+)
+
+func TestSchemaBuilder(t *testing.T) {
+	b := NewSchemaBuilder().
+		Column("id", flat.ColumnTypeLong).PrimaryKey().Unique().Required().
+		Column("name", flat.ColumnTypeString).Title("Name").Description("The name").Width(64).Metadata(`{"a":1}`).
+		AddColumn(Column{Name: "price", Type: flat.ColumnTypeDouble}).Precision(10).Scale(2)
+	schema, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	want := []Column{
+		{Name: "id", Type: flat.ColumnTypeLong, PrimaryKey: true, Unique: true, Required: true},
+		{Name: "name", Type: flat.ColumnTypeString, Title: "Name", Description: "The name", Width: 64, Metadata: `{"a":1}`},
+		{Name: "price", Type: flat.ColumnTypeDouble, Precision: 10, Scale: 2},
+	}
+	if n := schema.ColumnsLength(); n != len(want) {
+		t.Fatalf("got %d columns, want %d", n, len(want))
+	}
+	for i := range want {
+		if got := schema.Column(i); got != want[i] {
+			t.Errorf("column %d: got %+v, want %+v", i, got, want[i])
+		}
+	}
+
+	// Using the builder after Build doesn't affect the built schema.
+	b.Title("Price").Column("extra", flat.ColumnTypeInt)
+	if n := schema.ColumnsLength(); n != len(want) {
+		t.Errorf("after reuse: got %d columns, want %d", n, len(want))
+	}
+	if got := schema.Column(2).Title; got != "" {
+		t.Errorf("after reuse: got title %q, want empty", got)
+	}
+	again, err := b.Build()
+	if err != nil {
+		t.Fatalf("second Build: %v", err)
+	}
+	if n := again.ColumnsLength(); n != 4 {
+		t.Errorf("second Build: got %d columns, want 4", n)
+	}
+
+	if empty, err := NewSchemaBuilder().Build(); err != nil || empty.ColumnsLength() != 0 {
+		t.Errorf("empty: got %v, %v, want no columns", empty, err)
+	}
+}
+
+func TestSchemaBuilderErrors(t *testing.T) {
+	tooMany := NewSchemaBuilder()
+	for i := 0; i <= maxColumns; i++ {
+		tooMany.Column(strconv.Itoa(i), flat.ColumnTypeInt)
+	}
+
+	for _, testCase := range []struct {
+		name string
+		b    *SchemaBuilder
+		want []string
+	}{
+		{
+			name: "empty name",
+			b:    NewSchemaBuilder().Column("a", flat.ColumnTypeInt).Column("", flat.ColumnTypeInt),
+			want: []string{"column 1 has empty name"},
+		},
+		{
+			name: "duplicate name",
+			b: NewSchemaBuilder().
+				Column("a", flat.ColumnTypeInt).
+				Column("b", flat.ColumnTypeInt).
+				Column("a", flat.ColumnTypeString),
+			want: []string{`column 2 has same name as column 0: "a"`},
+		},
+		{
+			name: "invalid type",
+			b:    NewSchemaBuilder().Column("a", flat.ColumnTypeBinary+1),
+			want: []string{`column 0 ("a") has invalid type`},
+		},
+		{
+			name: "attribute before column",
+			b:    NewSchemaBuilder().Required().Column("a", flat.ColumnTypeInt),
+			want: []string{"Required called before any column was added"},
+		},
+		{
+			name: "too many columns",
+			b:    tooMany,
+			want: []string{"65537 columns exceeds maximum of 65536"},
+		},
+		{
+			name: "several",
+			b: NewSchemaBuilder().
+				Width(1).
+				Column("a", flat.ColumnTypeInt).
+				Column("a", flat.ColumnTypeInt).
+				Column("", flat.ColumnType(255)),
+			want: []string{
+				"Width called before any column was added",
+				`column 1 has same name as column 0: "a"`,
+				"column 2 has empty name",
+				`column 2 ("") has invalid type`,
+			},
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			schema, err := testCase.b.Build()
+			if schema != nil {
+				t.Errorf("got schema %v, want nil", schema)
+			}
+			if !errors.Is(err, ErrInvalidSchema) {
+				t.Fatalf("got %v, want ErrInvalidSchema", err)
+			}
+			errs := err.(interface{ Unwrap() []error }).Unwrap()
+			if len(errs) != len(testCase.want) {
+				t.Fatalf("got %d errors, want %d: %v", len(errs), len(testCase.want), err)
+			}
+			for i, want := range testCase.want {
+				if !errors.Is(errs[i], ErrInvalidSchema) || !strings.Contains(errs[i].Error(), want) {
+					t.Errorf("error %d: got %v, want ErrInvalidSchema containing %q", i, errs[i], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,311 @@
+package props
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+)
+
+// This file contains the coercion rules used to set column values from
+// loosely typed values, such as values decoded from JSON, whose Go type
+// need not match the column type exactly.
+
+// coerce sets the value of a column from a loosely typed value, such
+// as a value from a map or decoded from JSON, converting it to the
+// column type under the coercion rule of p as described on FromMap. A
+// json.RawMessage is decoded first, except that it is stored as-is in
+// a JSON column.
+//
+// Values which cannot be converted produce a *TypeError, and invalid
+// JSON produces a *ColumnError wrapping ErrInvalidJSON.
+func (p *Props) coerce(col int, v any) error {
+	t := p.columnType(col)
+	if raw, ok := v.(json.RawMessage); ok {
+		if t == flat.ColumnTypeJson {
+			return p.coerceRawJSON(col, raw)
+		}
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		v = nil
+		if err := dec.Decode(&v); err != nil {
+			return p.jsonErr(col, err)
+		}
+	}
+	switch v.(type) {
+	case nil, Null:
+		return p.SetNull(col)
+	}
+	if p.opts.coercion == CoerceNone {
+		return p.SetValue(col, v)
+	}
+	loose := p.opts.coercion == CoerceLoose
+	_, isString := v.(string)
+	if b, ok := v.(bool); ok && loose && (isSigned(t) || isUnsigned(t) || isFloat(t)) {
+		v = boolToInt(b)
+	}
+	switch t {
+	case flat.ColumnTypeBool:
+		switch x := v.(type) {
+		case bool:
+			return p.SetBool(col, x)
+		case string:
+			if b, err := strconv.ParseBool(x); err == nil && loose {
+				return p.SetBool(col, b)
+			}
+		}
+	case flat.ColumnTypeByte, flat.ColumnTypeShort, flat.ColumnTypeInt, flat.ColumnTypeLong:
+		if isString && !loose {
+			break
+		} else if n, ok := coerceSigned(v, 8*fixedSize(t)); ok {
+			switch t {
+			case flat.ColumnTypeByte:
+				return p.SetByte(col, int8(n))
+			case flat.ColumnTypeShort:
+				return p.SetShort(col, int16(n))
+			case flat.ColumnTypeInt:
+				return p.SetInt(col, int32(n))
+			default:
+				return p.SetLong(col, n)
+			}
+		}
+	case flat.ColumnTypeUByte, flat.ColumnTypeUShort, flat.ColumnTypeUInt, flat.ColumnTypeULong:
+		if isString && !loose {
+			break
+		} else if n, ok := coerceUnsigned(v, 8*fixedSize(t)); ok {
+			switch t {
+			case flat.ColumnTypeUByte:
+				return p.SetUByte(col, uint8(n))
+			case flat.ColumnTypeUShort:
+				return p.SetUShort(col, uint16(n))
+			case flat.ColumnTypeUInt:
+				return p.SetUInt(col, uint32(n))
+			default:
+				return p.SetULong(col, n)
+			}
+		}
+	case flat.ColumnTypeFloat:
+		if isString && !loose {
+			break
+		} else if f, ok := coerceFloat(v, 32); ok {
+			return p.SetFloat(col, float32(f))
+		}
+	case flat.ColumnTypeDouble:
+		if isString && !loose {
+			break
+		} else if f, ok := coerceFloat(v, 64); ok {
+			return p.SetDouble(col, f)
+		}
+	case flat.ColumnTypeString:
+		switch x := v.(type) {
+		case string:
+			return p.SetString(col, x)
+		case json.Number:
+			if loose {
+				return p.SetString(col, string(x))
+			}
+		case bool:
+			if loose {
+				return p.SetString(col, strconv.FormatBool(x))
+			}
+		}
+	case flat.ColumnTypeJson:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return p.valueErr(col, v, err)
+		}
+		return p.setBinary(col, flat.ColumnTypeJson, b)
+	case flat.ColumnTypeBinary:
+		switch x := v.(type) {
+		case []byte:
+			return p.SetBinary(col, x)
+		case string:
+			if b, err := base64.StdEncoding.DecodeString(x); err == nil {
+				return p.SetBinary(col, b)
+			}
+		}
+	case flat.ColumnTypeDateTime:
+		switch x := v.(type) {
+		case time.Time:
+			return p.SetDateTime(col, x)
+		case DateTime:
+			return p.SetDateTimeValue(col, x)
+		case string:
+			if _, err := ParseDateTime(x); err != nil {
+				return p.valueErr(col, x, err)
+			}
+			return p.SetDateTimeString(col, x)
+		}
+	default:
+		return errInvalidColumnType(t)
+	}
+	return p.valueErr(col, v, nil)
+}
+
+// coerceRawJSON stores raw JSON text in a JSON column, with
+// insignificant white space removed. The JSON literal null sets the
+// column to null.
+func (p *Props) coerceRawJSON(col int, raw json.RawMessage) error {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return p.jsonErr(col, err)
+	} else if bytes.Equal(buf.Bytes(), []byte("null")) {
+		return p.SetNull(col)
+	}
+	return p.setBinary(col, flat.ColumnTypeJson, buf.Bytes())
+}
+
+// boolToInt returns one for true and zero for false.
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// coerceSigned converts a loosely typed value to a signed integer which
+// fits in the given number of bits. The return value ok is false if the
+// value is not a number or numeric string, is not integral, or is out
+// of range.
+func coerceSigned(v any, bits int) (n int64, ok bool) {
+	switch x := v.(type) {
+	case int:
+		n = int64(x)
+	case int8:
+		n = int64(x)
+	case int16:
+		n = int64(x)
+	case int32:
+		n = int64(x)
+	case int64:
+		n = x
+	case uint, uint8, uint16, uint32, uint64:
+		u, _ := coerceUnsigned(x, 64)
+		if u > math.MaxInt64 {
+			return 0, false
+		}
+		n = int64(u)
+	case float32:
+		return floatToSigned(float64(x), bits)
+	case float64:
+		return floatToSigned(x, bits)
+	case json.Number:
+		return parseSigned(string(x), bits)
+	case string:
+		return parseSigned(x, bits)
+	default:
+		return 0, false
+	}
+	if bits < 64 && (n < -1<<(bits-1) || n >= 1<<(bits-1)) {
+		return 0, false
+	}
+	return n, true
+}
+
+// coerceUnsigned converts a loosely typed value to an unsigned integer
+// which fits in the given number of bits, in the same way as
+// coerceSigned.
+func coerceUnsigned(v any, bits int) (n uint64, ok bool) {
+	switch x := v.(type) {
+	case uint:
+		n = uint64(x)
+	case uint8:
+		n = uint64(x)
+	case uint16:
+		n = uint64(x)
+	case uint32:
+		n = uint64(x)
+	case uint64:
+		n = x
+	case int, int8, int16, int32, int64:
+		s, _ := coerceSigned(x, 64)
+		if s < 0 {
+			return 0, false
+		}
+		n = uint64(s)
+	case float32:
+		return floatToUnsigned(float64(x), bits)
+	case float64:
+		return floatToUnsigned(x, bits)
+	case json.Number:
+		return parseUnsigned(string(x), bits)
+	case string:
+		return parseUnsigned(x, bits)
+	default:
+		return 0, false
+	}
+	if bits < 64 && n >= 1<<bits {
+		return 0, false
+	}
+	return n, true
+}
+
+// coerceFloat converts a loosely typed value to a floating point value
+// which fits in the given number of bits.
+func coerceFloat(v any, bits int) (f float64, ok bool) {
+	switch x := v.(type) {
+	case float32:
+		return float64(x), true
+	case float64:
+		f = x
+	case json.Number:
+		return parseFloat(string(x), bits)
+	case string:
+		return parseFloat(x, bits)
+	default:
+		if n, ok := coerceSigned(v, 64); ok {
+			return float64(n), true
+		} else if u, ok := coerceUnsigned(v, 64); ok {
+			return float64(u), true
+		}
+		return 0, false
+	}
+	if bits == 32 && !math.IsInf(f, 0) && math.IsInf(float64(float32(f)), 0) {
+		return 0, false
+	}
+	return f, true
+}
+
+func parseSigned(s string, bits int) (int64, bool) {
+	if n, err := strconv.ParseInt(s, 10, bits); err == nil {
+		return n, true
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return floatToSigned(f, bits)
+	}
+	return 0, false
+}
+
+func parseUnsigned(s string, bits int) (uint64, bool) {
+	if n, err := strconv.ParseUint(s, 10, bits); err == nil {
+		return n, true
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return floatToUnsigned(f, bits)
+	}
+	return 0, false
+}
+
+func parseFloat(s string, bits int) (float64, bool) {
+	f, err := strconv.ParseFloat(s, bits)
+	return f, err == nil
+}
+
+func floatToSigned(f float64, bits int) (int64, bool) {
+	limit := math.Ldexp(1, bits-1)
+	if f != math.Trunc(f) || f < -limit || f >= limit {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+func floatToUnsigned(f float64, bits int) (uint64, bool) {
+	if f != math.Trunc(f) || f < 0 || f >= math.Ldexp(1, bits) {
+		return 0, false
+	}
+	return uint64(f), true
+}
@@ -1,6 +1,8 @@
 package props
 
 import (
+	"encoding/json"
+
 	"github.com/gogama/flatgeobuf-convert/interop"
 	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
 	flatbuffers "github.com/google/flatbuffers/go"
@@ -38,12 +40,25 @@ func ColumnFromFlat(obj *flat.Column) (col Column, err error) {
 	return
 }
 
+// ToBuilder writes the column to b as a flat.Column table. Width and
+// Precision are only written if they are positive, and Scale only if it
+// is positive or the column has a precision, so that the zero values of
+// these fields are left as FlatGeobuf's "unset" default of -1.
 func (c *Column) ToBuilder(b *flatbuffers.Builder) flatbuffers.UOffsetT {
 	func() {
 		offset := b.CreateString(c.Name)
 		defer func() {
 			flat.ColumnAddName(b, offset)
 			flat.ColumnAddType(b, c.Type)
+			if c.Width > 0 {
+				flat.ColumnAddWidth(b, c.Width)
+			}
+			if c.Precision > 0 {
+				flat.ColumnAddPrecision(b, c.Precision)
+			}
+			if c.Scale > 0 || c.Scale == 0 && c.Precision > 0 {
+				flat.ColumnAddScale(b, c.Scale)
+			}
 			flat.ColumnAddNullable(b, !c.Required)
 			flat.ColumnAddUnique(b, c.Unique)
 			flat.ColumnAddPrimaryKey(b, c.PrimaryKey)
@@ -64,3 +79,79 @@ func (c *Column) ToBuilder(b *flatbuffers.Builder) flatbuffers.UOffsetT {
 	}()
 	return flat.ColumnEnd(b)
 }
+
+// columnJSON is the JSON form of a Column. Every attribute is always
+// written, so that the JSON form of a schema is stable.
+type columnJSON struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Width       int32  `json:"width"`
+	Precision   int32  `json:"precision"`
+	Scale       int32  `json:"scale"`
+	Required    bool   `json:"required"`
+	Unique      bool   `json:"unique"`
+	PrimaryKey  bool   `json:"primaryKey"`
+	Metadata    string `json:"metadata"`
+}
+
+// MarshalJSON implements json.Marshaler. The column is written as a
+// JSON object with every attribute, and the column type is written by
+// name, for example "DateTime".
+func (c Column) MarshalJSON() ([]byte, error) {
+	typeName, ok := flat.EnumNamesColumnType[c.Type]
+	if !ok {
+		return nil, errInvalidColumnType(c.Type)
+	}
+	return json.Marshal(columnJSON{
+		Name:        c.Name,
+		Type:        typeName,
+		Title:       c.Title,
+		Description: c.Description,
+		Width:       c.Width,
+		Precision:   c.Precision,
+		Scale:       c.Scale,
+		Required:    c.Required,
+		Unique:      c.Unique,
+		PrimaryKey:  c.PrimaryKey,
+		Metadata:    c.Metadata,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler, reading the form written
+// by MarshalJSON. Attributes missing from the JSON object take their
+// zero value, but the column type must be present and must be a valid
+// type name.
+func (c *Column) UnmarshalJSON(data []byte) error {
+	var v columnJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	var ok bool
+	*c, ok = v.column()
+	if !ok {
+		return fmtErr("invalid column type name: %q", v.Type)
+	}
+	return nil
+}
+
+func (v *columnJSON) column() (Column, bool) {
+	columnType, ok := flat.EnumValuesColumnType[v.Type]
+	if !ok {
+		return Column{}, false
+	}
+	return Column{
+		Name:        v.Name,
+		Type:        columnType,
+		Title:       v.Title,
+		Description: v.Description,
+		Width:       v.Width,
+		Precision:   v.Precision,
+		Scale:       v.Scale,
+		Required:    v.Required,
+		Unique:      v.Unique,
+		PrimaryKey:  v.PrimaryKey,
+		Metadata:    v.Metadata,
+	}, true
+}
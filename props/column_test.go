@@ -0,0 +1,73 @@
+package props
+
+import (
+	"testing"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+func TestColumnToBuilder(t *testing.T) {
+	testCases := []struct {
+		name string
+		col  Column
+		want Column
+	}{
+		{
+			name: "zero value",
+			col:  Column{Name: "a", Type: flat.ColumnTypeDouble},
+			want: Column{Name: "a", Type: flat.ColumnTypeDouble, Width: -1, Precision: -1, Scale: -1},
+		},
+		{
+			name: "unset",
+			col:  Column{Name: "a", Type: flat.ColumnTypeDouble, Width: -1, Precision: -1, Scale: -1},
+			want: Column{Name: "a", Type: flat.ColumnTypeDouble, Width: -1, Precision: -1, Scale: -1},
+		},
+		{
+			name: "precision and zero scale",
+			col:  Column{Name: "a", Type: flat.ColumnTypeDouble, Precision: 5, Scale: 0},
+			want: Column{Name: "a", Type: flat.ColumnTypeDouble, Width: -1, Precision: 5, Scale: 0},
+		},
+		{
+			name: "every field",
+			col: Column{
+				Name:        "a",
+				Type:        flat.ColumnTypeString,
+				Title:       "A",
+				Description: "The letter A",
+				Width:       10,
+				Precision:   8,
+				Scale:       2,
+				Required:    true,
+				Unique:      true,
+				PrimaryKey:  true,
+				Metadata:    `{"x":1}`,
+			},
+			want: Column{
+				Name:        "a",
+				Type:        flat.ColumnTypeString,
+				Title:       "A",
+				Description: "The letter A",
+				Width:       10,
+				Precision:   8,
+				Scale:       2,
+				Required:    true,
+				Unique:      true,
+				PrimaryKey:  true,
+				Metadata:    `{"x":1}`,
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			b := flatbuffers.NewBuilder(0)
+			b.Finish(testCase.col.ToBuilder(b))
+			got, err := ColumnFromFlat(flat.GetRootAsColumn(b.FinishedBytes(), 0))
+			if err != nil {
+				t.Fatalf("ColumnFromFlat: %v", err)
+			} else if got != testCase.want {
+				t.Errorf("ColumnFromFlat(ToBuilder(%+v)) = %+v, want %+v", testCase.col, got, testCase.want)
+			}
+		})
+	}
+}
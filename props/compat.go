@@ -0,0 +1,379 @@
+package props
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+)
+
+// Compatibility classifies whether data written under one schema can be
+// read under another.
+//
+// Compatibility is judged at the level of column names and values, on
+// the assumption that property buffers are remapped by column name when
+// moving between schemas. Consequently, moving a column to a different
+// index does not by itself make two schemas incompatible.
+type Compatibility int
+
+const (
+	// Incompatible means data cannot reliably be read in either
+	// direction.
+	Incompatible Compatibility = 0
+	// BackwardCompatible means readers using the new schema can read
+	// data written under the old schema.
+	BackwardCompatible Compatibility = 1
+	// ForwardCompatible means readers using the old schema can read
+	// data written under the new schema.
+	ForwardCompatible Compatibility = 2
+	// FullyCompatible means both BackwardCompatible and
+	// ForwardCompatible.
+	FullyCompatible = BackwardCompatible | ForwardCompatible
+)
+
+// Backward reports whether c includes BackwardCompatible.
+func (c Compatibility) Backward() bool {
+	return c&BackwardCompatible != 0
+}
+
+// Forward reports whether c includes ForwardCompatible.
+func (c Compatibility) Forward() bool {
+	return c&ForwardCompatible != 0
+}
+
+func (c Compatibility) String() string {
+	switch c {
+	case Incompatible:
+		return "Incompatible"
+	case BackwardCompatible:
+		return "BackwardCompatible"
+	case ForwardCompatible:
+		return "ForwardCompatible"
+	case FullyCompatible:
+		return "FullyCompatible"
+	default:
+		return "Compatibility(" + strconv.Itoa(int(c)) + ")"
+	}
+}
+
+// ChangeKind identifies the kind of a SchemaChange.
+type ChangeKind int
+
+const (
+	// ColumnAdded means the column is only in the new schema.
+	ColumnAdded ChangeKind = iota + 1
+	// ColumnRemoved means the column is only in the old schema.
+	ColumnRemoved
+	// ColumnRenamed means a column in the old schema appears to have
+	// been given a new name in the new schema.
+	ColumnRenamed
+	// ColumnRetyped means the column type changed.
+	ColumnRetyped
+	// ColumnReflagged means at least one of the Required, Unique, or
+	// PrimaryKey flags changed.
+	ColumnReflagged
+	// ColumnResized means at least one of the Width, Precision, or
+	// Scale attributes changed.
+	ColumnResized
+	// ColumnAnnotated means at least one of the Title, Description, or
+	// Metadata attributes changed.
+	ColumnAnnotated
+	// ColumnMoved means the column changed position relative to the
+	// other columns which are in both schemas.
+	ColumnMoved
+)
+
+var changeKindNames = [...]string{
+	ColumnAdded:     "Added",
+	ColumnRemoved:   "Removed",
+	ColumnRenamed:   "Renamed",
+	ColumnRetyped:   "Retyped",
+	ColumnReflagged: "Reflagged",
+	ColumnResized:   "Resized",
+	ColumnAnnotated: "Annotated",
+	ColumnMoved:     "Moved",
+}
+
+func (k ChangeKind) String() string {
+	if 0 < k && int(k) < len(changeKindNames) {
+		return changeKindNames[k]
+	}
+	return "ChangeKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// SchemaChange is one difference between an old and a new schema.
+type SchemaChange struct {
+	// Kind is the kind of change.
+	Kind ChangeKind
+	// OldIndex is the index of the column in the old schema, or -1 if
+	// the column was added.
+	OldIndex int
+	// NewIndex is the index of the column in the new schema, or -1 if
+	// the column was removed.
+	NewIndex int
+	// Old is the column in the old schema. It is the zero value if the
+	// column was added.
+	Old Column
+	// New is the column in the new schema. It is the zero value if the
+	// column was removed.
+	New Column
+	// Compatibility classifies this change on its own.
+	Compatibility Compatibility
+}
+
+func (c SchemaChange) String() string {
+	switch c.Kind {
+	case ColumnAdded:
+		return fmt.Sprintf("%s %q (%s)", c.Kind, c.New.Name, c.Compatibility)
+	case ColumnRemoved:
+		return fmt.Sprintf("%s %q (%s)", c.Kind, c.Old.Name, c.Compatibility)
+	case ColumnRenamed:
+		return fmt.Sprintf("%s %q to %q (%s)", c.Kind, c.Old.Name, c.New.Name, c.Compatibility)
+	case ColumnRetyped:
+		return fmt.Sprintf("%s %q from %s to %s (%s)", c.Kind, c.New.Name, c.Old.Type, c.New.Type, c.Compatibility)
+	case ColumnMoved:
+		return fmt.Sprintf("%s %q from %d to %d (%s)", c.Kind, c.New.Name, c.OldIndex, c.NewIndex, c.Compatibility)
+	default:
+		return fmt.Sprintf("%s %q (%s)", c.Kind, c.New.Name, c.Compatibility)
+	}
+}
+
+// SchemaDiff lists the changes from an old schema to a new schema.
+type SchemaDiff struct {
+	Changes []SchemaChange
+}
+
+// Compatibility returns the compatibility of the new schema with the
+// old schema, which is the compatibility common to every change. Two
+// identical schemas are FullyCompatible.
+func (d *SchemaDiff) Compatibility() Compatibility {
+	c := FullyCompatible
+	for i := range d.Changes {
+		c &= d.Changes[i].Compatibility
+	}
+	return c
+}
+
+func (d *SchemaDiff) String() string {
+	var bldr strings.Builder
+	_, _ = bldr.WriteString(packageName)
+	_, _ = bldr.WriteString("SchemaDiff{")
+	for i := range d.Changes {
+		if i > 0 {
+			_, _ = bldr.WriteString(", ")
+		}
+		_, _ = bldr.WriteString(d.Changes[i].String())
+	}
+	_ = bldr.WriteByte('}')
+	return bldr.String()
+}
+
+// DiffSchemas compares an old schema, a, with a new schema, b, and
+// reports the differences between them, each classified by its
+// compatibility.
+//
+// Columns are matched by name. An unmatched column of a is reported as
+// renamed to an unmatched column of b if the two columns are identical
+// apart from their names; otherwise, unmatched columns are reported as
+// removed from a or added to b. A matched column may have several
+// changes, for example if it is both retyped and moved.
+//
+// The compatibility of each kind of change is:
+//   - Added: fully compatible, or only forward compatible if the added
+//     column is required, since old data has no value for it.
+//   - Removed: fully compatible, or only backward compatible if the
+//     removed column was required.
+//   - Renamed: incompatible.
+//   - Retyped: backward compatible if the new type widens the old type
+//     (see Widens), forward compatible if the old type widens the new
+//     type, otherwise incompatible.
+//   - Reflagged: adding a constraint is forward compatible and removing
+//     one is backward compatible.
+//   - Resized: a larger size, or removing a size limit, is backward
+//     compatible, and a smaller one is forward compatible.
+//   - Annotated and Moved: fully compatible.
+//
+// A nil schema has no columns.
+func DiffSchemas(a, b *Schema) *SchemaDiff {
+	a, b = orEmpty(a), orEmpty(b)
+	d := &SchemaDiff{}
+	// Match columns by name.
+	a2b := make([]int, a.ColumnsLength())
+	b2a := make([]int, b.ColumnsLength())
+	for j := range b2a {
+		b2a[j] = -1
+	}
+	for i := range a2b {
+		a2b[i] = -1
+		if j, ok := b.Index(a.Name(i)); ok && b2a[j] < 0 {
+			a2b[i], b2a[j] = j, i
+		}
+	}
+	// Report removed columns, or renamed columns if an identical
+	// unmatched column can be found in the new schema.
+	for i := range a2b {
+		if a2b[i] >= 0 {
+			continue
+		}
+		old := a.Column(i)
+		renamed := false
+		for j := range b2a {
+			if b2a[j] < 0 && sameApartFromName(old, b.Column(j)) {
+				a2b[i], b2a[j] = j, i
+				d.add(ColumnRenamed, i, j, old, b.Column(j), Incompatible)
+				renamed = true
+				break
+			}
+		}
+		if !renamed {
+			compat := FullyCompatible
+			if old.Required {
+				compat = BackwardCompatible
+			}
+			d.add(ColumnRemoved, i, -1, old, Column{}, compat)
+		}
+	}
+	// Report added columns and changes to matched columns, in new
+	// schema order.
+	rankA, rankB := matchedRanks(a2b), matchedRanks(b2a)
+	for j := range b2a {
+		i := b2a[j]
+		cur := b.Column(j)
+		if i < 0 {
+			compat := FullyCompatible
+			if cur.Required {
+				compat = ForwardCompatible
+			}
+			d.add(ColumnAdded, -1, j, Column{}, cur, compat)
+			continue
+		}
+		old := a.Column(i)
+		if old.Type != cur.Type {
+			compat := Incompatible
+			if Widens(old.Type, cur.Type) {
+				compat = BackwardCompatible
+			} else if Widens(cur.Type, old.Type) {
+				compat = ForwardCompatible
+			}
+			d.add(ColumnRetyped, i, j, old, cur, compat)
+		}
+		if old.Required != cur.Required || old.Unique != cur.Unique || old.PrimaryKey != cur.PrimaryKey {
+			compat := constraintCompat(old.Required, cur.Required) &
+				constraintCompat(old.Unique, cur.Unique) &
+				constraintCompat(old.PrimaryKey, cur.PrimaryKey)
+			d.add(ColumnReflagged, i, j, old, cur, compat)
+		}
+		if old.Width != cur.Width || old.Precision != cur.Precision || old.Scale != cur.Scale {
+			compat := sizeCompat(old.Width, cur.Width, 0) &
+				sizeCompat(old.Precision, cur.Precision, 0) &
+				sizeCompat(old.Scale, cur.Scale, -1)
+			d.add(ColumnResized, i, j, old, cur, compat)
+		}
+		if old.Title != cur.Title || old.Description != cur.Description || old.Metadata != cur.Metadata {
+			d.add(ColumnAnnotated, i, j, old, cur, FullyCompatible)
+		}
+		if rankA[i] != rankB[j] {
+			d.add(ColumnMoved, i, j, old, cur, FullyCompatible)
+		}
+	}
+	return d
+}
+
+func (d *SchemaDiff) add(kind ChangeKind, oldIndex, newIndex int, old, cur Column, compat Compatibility) {
+	d.Changes = append(d.Changes, SchemaChange{
+		Kind:          kind,
+		OldIndex:      oldIndex,
+		NewIndex:      newIndex,
+		Old:           old,
+		New:           cur,
+		Compatibility: compat,
+	})
+}
+
+func sameApartFromName(a, b Column) bool {
+	a.Name, b.Name = "", ""
+	return a == b
+}
+
+// matchedRanks returns, for each index which is matched to a column in
+// the other schema, its position among the matched columns. Unmatched
+// indexes get rank -1.
+func matchedRanks(match []int) []int {
+	ranks := make([]int, len(match))
+	r := 0
+	for i := range match {
+		if match[i] < 0 {
+			ranks[i] = -1
+		} else {
+			ranks[i] = r
+			r++
+		}
+	}
+	return ranks
+}
+
+func constraintCompat(old, cur bool) Compatibility {
+	if old == cur {
+		return FullyCompatible
+	} else if cur {
+		return ForwardCompatible
+	}
+	return BackwardCompatible
+}
+
+// sizeCompat compares two size limits, where any value less than or
+// equal to unset means there is no limit.
+func sizeCompat(old, cur, unset int32) Compatibility {
+	if old <= unset {
+		old = 1<<31 - 1
+	}
+	if cur <= unset {
+		cur = 1<<31 - 1
+	}
+	if old == cur {
+		return FullyCompatible
+	} else if cur > old {
+		return BackwardCompatible
+	}
+	return ForwardCompatible
+}
+
+// Widens reports whether every value of column type from can be
+// represented exactly as a value of column type to, so that a column
+// can be retyped from one to the other without losing information.
+//
+// Every type widens itself. Integer types widen to larger integer types
+// which can hold their full range, and to floating point types whose
+// mantissa can hold their full range. Float widens to Double. DateTime
+// and Json widen to String, since their values are text.
+func Widens(from, to flat.ColumnType) bool {
+	if from == to {
+		return true
+	}
+	switch from {
+	case flat.ColumnTypeByte:
+		return to == flat.ColumnTypeShort || to == flat.ColumnTypeInt || to == flat.ColumnTypeLong ||
+			to == flat.ColumnTypeFloat || to == flat.ColumnTypeDouble
+	case flat.ColumnTypeUByte:
+		return to == flat.ColumnTypeShort || to == flat.ColumnTypeUShort || to == flat.ColumnTypeInt ||
+			to == flat.ColumnTypeUInt || to == flat.ColumnTypeLong || to == flat.ColumnTypeULong ||
+			to == flat.ColumnTypeFloat || to == flat.ColumnTypeDouble
+	case flat.ColumnTypeShort:
+		return to == flat.ColumnTypeInt || to == flat.ColumnTypeLong ||
+			to == flat.ColumnTypeFloat || to == flat.ColumnTypeDouble
+	case flat.ColumnTypeUShort:
+		return to == flat.ColumnTypeInt || to == flat.ColumnTypeUInt || to == flat.ColumnTypeLong ||
+			to == flat.ColumnTypeULong || to == flat.ColumnTypeFloat || to == flat.ColumnTypeDouble
+	case flat.ColumnTypeInt:
+		return to == flat.ColumnTypeLong || to == flat.ColumnTypeDouble
+	case flat.ColumnTypeUInt:
+		return to == flat.ColumnTypeLong || to == flat.ColumnTypeULong || to == flat.ColumnTypeDouble
+	case flat.ColumnTypeFloat:
+		return to == flat.ColumnTypeDouble
+	case flat.ColumnTypeDateTime, flat.ColumnTypeJson:
+		return to == flat.ColumnTypeString
+	default:
+		return false
+	}
+}
@@ -0,0 +1,104 @@
+package props
+
+import (
+	"testing"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+)
+
+func TestDiffSchemas(t *testing.T) {
+	old := NewSchema([]Column{
+		{Name: "id", Type: flat.ColumnTypeInt, Required: true},
+		{Name: "name", Type: flat.ColumnTypeString, Width: 10},
+		{Name: "code", Type: flat.ColumnTypeString},
+		{Name: "gone", Type: flat.ColumnTypeBool, Required: true},
+	})
+	cur := NewSchema([]Column{
+		{Name: "name", Type: flat.ColumnTypeString, Width: 20, Title: "Name"},
+		{Name: "id", Type: flat.ColumnTypeLong, Required: true, Unique: true},
+		{Name: "label", Type: flat.ColumnTypeString},
+		{Name: "extra", Type: flat.ColumnTypeDouble},
+	})
+	type change struct {
+		kind   ChangeKind
+		old    int
+		new    int
+		compat Compatibility
+	}
+	testCases := []struct {
+		name   string
+		a, b   *Schema
+		want   []change
+		compat Compatibility
+	}{
+		{
+			name:   "identical",
+			a:      old,
+			b:      old,
+			compat: FullyCompatible,
+		},
+		{
+			name:   "both nil",
+			compat: FullyCompatible,
+		},
+		{
+			name: "nil old schema",
+			b:    old,
+			want: []change{
+				{ColumnAdded, -1, 0, ForwardCompatible},
+				{ColumnAdded, -1, 1, FullyCompatible},
+				{ColumnAdded, -1, 2, FullyCompatible},
+				{ColumnAdded, -1, 3, ForwardCompatible},
+			},
+			compat: ForwardCompatible,
+		},
+		{
+			name: "nil new schema",
+			a:    old,
+			want: []change{
+				{ColumnRemoved, 0, -1, BackwardCompatible},
+				{ColumnRemoved, 1, -1, FullyCompatible},
+				{ColumnRemoved, 2, -1, FullyCompatible},
+				{ColumnRemoved, 3, -1, BackwardCompatible},
+			},
+			compat: BackwardCompatible,
+		},
+		{
+			name: "changes",
+			a:    old,
+			b:    cur,
+			want: []change{
+				{ColumnRenamed, 2, 2, Incompatible},
+				{ColumnRemoved, 3, -1, BackwardCompatible},
+				{ColumnResized, 1, 0, BackwardCompatible},
+				{ColumnAnnotated, 1, 0, FullyCompatible},
+				{ColumnMoved, 1, 0, FullyCompatible},
+				{ColumnRetyped, 0, 1, BackwardCompatible},
+				{ColumnReflagged, 0, 1, ForwardCompatible},
+				{ColumnMoved, 0, 1, FullyCompatible},
+				{ColumnAdded, -1, 3, FullyCompatible},
+			},
+			compat: Incompatible,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			d := DiffSchemas(testCase.a, testCase.b)
+			var got []change
+			for _, c := range d.Changes {
+				got = append(got, change{c.Kind, c.OldIndex, c.NewIndex, c.Compatibility})
+			}
+			if len(got) != len(testCase.want) {
+				t.Fatalf("got changes %v, want %v", d, testCase.want)
+			}
+			for i := range got {
+				if got[i] != testCase.want[i] {
+					t.Errorf("change %d: got %+v, want %+v", i, got[i], testCase.want[i])
+				}
+			}
+			if c := d.Compatibility(); c != testCase.compat {
+				t.Errorf("got compatibility %s, want %s", c, testCase.compat)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package props
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// DateTime is the value of a FlatGeobuf date/time column, which the
+// FlatGeobuf format stores as ISO 8601 text.
+//
+// Real-world FlatGeobuf files frequently contain partial date/time
+// values, such as dates without times or times without zones. DateTime
+// records which parts were present so that a partial value can be
+// written back out without inventing the missing parts.
+type DateTime struct {
+	// Time is the parsed value. Parts which are absent take the same
+	// defaults as time.Parse gives them: a missing date is January 1 of
+	// year zero, a missing time is midnight, and a missing zone is UTC.
+	// When HasZone is false, the location of Time is therefore not
+	// meaningful and only the wall clock should be relied on.
+	Time time.Time
+	// HasDate indicates whether the value has a calendar date part.
+	HasDate bool
+	// HasTime indicates whether the value has a time-of-day part.
+	HasTime bool
+	// HasZone indicates whether the value has a zone designator, either
+	// "Z" or a numeric offset from UTC.
+	HasZone bool
+}
+
+// IsPartial reports whether any of the date, time, or zone parts of the
+// value are absent.
+func (dt DateTime) IsPartial() bool {
+	return !dt.HasDate || !dt.HasTime || !dt.HasZone
+}
+
+// String formats the value in ISO 8601 extended format, writing only
+// the parts which are present. Fractional seconds are written with as
+// many digits as needed, and omitted if zero.
+func (dt DateTime) String() string {
+	var layout string
+	if dt.HasDate {
+		layout = "2006-01-02"
+	}
+	if dt.HasTime {
+		if dt.HasDate {
+			layout += "T"
+		}
+		layout += "15:04:05.999999999"
+		if dt.HasZone {
+			layout += "Z07:00"
+		}
+	}
+	if layout == "" {
+		return ""
+	}
+	return dt.Time.Format(layout)
+}
+
+// ParseDateTime parses an ISO 8601 date/time value in extended format.
+//
+// The accepted forms are a date ("2006-01-02"), a time ("15:04",
+// "15:04:05", or "15:04:05.999999999"), or a date and time separated by
+// "T" or a single space. Seconds may have any number of fractional
+// digits, separated by either "." or ",". A time may be followed by a
+// zone designator, which is either "Z" or a numeric offset in one of
+// the forms "-07", "-0700", or "-07:00".
+//
+// If the value cannot be parsed, the error returned is a
+// *time.ParseError.
+func ParseDateTime(s string) (DateTime, error) {
+	var dt DateTime
+	var year, month, day, hour, min, sec, nsec int
+	var loc = time.UTC
+	q := s
+	if len(q) >= 10 && q[4] == '-' && q[7] == '-' {
+		var ok bool
+		if year, ok = atoi(q[0:4]); !ok {
+			return dt, dateTimeErr(s, "bad year")
+		} else if month, ok = atoi(q[5:7]); !ok || month < 1 || month > 12 {
+			return dt, dateTimeErr(s, "bad month")
+		} else if day, ok = atoi(q[8:10]); !ok || day < 1 || day > daysIn(time.Month(month), year) {
+			return dt, dateTimeErr(s, "bad day")
+		}
+		dt.HasDate = true
+		q = q[10:]
+		if q == "" {
+			return dt.done(year, month, day, hour, min, sec, nsec, loc), nil
+		} else if q[0] != 'T' && q[0] != 't' && q[0] != ' ' {
+			return dt, dateTimeErr(s, "extra text after date")
+		}
+		q = q[1:]
+	}
+	if len(q) < 5 || q[2] != ':' {
+		return dt, dateTimeErr(s, "bad time")
+	}
+	var ok bool
+	if hour, ok = atoi(q[0:2]); !ok || hour > 23 {
+		return dt, dateTimeErr(s, "bad hour")
+	} else if min, ok = atoi(q[3:5]); !ok || min > 59 {
+		return dt, dateTimeErr(s, "bad minute")
+	}
+	q = q[5:]
+	if len(q) > 0 && q[0] == ':' {
+		if len(q) < 3 {
+			return dt, dateTimeErr(s, "bad second")
+		} else if sec, ok = atoi(q[1:3]); !ok || sec > 59 {
+			return dt, dateTimeErr(s, "bad second")
+		}
+		q = q[3:]
+		if len(q) > 0 && (q[0] == '.' || q[0] == ',') {
+			i := 1
+			for i < len(q) && '0' <= q[i] && q[i] <= '9' {
+				if i <= 9 {
+					nsec = nsec*10 + int(q[i]-'0')
+				}
+				i++
+			}
+			if i == 1 {
+				return dt, dateTimeErr(s, "bad fractional second")
+			}
+			for j := i; j <= 9; j++ {
+				nsec *= 10
+			}
+			q = q[i:]
+		}
+	}
+	dt.HasTime = true
+	if q == "" {
+		return dt.done(year, month, day, hour, min, sec, nsec, loc), nil
+	}
+	if q == "Z" || q == "z" {
+		dt.HasZone = true
+		return dt.done(year, month, day, hour, min, sec, nsec, loc), nil
+	}
+	if q[0] != '+' && q[0] != '-' {
+		return dt, dateTimeErr(s, "extra text after time")
+	}
+	var zh, zm int
+	switch {
+	case len(q) == 3:
+		zh, ok = atoi(q[1:3])
+	case len(q) == 5:
+		zh, ok = atoi(q[1:3])
+		if ok {
+			zm, ok = atoi(q[3:5])
+		}
+	case len(q) == 6 && q[3] == ':':
+		zh, ok = atoi(q[1:3])
+		if ok {
+			zm, ok = atoi(q[4:6])
+		}
+	default:
+		ok = false
+	}
+	if !ok || zh > 23 || zm > 59 {
+		return dt, dateTimeErr(s, "bad zone offset")
+	}
+	offset := zh*60*60 + zm*60
+	if q[0] == '-' {
+		offset = -offset
+	}
+	if offset != 0 {
+		loc = time.FixedZone("", offset)
+	}
+	dt.HasZone = true
+	return dt.done(year, month, day, hour, min, sec, nsec, loc), nil
+}
+
+func (dt DateTime) done(year, month, day, hour, min, sec, nsec int, loc *time.Location) DateTime {
+	if !dt.HasDate {
+		month, day = 1, 1
+	}
+	dt.Time = time.Date(year, time.Month(month), day, hour, min, sec, nsec, loc)
+	return dt
+}
+
+func atoi(s string) (int, bool) {
+	var n int
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, false
+		}
+		n = n*10 + int(s[i]-'0')
+	}
+	return n, true
+}
+
+func daysIn(m time.Month, year int) int {
+	return time.Date(year, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func dateTimeErr(value, message string) error {
+	return &time.ParseError{
+		Value:   value,
+		Message: ": " + packageName + "invalid ISO 8601 date/time: " + message,
+	}
+}
+
+// DateTimeLayoutFromMetadata returns the date/time layout specified in
+// the metadata of a column, or the empty string if the metadata does
+// not specify one.
+//
+// FlatGeobuf column metadata is free-form, but is conventionally a JSON
+// object. A column specifies its own date/time layout, in the format
+// understood by time.Time.Format, using the "dateTimeLayout" member of
+// the object. For example, the column metadata
+//
+//	{"dateTimeLayout": "2006-01-02"}
+//
+// causes Props.SetDateTime to write only the date part of its value to
+// the column.
+func DateTimeLayoutFromMetadata(metadata string) string {
+	if !strings.HasPrefix(strings.TrimSpace(metadata), "{") {
+		return ""
+	}
+	var v struct {
+		DateTimeLayout string `json:"dateTimeLayout"`
+	}
+	if json.Unmarshal([]byte(metadata), &v) != nil {
+		return ""
+	}
+	return v.DateTimeLayout
+}
@@ -0,0 +1,222 @@
+package props
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+)
+
+func TestParseDateTime(t *testing.T) {
+	zone := func(offset int) *time.Location { return time.FixedZone("", offset) }
+	testCases := []struct {
+		in      string
+		want    time.Time
+		hasDate bool
+		hasTime bool
+		hasZone bool
+		// str is the expected output of String, if it differs from in.
+		str string
+	}{
+		{
+			in:      "2023-07-14",
+			want:    time.Date(2023, 7, 14, 0, 0, 0, 0, time.UTC),
+			hasDate: true,
+		},
+		{
+			in:      "15:04",
+			want:    time.Date(0, 1, 1, 15, 4, 0, 0, time.UTC),
+			hasTime: true,
+			str:     "15:04:00",
+		},
+		{
+			in:      "15:04:05.25",
+			want:    time.Date(0, 1, 1, 15, 4, 5, 250000000, time.UTC),
+			hasTime: true,
+		},
+		{
+			in:      "2023-07-14T15:04:05",
+			want:    time.Date(2023, 7, 14, 15, 4, 5, 0, time.UTC),
+			hasDate: true,
+			hasTime: true,
+		},
+		{
+			in:      "2023-07-14 15:04:05,123456789123Z",
+			want:    time.Date(2023, 7, 14, 15, 4, 5, 123456789, time.UTC),
+			hasDate: true,
+			hasTime: true,
+			hasZone: true,
+			str:     "2023-07-14T15:04:05.123456789Z",
+		},
+		{
+			in:      "2024-02-29t23:59:59-07",
+			want:    time.Date(2024, 2, 29, 23, 59, 59, 0, zone(-7*60*60)),
+			hasDate: true,
+			hasTime: true,
+			hasZone: true,
+			str:     "2024-02-29T23:59:59-07:00",
+		},
+		{
+			in:      "2023-07-14T15:04+0530",
+			want:    time.Date(2023, 7, 14, 15, 4, 0, 0, zone(5*60*60+30*60)),
+			hasDate: true,
+			hasTime: true,
+			hasZone: true,
+			str:     "2023-07-14T15:04:00+05:30",
+		},
+		{
+			in:      "2023-07-14T15:04:05-00:00",
+			want:    time.Date(2023, 7, 14, 15, 4, 5, 0, time.UTC),
+			hasDate: true,
+			hasTime: true,
+			hasZone: true,
+			str:     "2023-07-14T15:04:05Z",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.in, func(t *testing.T) {
+			got, err := ParseDateTime(testCase.in)
+			if err != nil {
+				t.Fatalf("ParseDateTime: %v", err)
+			}
+			if !got.Time.Equal(testCase.want) || got.Time.Format(time.RFC3339Nano) != testCase.want.Format(time.RFC3339Nano) {
+				t.Errorf("got time %v, want %v", got.Time, testCase.want)
+			}
+			if got.HasDate != testCase.hasDate || got.HasTime != testCase.hasTime || got.HasZone != testCase.hasZone {
+				t.Errorf("got date, time, zone %t, %t, %t, want %t, %t, %t", got.HasDate, got.HasTime, got.HasZone, testCase.hasDate, testCase.hasTime, testCase.hasZone)
+			}
+			if partial := !testCase.hasDate || !testCase.hasTime || !testCase.hasZone; got.IsPartial() != partial {
+				t.Errorf("got IsPartial %t, want %t", got.IsPartial(), partial)
+			}
+			str := testCase.str
+			if str == "" {
+				str = testCase.in
+			}
+			if got.String() != str {
+				t.Errorf("got String %q, want %q", got.String(), str)
+			}
+		})
+	}
+}
+
+func TestParseDateTimeErrors(t *testing.T) {
+	testCases := []string{
+		"",
+		"2023",
+		"2023-13-01",
+		"2023-02-29",
+		"2023-07-14X15:04",
+		"2023-07-14T",
+		"24:00",
+		"15:60",
+		"15:04:60",
+		"15:04:5",
+		"15:04:05.",
+		"15:04:05 PM",
+		"15:04+7",
+		"15:04+07:60",
+		"15:04Z07",
+		"abcd-ef-gh",
+	}
+	for _, in := range testCases {
+		t.Run(in, func(t *testing.T) {
+			got, err := ParseDateTime(in)
+			var parseErr *time.ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("got %v, %v, want *time.ParseError", got, err)
+			} else if parseErr.Value != in {
+				t.Errorf("got error value %q, want %q", parseErr.Value, in)
+			}
+		})
+	}
+}
+
+func TestDateTimeLayoutFromMetadata(t *testing.T) {
+	testCases := []struct {
+		metadata string
+		want     string
+	}{
+		{"", ""},
+		{"free text", ""},
+		{`{"dateTimeLayout": "2006-01-02"}`, "2006-01-02"},
+		{` {"other": 1}`, ""},
+		{`{"dateTimeLayout": 3}`, ""},
+		{`{broken`, ""},
+	}
+	for _, testCase := range testCases {
+		if got := DateTimeLayoutFromMetadata(testCase.metadata); got != testCase.want {
+			t.Errorf("DateTimeLayoutFromMetadata(%q) = %q, want %q", testCase.metadata, got, testCase.want)
+		}
+	}
+}
+
+func TestSetDateTimeLayout(t *testing.T) {
+	columns := []Column{
+		{Name: "plain", Type: flat.ColumnTypeDateTime},
+		{Name: "date", Type: flat.ColumnTypeDateTime, Metadata: `{"dateTimeLayout": "2006-01-02"}`},
+		{Name: "other", Type: flat.ColumnTypeDateTime, Metadata: `{"other": "2006"}`},
+		{Name: "n", Type: flat.ColumnTypeInt, Metadata: `{"dateTimeLayout": "2006"}`},
+	}
+	value := time.Date(2024, 3, 4, 5, 6, 7, 800000000, time.FixedZone("", 3600))
+	testCases := []struct {
+		name string
+		opts []Option
+		want [3]string
+	}{
+		{
+			name: "default",
+			want: [3]string{"2024-03-04T05:06:07.8+01:00", "2024-03-04", "2024-03-04T05:06:07.8+01:00"},
+		},
+		{
+			name: "option",
+			opts: []Option{WithDateTimeLayout("2006-01-02 15:04")},
+			want: [3]string{"2024-03-04 05:06", "2024-03-04", "2024-03-04 05:06"},
+		},
+		{
+			name: "empty option",
+			opts: []Option{WithDateTimeLayout("")},
+			want: [3]string{"2024-03-04T05:06:07.8+01:00", "2024-03-04", "2024-03-04T05:06:07.8+01:00"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			for _, kind := range []string{"Schema", "flat"} {
+				var p *Props
+				if kind == "Schema" {
+					p = NewProps(NewSchema(columns), testCase.opts...)
+				} else {
+					p = PropsFromFlat(flatSchema(t, columns...), nil, testCase.opts...)
+				}
+				for col, want := range testCase.want {
+					if err := p.SetDateTimeName(columns[col].Name, value); err != nil {
+						t.Fatalf("%s: SetDateTimeName(%q): %v", kind, columns[col].Name, err)
+					}
+					if got, err := p.GetDateTimeString(col); err != nil || got != want {
+						t.Errorf("%s: column %q: got %q, %v, want %q", kind, columns[col].Name, got, err, want)
+					}
+				}
+				// SetDateTimeValue writes the parts present, whatever the
+				// layout.
+				if err := p.SetDateTimeValue(1, DateTime{Time: value, HasDate: true, HasTime: true, HasZone: true}); err != nil {
+					t.Fatal(err)
+				}
+				if got, _ := p.GetDateTimeString(1); got != "2024-03-04T05:06:07.8+01:00" {
+					t.Errorf("%s: SetDateTimeValue: got %q", kind, got)
+				}
+				if err := p.SetDateTime(3, value); !errors.Is(err, ErrTypeMismatch) {
+					t.Errorf("%s: Int column: got %v, want ErrTypeMismatch", kind, err)
+				}
+			}
+		})
+	}
+
+	// A value written with a date-only layout reads back as midnight UTC.
+	p := NewProps(NewSchema(columns))
+	if err := p.SetDateTime(1, value); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := p.GetDateTime(1); err != nil || !got.Equal(time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("GetDateTime: got %v, %v, want 2024-03-04 midnight UTC", got, err)
+	}
+}
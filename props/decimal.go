@@ -0,0 +1,230 @@
+package props
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/gogama/flatgeobuf-convert/interop"
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+)
+
+// This file contains the decimal accessors, which treat the values of
+// Float and Double columns as decimal numbers with the precision and
+// scale declared by the column.
+//
+// A binary floating point value generally isn't exactly equal to the
+// decimal number it was set from: for example, the Double closest to
+// 0.1 is slightly more than 0.1. The decimal accessors therefore read a
+// value as the shortest decimal number which converts back to the same
+// floating point value, rounded to the column scale if it has one. The
+// decimal setters only accept values which read back unchanged, so a
+// decimal round trips losslessly through the column.
+
+// GetDecimal returns the value of a Float or Double column as a decimal
+// number. If the column has a scale, the value is rounded to that many
+// digits after the decimal point, with halves rounded away from zero.
+// A column only has a scale if it declares both a positive Precision
+// and a non-negative Scale, the same rule Validator uses.
+//
+// GetDecimal returns an error wrapping ErrTypeMismatch for other column
+// types, and an error wrapping ErrPrecision if the value is NaN or
+// infinite.
+func (p *Props) GetDecimal(col int) (*big.Rat, error) {
+	s, err := p.GetDecimalString(col)
+	if err != nil {
+		return nil, err
+	}
+	r, _ := new(big.Rat).SetString(s)
+	return r, nil
+}
+
+func (p *Props) GetDecimalName(name string) (*big.Rat, error) {
+	col, err := p.name2Col(name)
+	if err != nil {
+		return nil, err
+	}
+	return p.GetDecimal(col)
+}
+
+// GetDecimalString returns the value of a Float or Double column as a
+// decimal string, in the same way as GetDecimal. If the column has a
+// scale, the string has exactly that many digits after the decimal
+// point, so a Double column with scale 2 holding 12.5 gives "12.50".
+// Otherwise, the string is the shortest decimal representation of the
+// value, without an exponent.
+func (p *Props) GetDecimalString(col int) (string, error) {
+	f, bits, err := p.getDecimalFloat(col)
+	if err != nil {
+		return "", err
+	} else if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("%w: column %q: value %v is not a finite decimal", ErrPrecision, p.columnName(col), f)
+	}
+	_, scale := p.columnDecimal(col)
+	return decimalString(f, bits, scale), nil
+}
+
+func (p *Props) GetDecimalStringName(name string) (string, error) {
+	col, err := p.name2Col(name)
+	if err != nil {
+		return "", err
+	}
+	return p.GetDecimalString(col)
+}
+
+// SetDecimal sets the value of a Float or Double column from a decimal
+// number.
+//
+// SetDecimal returns an error wrapping ErrPrecision, and leaves the
+// column unchanged, if value has more digits after the decimal point
+// than the column scale, more digits in total than the column
+// precision allows, or cannot be read back unchanged by GetDecimal
+// because the column type cannot represent it closely enough. Values
+// which are not finite decimals, such as 1/3, are always rejected.
+func (p *Props) SetDecimal(col int, value *big.Rat) error {
+	t := p.columnType(col)
+	if t != flat.ColumnTypeFloat && t != flat.ColumnTypeDouble {
+		return p.typeErr(col, flat.ColumnTypeDouble)
+	}
+	precision, scale := p.columnDecimal(col)
+	intDigits, fracDigits, ok := ratDigits(value)
+	if !ok {
+		return fmt.Errorf("%w: column %q: value %s is not a finite decimal", ErrPrecision, p.columnName(col), value.RatString())
+	} else if scale >= 0 && fracDigits > int(scale) {
+		return fmt.Errorf("%w: column %q: value %s has %d digits after the decimal point but scale is %d", ErrPrecision, p.columnName(col), value.FloatString(fracDigits), fracDigits, scale)
+	} else if precision > 0 && scale >= 0 && intDigits > int(precision-scale) {
+		return fmt.Errorf("%w: column %q: value %s has %d digits before the decimal point but precision %d and scale %d allow %d", ErrPrecision, p.columnName(col), value.FloatString(fracDigits), intDigits, precision, scale, precision-scale)
+	} else if precision > 0 && scale < 0 && intDigits+fracDigits > int(precision) {
+		return fmt.Errorf("%w: column %q: value %s has %d digits but precision is %d", ErrPrecision, p.columnName(col), value.FloatString(fracDigits), intDigits+fracDigits, precision)
+	}
+	bits := 64
+	if t == flat.ColumnTypeFloat {
+		bits = 32
+	}
+	f, _ := value.Float64()
+	if bits == 32 {
+		f32, _ := value.Float32()
+		f = float64(f32)
+	}
+	if r, ok := new(big.Rat).SetString(decimalString(f, bits, scale)); !ok || r.Cmp(value) != 0 {
+		return fmt.Errorf("%w: column %q: value %s cannot be stored exactly in a %s column", ErrPrecision, p.columnName(col), value.FloatString(fracDigits), t)
+	}
+	if bits == 32 {
+		return p.SetFloat(col, float32(f))
+	}
+	return p.SetDouble(col, f)
+}
+
+func (p *Props) SetDecimalName(name string, value *big.Rat) error {
+	col, err := p.name2Col(name)
+	if err != nil {
+		return err
+	}
+	return p.SetDecimal(col, value)
+}
+
+// errInvalidDecimal is the cause of the TypeError for a string which
+// SetDecimalString cannot parse.
+var errInvalidDecimal = errors.New("invalid decimal string")
+
+// SetDecimalString sets the value of a Float or Double column from a
+// decimal string, such as "-12.50" or "1.5e3", in the same way as
+// SetDecimal. It returns a *TypeError if value is not a decimal number.
+func (p *Props) SetDecimalString(col int, value string) error {
+	r, ok := new(big.Rat).SetString(value)
+	if !ok || strings.ContainsRune(value, '/') {
+		return p.valueErr(col, value, errInvalidDecimal)
+	}
+	return p.SetDecimal(col, r)
+}
+
+func (p *Props) SetDecimalStringName(name string, value string) error {
+	col, err := p.name2Col(name)
+	if err != nil {
+		return err
+	}
+	return p.SetDecimalString(col, value)
+}
+
+// getDecimalFloat returns the value of a Float or Double column as a
+// float64, with the size in bits of the column type.
+func (p *Props) getDecimalFloat(col int) (float64, int, error) {
+	switch p.columnType(col) {
+	case flat.ColumnTypeFloat:
+		f, err := p.GetFloat(col)
+		return float64(f), 32, err
+	case flat.ColumnTypeDouble:
+		f, err := p.GetDouble(col)
+		return f, 64, err
+	default:
+		return 0, 0, p.typeErr(col, flat.ColumnTypeDouble)
+	}
+}
+
+// columnDecimal returns the precision and scale of a column. A
+// precision less than or equal to zero, or a scale less than zero,
+// means the column doesn't declare it. As with Validator, the scale is
+// only declared if the precision is, so a column with a zero Scale and
+// no Precision has no scale.
+func (p *Props) columnDecimal(col int) (precision, scale int32) {
+	precision, scale = -1, -1
+	if p.fastSchema != nil {
+		c := p.fastSchema.Column(col)
+		precision, scale = c.Precision, c.Scale
+	} else if p.flatSchema != nil {
+		_ = interop.FlatBufferSafe(func() error {
+			var obj flat.Column
+			if p.flatSchema.Columns(&obj, col) {
+				precision, scale = obj.Precision(), obj.Scale()
+			}
+			return nil
+		})
+	}
+	if precision <= 0 {
+		scale = -1
+	}
+	return
+}
+
+// decimalString returns the shortest decimal representation of a
+// floating point value of the given size in bits, rounded to scale
+// digits after the decimal point if scale is not negative.
+func decimalString(f float64, bits int, scale int32) string {
+	s := strconv.FormatFloat(f, 'f', -1, bits)
+	if scale < 0 {
+		return s
+	}
+	r, _ := new(big.Rat).SetString(s)
+	return r.FloatString(int(scale))
+}
+
+// ratDigits returns the number of digits before and after the decimal
+// point in the decimal representation of r, ignoring the sign and
+// leading zeros before the decimal point. The return value ok is false
+// if r has no finite decimal representation.
+func ratDigits(r *big.Rat) (intDigits, fracDigits int, ok bool) {
+	d := new(big.Int).Set(r.Denom())
+	var twos, fives int
+	var m big.Int
+	two, five := big.NewInt(2), big.NewInt(5)
+	for m.Mod(d, two).Sign() == 0 {
+		d.Quo(d, two)
+		twos++
+	}
+	for m.Mod(d, five).Sign() == 0 {
+		d.Quo(d, five)
+		fives++
+	}
+	if d.Cmp(big.NewInt(1)) != 0 {
+		return 0, 0, false
+	}
+	fracDigits = twos
+	if fives > fracDigits {
+		fracDigits = fives
+	}
+	intPart, _, _ := strings.Cut(new(big.Rat).Abs(r).FloatString(fracDigits), ".")
+	return len(strings.TrimLeft(intPart, "0")), fracDigits, true
+}
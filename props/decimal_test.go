@@ -0,0 +1,164 @@
+package props
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+)
+
+func TestDecimal(t *testing.T) {
+	testCases := []struct {
+		name   string
+		col    Column
+		set    string
+		get    string
+		setErr error
+	}{
+		{
+			name: "double without precision or scale",
+			col:  Column{Name: "d", Type: flat.ColumnTypeDouble},
+			set:  "12.5",
+			get:  "12.5",
+		},
+		{
+			name: "double without precision or scale, many digits",
+			col:  Column{Name: "d", Type: flat.ColumnTypeDouble},
+			set:  "-1234567.125",
+			get:  "-1234567.125",
+		},
+		{
+			name: "double with unset precision and scale",
+			col:  Column{Name: "d", Type: flat.ColumnTypeDouble, Precision: -1, Scale: -1},
+			set:  "0.001",
+			get:  "0.001",
+		},
+		{
+			name: "double with scale but no precision",
+			col:  Column{Name: "d", Type: flat.ColumnTypeDouble, Scale: 1},
+			set:  "12.25",
+			get:  "12.25",
+		},
+		{
+			name: "double with precision and scale",
+			col:  Column{Name: "d", Type: flat.ColumnTypeDouble, Precision: 5, Scale: 2},
+			set:  "12.5",
+			get:  "12.50",
+		},
+		{
+			name: "double with precision and zero scale",
+			col:  Column{Name: "d", Type: flat.ColumnTypeDouble, Precision: 3, Scale: 0},
+			set:  "125",
+			get:  "125",
+		},
+		{
+			name:   "too many digits after the decimal point",
+			col:    Column{Name: "d", Type: flat.ColumnTypeDouble, Precision: 5, Scale: 2},
+			set:    "1.234",
+			setErr: ErrPrecision,
+		},
+		{
+			name:   "too many digits before the decimal point",
+			col:    Column{Name: "d", Type: flat.ColumnTypeDouble, Precision: 5, Scale: 2},
+			set:    "1234.5",
+			setErr: ErrPrecision,
+		},
+		{
+			name:   "too many digits for precision without scale",
+			col:    Column{Name: "d", Type: flat.ColumnTypeDouble, Precision: 4, Scale: -1},
+			set:    "123.45",
+			setErr: ErrPrecision,
+		},
+		{
+			name: "float",
+			col:  Column{Name: "f", Type: flat.ColumnTypeFloat},
+			set:  "0.1",
+			get:  "0.1",
+		},
+		{
+			name:   "float cannot hold value exactly",
+			col:    Column{Name: "f", Type: flat.ColumnTypeFloat},
+			set:    "16777217",
+			setErr: ErrPrecision,
+		},
+		{
+			name:   "wrong column type",
+			col:    Column{Name: "i", Type: flat.ColumnTypeInt},
+			set:    "1",
+			setErr: ErrTypeMismatch,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			for _, kind := range []string{"Schema", "flat"} {
+				var p *Props
+				if kind == "Schema" {
+					p = NewProps(NewSchema([]Column{testCase.col}))
+				} else {
+					p = PropsFromFlat(flatSchema(t, testCase.col), nil)
+				}
+				err := p.SetDecimalString(0, testCase.set)
+				if testCase.setErr != nil {
+					if !errors.Is(err, testCase.setErr) {
+						t.Fatalf("%s: SetDecimalString(%q) error = %v, want %v", kind, testCase.set, err, testCase.setErr)
+					} else if p.Has(0) {
+						t.Errorf("%s: column has a value after failed SetDecimalString", kind)
+					}
+					continue
+				} else if err != nil {
+					t.Fatalf("%s: SetDecimalString(%q): %v", kind, testCase.set, err)
+				}
+				get, err := p.GetDecimalString(0)
+				if err != nil {
+					t.Fatalf("%s: GetDecimalString: %v", kind, err)
+				} else if get != testCase.get {
+					t.Errorf("%s: GetDecimalString = %q, want %q", kind, get, testCase.get)
+				}
+				r, err := p.GetDecimal(0)
+				if err != nil {
+					t.Fatalf("%s: GetDecimal: %v", kind, err)
+				} else if want, _ := new(big.Rat).SetString(testCase.get); r.Cmp(want) != 0 {
+					t.Errorf("%s: GetDecimal = %s, want %s", kind, r.RatString(), want.RatString())
+				}
+			}
+		})
+	}
+}
+
+func TestDecimalErrors(t *testing.T) {
+	p := NewProps(NewSchema([]Column{
+		{Name: "d", Type: flat.ColumnTypeDouble},
+		{Name: "s", Type: flat.ColumnTypeString},
+	}))
+	if err := p.SetDecimal(0, big.NewRat(1, 3)); !errors.Is(err, ErrPrecision) {
+		t.Errorf("SetDecimal(1/3) error = %v, want %v", err, ErrPrecision)
+	}
+	for _, s := range []string{"1/2", "abc", ""} {
+		var typeErr *TypeError
+		if err := p.SetDecimalString(0, s); !errors.As(err, &typeErr) || typeErr.Value != s || typeErr.Name != "d" {
+			t.Errorf("SetDecimalString(%q) error = %v, want *TypeError", s, err)
+		}
+	}
+	if err := p.SetDouble(0, math.NaN()); err != nil {
+		t.Fatal(err)
+	} else if _, err = p.GetDecimal(0); !errors.Is(err, ErrPrecision) {
+		t.Errorf("GetDecimal(NaN) error = %v, want %v", err, ErrPrecision)
+	}
+	p.Delete(0)
+	if _, err := p.GetDecimal(0); !errors.Is(err, ErrNoValue) {
+		t.Errorf("GetDecimal of absent column error = %v, want %v", err, ErrNoValue)
+	}
+	var typeErr *TypeError
+	if _, err := p.GetDecimalString(1); !errors.As(err, &typeErr) {
+		t.Errorf("GetDecimalString of string column error = %v, want *TypeError", err)
+	} else if typeErr.Col != 1 || typeErr.Expected != flat.ColumnTypeString {
+		t.Errorf("TypeError = %+v", typeErr)
+	}
+	if err := p.SetDecimalStringName("d", "2.5"); err != nil {
+		t.Fatalf("SetDecimalStringName: %v", err)
+	} else if s, err := p.GetDecimalStringName("d"); err != nil || s != "2.5" {
+		t.Errorf("GetDecimalStringName = %q, %v, want \"2.5\", nil", s, err)
+	}
+}
@@ -0,0 +1,294 @@
+package props
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// Equal reports whether a and b contain the same values, compared by
+// column name and by value rather than by byte layout.
+//
+// The schemas of a and b may differ, as long as they are compatible:
+// every column name in both schemas must have types with a common
+// supertype (see Supertype), otherwise Equal returns an error wrapping
+// ErrTypeMismatch. A column which is in only one schema is treated as
+// absent in the other. Numeric values are compared by numeric value, so
+// an Int column holding 5 equals a Double column holding 5.0, and
+// String and Json values are compared as text. Absent and null values
+// are equal to each other, since the FlatGeobuf property format does
+// not distinguish them.
+//
+// DateTime values are parsed with ParseDateTime and compared in the
+// canonical form written by DateTime.String, with values that have a
+// zone converted to UTC first. So "2024-01-01T01:00:00+01:00" equals
+// "2024-01-01 00:00Z", but a value without a zone is a wall clock time
+// and never equals a value with one. A DateTime value which cannot be
+// parsed is compared as text, as is a DateTime compared with a String,
+// which uses the canonical form of the DateTime value.
+//
+// If the error return value is non-nil, the bool return value is false.
+func Equal(a, b *Props) (bool, error) {
+	changes, err := diff(a, b, true)
+	if err != nil {
+		return false, err
+	}
+	return len(changes) == 0, nil
+}
+
+// Hash returns a content hash of the values in p which is consistent
+// with Equal: if Equal reports that two Props are equal, their hashes
+// are equal too, regardless of the order of columns in their schemas or
+// of values in their property buffers.
+//
+// The hash is the 64-bit FNV-1a hash of a canonical encoding of the
+// values, ordered by column name.
+func Hash(p *Props) (uint64, error) {
+	n := p.numColumns()
+	type entry struct {
+		name string
+		v    normValue
+	}
+	entries := make([]entry, 0, n)
+	for col := 0; col < n; col++ {
+		v, err := p.normValue(col)
+		if err != nil {
+			return 0, err
+		} else if v.kind != normNone {
+			entries = append(entries, entry{p.columnName(col), v})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].name < entries[j].name
+	})
+	h := fnv.New64a()
+	var buf [8]byte
+	for i := range entries {
+		binary.LittleEndian.PutUint64(buf[:], uint64(len(entries[i].name)))
+		_, _ = h.Write(buf[:])
+		_, _ = h.Write([]byte(entries[i].name))
+		entries[i].v.hash(h.Write, buf[:])
+	}
+	return h.Sum64(), nil
+}
+
+// ValueChangeKind identifies the kind of a ValueChange.
+type ValueChangeKind int
+
+const (
+	// ValueAdded means the column has a value only in the new Props.
+	ValueAdded ValueChangeKind = iota + 1
+	// ValueRemoved means the column has a value only in the old Props.
+	ValueRemoved
+	// ValueChanged means the column has different values in the old
+	// and new Props.
+	ValueChanged
+)
+
+func (k ValueChangeKind) String() string {
+	switch k {
+	case ValueAdded:
+		return "Added"
+	case ValueRemoved:
+		return "Removed"
+	case ValueChanged:
+		return "Changed"
+	default:
+		return "ValueChangeKind(" + strconv.Itoa(int(k)) + ")"
+	}
+}
+
+// ValueChange is one difference between the values of an old and a new
+// Props.
+type ValueChange struct {
+	// Kind is the kind of change.
+	Kind ValueChangeKind
+	// Name is the column name.
+	Name string
+	// OldCol is the column index in the old Props, or -1 if the column
+	// is not in the old schema.
+	OldCol int
+	// NewCol is the column index in the new Props, or -1 if the column
+	// is not in the new schema.
+	NewCol int
+	// Old is the old value, as returned by GetValue, or nil if the
+	// column was added.
+	Old any
+	// New is the new value, as returned by GetValue, or nil if the
+	// column was removed.
+	New any
+}
+
+func (c ValueChange) String() string {
+	return fmt.Sprintf("%s %q: %v -> %v", c.Kind, c.Name, c.Old, c.New)
+}
+
+// Diff compares an old Props, a, with a new Props, b, and lists the
+// columns whose values differ, using the same rules as Equal. Columns
+// of a are listed first, in column order, followed by columns only in
+// b.
+func Diff(a, b *Props) ([]ValueChange, error) {
+	return diff(a, b, false)
+}
+
+func diff(a, b *Props, stopAtFirst bool) (changes []ValueChange, err error) {
+	na, nb := a.numColumns(), b.numColumns()
+	seen := make([]bool, nb)
+	for i := 0; i < na; i++ {
+		name := a.columnName(i)
+		j, err := b.name2Col(name)
+		if errors.Is(err, ErrNoColumn) {
+			j = -1
+		} else if err != nil {
+			return nil, err
+		} else if _, ok := Supertype(a.columnType(i), b.columnType(j)); !ok {
+			return nil, &TypeError{Feature: a.feature, Col: i, Name: name, Expected: a.columnType(i), Actual: b.columnType(j)}
+		} else {
+			seen[j] = true
+		}
+		va, err := a.normValue(i)
+		if err != nil {
+			return nil, err
+		}
+		vb := normValue{}
+		if j >= 0 {
+			if vb, err = b.normValue(j); err != nil {
+				return nil, err
+			}
+		}
+		if va.equal(&vb) {
+			continue
+		}
+		c := ValueChange{Name: name, OldCol: i, NewCol: j}
+		switch {
+		case vb.kind == normNone:
+			c.Kind = ValueRemoved
+		case va.kind == normNone:
+			c.Kind = ValueAdded
+		default:
+			c.Kind = ValueChanged
+		}
+		if stopAtFirst {
+			return []ValueChange{c}, nil
+		}
+		if va.kind != normNone {
+			c.Old, _ = a.GetValue(i)
+		}
+		if vb.kind != normNone {
+			c.New, _ = b.GetValue(j)
+		}
+		changes = append(changes, c)
+	}
+	for j := 0; j < nb; j++ {
+		if seen[j] {
+			continue
+		}
+		vb, err := b.normValue(j)
+		if err != nil {
+			return nil, err
+		} else if vb.kind == normNone {
+			continue
+		}
+		c := ValueChange{Kind: ValueAdded, Name: b.columnName(j), OldCol: -1, NewCol: j}
+		if stopAtFirst {
+			return []ValueChange{c}, nil
+		}
+		c.New, _ = b.GetValue(j)
+		changes = append(changes, c)
+	}
+	return changes, nil
+}
+
+// normKind is the kind of a normValue.
+type normKind byte
+
+const (
+	normNone normKind = iota
+	normBool
+	normInt
+	normUint
+	normFloat
+	normText
+	normBinary
+)
+
+// normValue is a property value normalized so that values which are
+// equal by value, but have different column types, compare equal.
+type normValue struct {
+	kind normKind
+	n    uint64 // Bool, integer, or float bits.
+	b    []byte // Text or binary.
+}
+
+// normValue returns the normalized value of a column. Absent and null
+// values have kind normNone.
+func (p *Props) normValue(col int) (normValue, error) {
+	raw, err := p.rawValue(col)
+	if err != nil || raw == nil {
+		return normValue{}, err
+	}
+	t := p.columnType(col)
+	switch {
+	case t == flat.ColumnTypeBool:
+		var n uint64
+		if raw[0] != 0 {
+			n = 1
+		}
+		return normValue{kind: normBool, n: n}, nil
+	case t == flat.ColumnTypeULong:
+		u := rawUint64(t, raw)
+		if u > math.MaxInt64 {
+			return normValue{kind: normUint, n: u}, nil
+		}
+		return normValue{kind: normInt, n: u}, nil
+	case isSigned(t) || isUnsigned(t):
+		return normValue{kind: normInt, n: uint64(rawInt64(t, raw))}, nil
+	case isFloat(t):
+		f := rawFloat64(t, raw)
+		if f == math.Trunc(f) && -(1<<63) <= f && f < 1<<63 {
+			return normValue{kind: normInt, n: uint64(int64(f))}, nil
+		} else if math.IsNaN(f) {
+			f = math.NaN()
+		}
+		return normValue{kind: normFloat, n: math.Float64bits(f)}, nil
+	case t == flat.ColumnTypeBinary:
+		return normValue{kind: normBinary, b: raw[flatbuffers.SizeUint32:]}, nil
+	case t == flat.ColumnTypeDateTime:
+		text := raw[flatbuffers.SizeUint32:]
+		dt, err := ParseDateTime(viewString(text))
+		if err != nil {
+			return normValue{kind: normText, b: text}, nil
+		} else if dt.HasZone {
+			dt.Time = dt.Time.UTC()
+		}
+		return normValue{kind: normText, b: []byte(dt.String())}, nil
+	case isText(t):
+		return normValue{kind: normText, b: raw[flatbuffers.SizeUint32:]}, nil
+	default:
+		return normValue{}, errInvalidColumnType(t)
+	}
+}
+
+func (v *normValue) equal(w *normValue) bool {
+	return v.kind == w.kind && v.n == w.n && bytes.Equal(v.b, w.b)
+}
+
+func (v *normValue) hash(write func([]byte) (int, error), buf []byte) {
+	_, _ = write([]byte{byte(v.kind)})
+	if v.kind == normText || v.kind == normBinary {
+		binary.LittleEndian.PutUint64(buf, uint64(len(v.b)))
+		_, _ = write(buf[:8])
+		_, _ = write(v.b)
+	} else {
+		binary.LittleEndian.PutUint64(buf, v.n)
+		_, _ = write(buf[:8])
+	}
+}
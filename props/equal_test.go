@@ -0,0 +1,222 @@
+package props
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+)
+
+func TestEqual(t *testing.T) {
+	s1 := NewSchema([]Column{
+		{Name: "n", Type: flat.ColumnTypeInt},
+		{Name: "s", Type: flat.ColumnTypeString},
+		{Name: "b", Type: flat.ColumnTypeBool},
+	})
+	s2 := NewSchema([]Column{
+		{Name: "s", Type: flat.ColumnTypeString},
+		{Name: "n", Type: flat.ColumnTypeDouble},
+		{Name: "b", Type: flat.ColumnTypeBool},
+		{Name: "x", Type: flat.ColumnTypeLong},
+	})
+	make1 := func(n int32, s string) *Props {
+		p := NewProps(s1)
+		if err := p.SetInt(0, n); err != nil {
+			t.Fatal(err)
+		} else if err = p.SetString(1, s); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	make2 := func(s string, n float64, x *int64) *Props {
+		p := NewProps(s2)
+		if err := p.SetString(0, s); err != nil {
+			t.Fatal(err)
+		} else if err = p.SetDouble(1, n); err != nil {
+			t.Fatal(err)
+		} else if err = p.SetNull(2); err != nil {
+			t.Fatal(err)
+		}
+		if x != nil {
+			if err := p.SetLong(3, *x); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return p
+	}
+	seven := int64(7)
+	testCases := []struct {
+		name  string
+		a, b  *Props
+		equal bool
+		diff  []ValueChangeKind
+	}{
+		{
+			name:  "same values, different schemas",
+			a:     make1(5, "hi"),
+			b:     make2("hi", 5.0, nil),
+			equal: true,
+		},
+		{
+			name:  "different numeric value",
+			a:     make1(5, "hi"),
+			b:     make2("hi", 5.5, nil),
+			equal: false,
+			diff:  []ValueChangeKind{ValueChanged},
+		},
+		{
+			name:  "different string",
+			a:     make1(5, "hi"),
+			b:     make2("ho", 5, nil),
+			equal: false,
+			diff:  []ValueChangeKind{ValueChanged},
+		},
+		{
+			name:  "column only in new schema",
+			a:     make1(5, "hi"),
+			b:     make2("hi", 5, &seven),
+			equal: false,
+			diff:  []ValueChangeKind{ValueAdded},
+		},
+		{
+			name:  "column only in old schema",
+			a:     make2("hi", 5, &seven),
+			b:     make1(5, "hi"),
+			equal: false,
+			diff:  []ValueChangeKind{ValueRemoved},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			equal, err := Equal(testCase.a, testCase.b)
+			if err != nil {
+				t.Fatalf("Equal: %v", err)
+			} else if equal != testCase.equal {
+				t.Errorf("Equal = %t, want %t", equal, testCase.equal)
+			}
+			changes, err := Diff(testCase.a, testCase.b)
+			if err != nil {
+				t.Fatalf("Diff: %v", err)
+			} else if len(changes) != len(testCase.diff) {
+				t.Fatalf("Diff = %v, want kinds %v", changes, testCase.diff)
+			}
+			for i := range changes {
+				if changes[i].Kind != testCase.diff[i] {
+					t.Errorf("Diff[%d] = %v, want kind %v", i, changes[i], testCase.diff[i])
+				}
+			}
+			ha, err := Hash(testCase.a)
+			if err != nil {
+				t.Fatalf("Hash(a): %v", err)
+			}
+			hb, err := Hash(testCase.b)
+			if err != nil {
+				t.Fatalf("Hash(b): %v", err)
+			}
+			if testCase.equal && ha != hb {
+				t.Errorf("Hash(a) = %x, Hash(b) = %x, want equal hashes", ha, hb)
+			} else if !testCase.equal && ha == hb {
+				t.Errorf("Hash(a) = Hash(b) = %x for unequal props", ha)
+			}
+		})
+	}
+}
+
+func TestEqualHashStorageOrder(t *testing.T) {
+	schema := NewSchema([]Column{
+		{Name: "a", Type: flat.ColumnTypeInt},
+		{Name: "b", Type: flat.ColumnTypeString},
+	})
+	a := NewProps(schema)
+	b := NewProps(schema)
+	if err := a.SetInt(0, 1); err != nil {
+		t.Fatal(err)
+	} else if err = a.SetString(1, "x"); err != nil {
+		t.Fatal(err)
+	} else if err = b.SetString(1, "x"); err != nil {
+		t.Fatal(err)
+	} else if err = b.SetInt(0, 1); err != nil {
+		t.Fatal(err)
+	}
+	if equal, err := Equal(a, b); err != nil || !equal {
+		t.Errorf("Equal = %t, %v, want true, nil", equal, err)
+	}
+	ha, _ := Hash(a)
+	hb, _ := Hash(b)
+	if ha != hb {
+		t.Errorf("Hash(a) = %x, Hash(b) = %x, want equal hashes", ha, hb)
+	}
+}
+
+func TestEqualErrors(t *testing.T) {
+	a := NewProps(NewSchema([]Column{{Name: "v", Type: flat.ColumnTypeString}}))
+	b := NewProps(NewSchema([]Column{{Name: "v", Type: flat.ColumnTypeInt}}))
+	equal, err := Equal(a, b)
+	var typeErr *TypeError
+	if !errors.As(err, &typeErr) {
+		t.Errorf("Equal of incompatible types error = %v, want *TypeError", err)
+	} else if equal {
+		t.Error("Equal of incompatible types = true, want false")
+	}
+
+	schema := NewSchema([]Column{{Name: "v", Type: flat.ColumnTypeInt}})
+	corrupt := PropsFromFlat(schema, []byte{0, 0, 1}, WithParseMode(ParseStrict))
+	equal, err = Equal(corrupt, NewProps(schema))
+	if !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Equal of corrupt props error = %v, want %v", err, ErrCorrupt)
+	} else if equal {
+		t.Error("Equal of corrupt props = true, want false")
+	}
+	if _, err = Hash(corrupt); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Hash of corrupt props error = %v, want %v", err, ErrCorrupt)
+	}
+}
+
+func TestEqualDateTime(t *testing.T) {
+	dateTimeSchema := NewSchema([]Column{{Name: "d", Type: flat.ColumnTypeDateTime}})
+	stringSchema := NewSchema([]Column{{Name: "d", Type: flat.ColumnTypeString}})
+	props := func(schema *Schema, text string) *Props {
+		return PropsFromFlat(schema, stringEntry(0, uint32(len(text)), text))
+	}
+	testCases := []struct {
+		a, b  string
+		equal bool
+	}{
+		{"2024-01-01T01:00:00+01:00", "2024-01-01T00:00:00Z", true},
+		{"2024-01-01T01:00:00+01:00", "2023-12-31 19:00-0500", true},
+		{"2024-01-01T00:00:00.500Z", "2024-01-01T00:00:00,5+00", true},
+		{"2024-01-01T00:00:00Z", "2024-01-01T00:00:01Z", false},
+		{"2024-01-01T00:00", "2024-01-01 00:00:00", true},
+		{"2024-01-01T00:00:00", "2024-01-01T00:00:00Z", false},
+		{"2024-01-01", "2024-01-01T00:00:00", false},
+		{"12:00+02:00", "10:00Z", true},
+		{"not a date", "not a date", true},
+		{"not a date", "Not a date", false},
+	}
+	for _, testCase := range testCases {
+		a, b := props(dateTimeSchema, testCase.a), props(dateTimeSchema, testCase.b)
+		if got, err := Equal(a, b); err != nil || got != testCase.equal {
+			t.Errorf("Equal(%q, %q): got %v, %v, want %v", testCase.a, testCase.b, got, err, testCase.equal)
+		}
+		ha, errA := Hash(a)
+		hb, errB := Hash(b)
+		if errA != nil || errB != nil || (ha == hb) != testCase.equal {
+			t.Errorf("Hash(%q) == Hash(%q): got %v, %v, %v, want %v", testCase.a, testCase.b, ha == hb, errA, errB, testCase.equal)
+		}
+	}
+
+	// A DateTime is compared with a String using its canonical form.
+	for _, testCase := range []struct {
+		dateTime, s string
+		equal       bool
+	}{
+		{"2024-01-01T01:00:00+01:00", "2024-01-01T00:00:00Z", true},
+		{"2024-01-01T01:00:00+01:00", "2024-01-01T01:00:00+01:00", false},
+		{"not a date", "not a date", true},
+	} {
+		a, b := props(dateTimeSchema, testCase.dateTime), props(stringSchema, testCase.s)
+		if got, err := Equal(a, b); err != nil || got != testCase.equal {
+			t.Errorf("Equal(DateTime %q, String %q): got %v, %v, want %v", testCase.dateTime, testCase.s, got, err, testCase.equal)
+		}
+	}
+}
@@ -3,12 +3,23 @@ package props
 import (
 	"errors"
 	"fmt"
+	"strings"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
 )
 
 var (
 	ErrNoColumn               = textErr("no such column")
 	ErrNoValue                = textErr("no value for column")
+	ErrNull                   = textErr("null value for column")
+	ErrRequired               = textErr("required column has no value")
+	ErrInvalidSchema          = textErr("invalid schema")
 	ErrTypeMismatch           = textErr("type mismatch: value type does not match schema column type")
+	ErrInvalidJSON            = textErr("invalid JSON value")
+	ErrPrecision              = textErr("decimal value exceeds column precision or scale")
+	ErrCorrupt                = textErr("corrupt property buffer")
+	ErrSchemaMismatch         = textErr("props schema does not match")
+	ErrInvalidMapping         = textErr("invalid migration mapping")
 	errStringSizeOverflowsInt = textErr("string-ish column size prefix overflows int")
 	errStringSizeCorrupt      = textErr("string-ish column size prefix is missing or too short")
 	errUnknownColumnType      = textErr("unknown column type")
@@ -16,6 +27,151 @@ var (
 
 const packageName = "props: "
 
+// ParseError describes a problem found in a FlatGeobuf property buffer.
+// It is returned as an error when parsing in ParseStrict mode, and
+// recorded as a warning by Props in ParseLenient mode. ParseError
+// wraps ErrCorrupt.
+type ParseError struct {
+	// Feature is the index of the feature whose property buffer has the
+	// problem, as given to Props.SetFeature, or -1 if unknown.
+	Feature int
+	// Offset is the byte offset within the property buffer where the
+	// problem was found.
+	Offset int
+	// Col is the column index of the entry with the problem, or -1 if
+	// the column index could not be read. It may be out of range for
+	// the schema, if that is the problem.
+	Col int
+	// Reason describes the problem.
+	Reason string
+}
+
+func (e *ParseError) Error() string {
+	var b strings.Builder
+	_, _ = b.WriteString(ErrCorrupt.Error())
+	if e.Feature >= 0 {
+		_, _ = fmt.Fprintf(&b, ": feature %d", e.Feature)
+	}
+	_, _ = fmt.Fprintf(&b, ": byte %d", e.Offset)
+	if e.Col >= 0 {
+		_, _ = fmt.Fprintf(&b, ": column %d", e.Col)
+	}
+	_, _ = b.WriteString(": ")
+	_, _ = b.WriteString(e.Reason)
+	return b.String()
+}
+
+func (e *ParseError) Unwrap() error {
+	return ErrCorrupt
+}
+
+// ColumnError describes a problem with the value of one column of a
+// Props. It wraps Err, which is ErrNoColumn, ErrNoValue, ErrNull,
+// ErrRequired, or ErrInvalidJSON, so it may be tested with errors.Is
+// against those errors. It also wraps Cause, if there is one.
+type ColumnError struct {
+	// Feature is the index of the feature the Props belongs to, as
+	// given to Props.SetFeature, or -1 if unknown.
+	Feature int
+	// Col is the column index, or -1 if a column was looked up by a
+	// name which isn't in the schema.
+	Col int
+	// Name is the column name, or empty if it isn't known.
+	Name string
+	// Err is the sentinel error describing the problem.
+	Err error
+	// Cause is the underlying error, such as the error from the JSON
+	// decoder for ErrInvalidJSON, or nil if there isn't one.
+	Cause error
+}
+
+func (e *ColumnError) Error() string {
+	var b strings.Builder
+	_, _ = b.WriteString(e.Err.Error())
+	writeColumnContext(&b, e.Feature, e.Col, e.Name)
+	writeCause(&b, e.Cause)
+	return b.String()
+}
+
+func (e *ColumnError) Unwrap() []error {
+	return unwrapCause(e.Err, e.Cause)
+}
+
+// TypeError describes an attempt to read or write the value of a column
+// as a type other than the column type, or to store a Go value which
+// cannot be converted to the column type. It wraps ErrTypeMismatch, and
+// also Cause if there is one.
+type TypeError struct {
+	// Feature is the index of the feature the Props belongs to, as
+	// given to Props.SetFeature, or -1 if unknown.
+	Feature int
+	// Col is the column index.
+	Col int
+	// Name is the column name, or empty if it isn't known.
+	Name string
+	// Expected is the column type in the schema.
+	Expected flat.ColumnType
+	// Actual is the type that was used, such as ColumnTypeString for a
+	// call to GetString, or the column type in another schema. It is
+	// meaningful only if Value is nil.
+	Actual flat.ColumnType
+	// Value is the Go value which could not be converted to the column
+	// type, such as a value given to SetValue or FromMap, or nil if a
+	// column type was used instead.
+	Value any
+	// Cause is the underlying error, such as the error from parsing a
+	// date-time string, or nil if there isn't one.
+	Cause error
+}
+
+func (e *TypeError) Error() string {
+	var b strings.Builder
+	_, _ = b.WriteString(ErrTypeMismatch.Error())
+	writeColumnContext(&b, e.Feature, e.Col, e.Name)
+	if e.Value != nil {
+		_, _ = fmt.Fprintf(&b, ": column has type %s but value %v of type %T was used", e.Expected, e.Value, e.Value)
+	} else {
+		_, _ = fmt.Fprintf(&b, ": column has type %s but %s was used", e.Expected, e.Actual)
+	}
+	writeCause(&b, e.Cause)
+	return b.String()
+}
+
+func (e *TypeError) Unwrap() []error {
+	return unwrapCause(ErrTypeMismatch, e.Cause)
+}
+
+// writeColumnContext writes the feature index, column index, and column
+// name of an error, omitting those which are unknown.
+func writeColumnContext(b *strings.Builder, feature, col int, name string) {
+	if feature >= 0 {
+		_, _ = fmt.Fprintf(b, ": feature %d", feature)
+	}
+	if col >= 0 {
+		_, _ = fmt.Fprintf(b, ": column %d", col)
+	}
+	if name != "" {
+		_, _ = fmt.Fprintf(b, ": %q", name)
+	}
+}
+
+// writeCause writes the underlying error of an error, if it has one.
+func writeCause(b *strings.Builder, cause error) {
+	if cause != nil {
+		_, _ = b.WriteString(": ")
+		_, _ = b.WriteString(cause.Error())
+	}
+}
+
+// unwrapCause returns the errors wrapped by an error with sentinel err
+// and underlying error cause, which may be nil.
+func unwrapCause(err, cause error) []error {
+	if cause == nil {
+		return []error{err}
+	}
+	return []error{err, cause}
+}
+
 func textErr(text string) error {
 	return errors.New(packageName + text)
 }
@@ -24,6 +180,6 @@ func fmtErr(format string, a ...any) error {
 	return fmt.Errorf(packageName+format, a...)
 }
 
-func textPanic(text string) {
-	panic(packageName + text)
+func errInvalidColumnType(columnType flat.ColumnType) error {
+	return fmtErr("invalid column type: %s", columnType)
 }
@@ -0,0 +1,156 @@
+package props
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+)
+
+func TestErrors(t *testing.T) {
+	schema := NewSchema([]Column{
+		{Name: "n", Type: flat.ColumnTypeInt},
+		{Name: "j", Type: flat.ColumnTypeJson},
+		{Name: "d", Type: flat.ColumnTypeDateTime},
+	})
+	testCases := []struct {
+		name     string
+		call     func(p *Props) error
+		sentinel error
+		col      int
+		value    bool
+		cause    bool
+	}{
+		{
+			name:     "SetValue unmapped type",
+			call:     func(p *Props) error { return p.SetValue(0, struct{}{}) },
+			sentinel: ErrTypeMismatch,
+			col:      0,
+			value:    true,
+		},
+		{
+			name:     "SetValue wrong column type",
+			call:     func(p *Props) error { return p.SetValue(0, "x") },
+			sentinel: ErrTypeMismatch,
+			col:      0,
+		},
+		{
+			name:     "coerce unconvertible value",
+			call:     func(p *Props) error { return p.coerce(0, []int{1}) },
+			sentinel: ErrTypeMismatch,
+			col:      0,
+			value:    true,
+		},
+		{
+			name:     "coerce bad date-time",
+			call:     func(p *Props) error { return p.coerce(2, "yesterday") },
+			sentinel: ErrTypeMismatch,
+			col:      2,
+			value:    true,
+			cause:    true,
+		},
+		{
+			name:     "coerce invalid raw JSON",
+			call:     func(p *Props) error { return p.coerce(0, json.RawMessage("{")) },
+			sentinel: ErrInvalidJSON,
+			col:      0,
+			cause:    true,
+		},
+		{
+			name:     "coerce raw JSON into JSON column",
+			call:     func(p *Props) error { return p.coerce(1, json.RawMessage("[1,")) },
+			sentinel: ErrInvalidJSON,
+			col:      1,
+			cause:    true,
+		},
+		{
+			name:     "coerce unencodable value into JSON column",
+			call:     func(p *Props) error { return p.coerce(1, math.NaN()) },
+			sentinel: ErrTypeMismatch,
+			col:      1,
+			value:    true,
+			cause:    true,
+		},
+		{
+			name:     "SetJSON strict",
+			call:     func(p *Props) error { return p.SetJSON(1, "{") },
+			sentinel: ErrInvalidJSON,
+			col:      1,
+		},
+		{
+			name:     "SetJSONValue unencodable",
+			call:     func(p *Props) error { return p.SetJSONValue(1, make(chan int)) },
+			sentinel: ErrTypeMismatch,
+			col:      1,
+			value:    true,
+			cause:    true,
+		},
+		{
+			name: "GetJSONInto wrong Go type",
+			call: func(p *Props) error {
+				if err := p.SetJSON(1, `"s"`); err != nil {
+					return err
+				}
+				var n int
+				return p.GetJSONInto(1, &n)
+			},
+			sentinel: ErrTypeMismatch,
+			col:      1,
+			value:    true,
+			cause:    true,
+		},
+		{
+			name: "GetJSONInto invalid stored JSON",
+			call: func(p *Props) error {
+				if err := p.setBinary(1, flat.ColumnTypeJson, []byte("{")); err != nil {
+					return err
+				}
+				var v any
+				return p.GetJSONInto(1, &v)
+			},
+			sentinel: ErrInvalidJSON,
+			col:      1,
+			cause:    true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			p := NewProps(schema, WithStrictJSON())
+			p.SetFeature(7)
+			err := testCase.call(p)
+			if !errors.Is(err, testCase.sentinel) {
+				t.Fatalf("got error %v, want one wrapping %v", err, testCase.sentinel)
+			}
+			var feature, col int
+			var name string
+			var value any
+			var cause error
+			var typeErr *TypeError
+			var colErr *ColumnError
+			switch {
+			case errors.As(err, &typeErr):
+				feature, col, name, value, cause = typeErr.Feature, typeErr.Col, typeErr.Name, typeErr.Value, typeErr.Cause
+				if typeErr.Expected != schema.Type(testCase.col) {
+					t.Errorf("got Expected %s, want %s", typeErr.Expected, schema.Type(testCase.col))
+				}
+			case errors.As(err, &colErr):
+				feature, col, name, cause = colErr.Feature, colErr.Col, colErr.Name, colErr.Cause
+			default:
+				t.Fatalf("got error %T, want *TypeError or *ColumnError", err)
+			}
+			if feature != 7 || col != testCase.col || name != schema.Name(testCase.col) {
+				t.Errorf("got feature %d, column %d %q, want feature 7, column %d %q", feature, col, name, testCase.col, schema.Name(testCase.col))
+			}
+			if (value != nil) != testCase.value {
+				t.Errorf("got Value %v, want value: %t", value, testCase.value)
+			}
+			if (cause != nil) != testCase.cause {
+				t.Errorf("got Cause %v, want cause: %t", cause, testCase.cause)
+			} else if cause != nil && !errors.Is(err, cause) {
+				t.Errorf("error %v does not wrap its cause %v", err, cause)
+			}
+		})
+	}
+}
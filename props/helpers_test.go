@@ -0,0 +1,101 @@
+package props
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf"
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// flatSchema returns cols as the columns of a FlatGeobuf header table,
+// so tests can exercise the code paths for schemas which aren't a
+// *Schema.
+func flatSchema(t *testing.T, cols ...Column) flatgeobuf.Schema {
+	t.Helper()
+	b := flatbuffers.NewBuilder(0)
+	offsets := make([]flatbuffers.UOffsetT, len(cols))
+	for i := range cols {
+		offsets[i] = cols[i].ToBuilder(b)
+	}
+	flat.HeaderStartColumnsVector(b, len(offsets))
+	for i := len(offsets) - 1; i >= 0; i-- {
+		b.PrependUOffsetT(offsets[i])
+	}
+	v := b.EndVector(len(offsets))
+	flat.HeaderStart(b)
+	flat.HeaderAddColumns(b, v)
+	b.Finish(flat.HeaderEnd(b))
+	return flat.GetRootAsHeader(b.FinishedBytes(), 0)
+}
+
+// mustBytes returns the property buffer of p, failing the test if it
+// cannot be encoded.
+func mustBytes(t *testing.T, p *Props) []byte {
+	t.Helper()
+	b, err := p.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes: %v", err)
+	}
+	return b
+}
+
+// intEntry returns a property buffer entry for an Int column.
+func intEntry(col int, v int32) []byte {
+	b := appendHeader(nil, col)
+	return binary.LittleEndian.AppendUint32(b, uint32(v))
+}
+
+// stringEntry returns a property buffer entry for a String column whose
+// size prefix is size, followed by s. Passing a size other than len(s)
+// makes a corrupt entry.
+func stringEntry(col int, size uint32, s string) []byte {
+	b := appendHeader(nil, col)
+	b = binary.LittleEndian.AppendUint32(b, size)
+	return append(b, s...)
+}
+
+// parseSchema is the schema the buffers of parseBuffers are under.
+var parseSchema = NewSchema([]Column{
+	{Name: "a", Type: flat.ColumnTypeInt},
+	{Name: "b", Type: flat.ColumnTypeString},
+	{Name: "c", Type: flat.ColumnTypeInt},
+})
+
+// parseBuffers are property buffers under parseSchema, some of them
+// corrupt. problem is the byte offset of the first problem, or -1 if
+// there isn't one.
+var parseBuffers = []struct {
+	name    string
+	data    []byte
+	problem int
+	// values are the values read in ParseLenient mode.
+	values map[int]any
+}{
+	{
+		name:    "valid",
+		data:    bytes.Join([][]byte{intEntry(0, 1), stringEntry(1, 1, "x")}, nil),
+		problem: -1,
+		values:  map[int]any{0: int32(1), 1: "x"},
+	},
+	{
+		name:    "duplicate",
+		data:    bytes.Join([][]byte{intEntry(0, 1), stringEntry(1, 1, "x"), intEntry(0, 2)}, nil),
+		problem: 13,
+		values:  map[int]any{0: int32(2), 1: "x"},
+	},
+	{
+		name:    "truncated value",
+		data:    bytes.Join([][]byte{intEntry(0, 1), stringEntry(1, 10, "xy")}, nil),
+		problem: 8,
+		values:  map[int]any{0: int32(1)},
+	},
+	{
+		name:    "column out of range",
+		data:    bytes.Join([][]byte{intEntry(0, 1), intEntry(9, 2), intEntry(2, 3)}, nil),
+		problem: 6,
+		values:  map[int]any{0: int32(1)},
+	},
+}
@@ -0,0 +1,113 @@
+package props
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// Each calls f once for every value present in the property buffer, in
+// the order the values are stored, passing the column index, column
+// name, and value. Values have the same Go types as returned by
+// GetValue. Absent and null columns are skipped.
+//
+// Unlike a loop over every column calling GetValue, Each decodes only
+// the values present, so its cost is proportional to the number of
+// values present rather than the number of columns in the schema.
+//
+// Each parses the property buffer in the same way as the other methods
+// which read values, so problems are treated according to the parse
+// mode. In ParseStrict mode, Each returns the ParseError without
+// calling f. In ParseLenient mode, Each calls f for every value parsed
+// before the first problem which makes the rest of the buffer
+// unreadable, and where a column has duplicate entries f is called
+// only for the last one. If f returns an error, Each stops and returns
+// that error.
+//
+// The Props must not be mutated while Each is running.
+func (p *Props) Each(f func(col int, name string, value any) error) error {
+	return p.walk(func(col, offset, size int) error {
+		value, err := p.valueAt(col, offset, size)
+		if err != nil {
+			return err
+		}
+		return f(col, p.columnName(col), value)
+	})
+}
+
+// walk calls f for every value in the offset table, in storage order,
+// passing the column index, the value offset, and the value size.
+// Entries which the offset table doesn't point at, because a later
+// entry for the same column replaced them, are skipped.
+func (p *Props) walk(f func(col, offset, size int) error) error {
+	if err := p.Parse(); err != nil {
+		return err
+	}
+	n := len(p.offset)
+	b := p.data.Bytes()
+	offset := 0
+	for offset < len(b) {
+		col, valueOffset, size, err := nextEntry(b, offset, n, p.columnType)
+		if err != nil {
+			// Parse has already recorded the problem as a warning.
+			return nil
+		} else if p.offset[col] == valueOffset {
+			if err := f(col, valueOffset, size); err != nil {
+				return err
+			}
+		}
+		offset = valueOffset + size
+	}
+	return nil
+}
+
+// valueAt decodes the value of a column at a given offset and size in
+// the property buffer, returning the same Go type as GetValue.
+func (p *Props) valueAt(col, offset, size int) (any, error) {
+	b := p.data.Bytes()[offset : offset+size]
+	columnType := p.columnType(col)
+	switch columnType {
+	case flat.ColumnTypeBool:
+		return b[0] != 0, nil
+	case flat.ColumnTypeByte:
+		return int8(b[0]), nil
+	case flat.ColumnTypeUByte:
+		return b[0], nil
+	case flat.ColumnTypeShort:
+		return flatbuffers.GetInt16(b), nil
+	case flat.ColumnTypeUShort:
+		return flatbuffers.GetUint16(b), nil
+	case flat.ColumnTypeInt:
+		return flatbuffers.GetInt32(b), nil
+	case flat.ColumnTypeUInt:
+		return flatbuffers.GetUint32(b), nil
+	case flat.ColumnTypeLong:
+		return flatbuffers.GetInt64(b), nil
+	case flat.ColumnTypeULong:
+		return flatbuffers.GetUint64(b), nil
+	case flat.ColumnTypeFloat:
+		return flatbuffers.GetFloat32(b), nil
+	case flat.ColumnTypeDouble:
+		return flatbuffers.GetFloat64(b), nil
+	case flat.ColumnTypeString, flat.ColumnTypeJson:
+		return string(b[flatbuffers.SizeUint32:]), nil
+	case flat.ColumnTypeBinary:
+		dup := make([]byte, len(b)-flatbuffers.SizeUint32)
+		copy(dup, b[flatbuffers.SizeUint32:])
+		return dup, nil
+	case flat.ColumnTypeDateTime:
+		s := string(b[flatbuffers.SizeUint32:])
+		v, err := ParseDateTime(s)
+		var x *time.ParseError
+		if errors.As(err, &x) {
+			return s, nil
+		} else if v.IsPartial() {
+			return v, nil
+		}
+		return v.Time, nil
+	default:
+		return nil, errInvalidColumnType(columnType)
+	}
+}
@@ -0,0 +1,57 @@
+package props
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEach(t *testing.T) {
+	for _, testCase := range parseBuffers {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Run("lenient", func(t *testing.T) {
+				p := NewProps(parseSchema)
+				p.Reset(parseSchema, testCase.data)
+				got := make(map[int]any)
+				err := p.Each(func(col int, name string, value any) error {
+					if _, ok := got[col]; ok {
+						t.Errorf("column %d visited more than once", col)
+					} else if name != parseSchema.Name(col) {
+						t.Errorf("column %d: got name %q, want %q", col, name, parseSchema.Name(col))
+					}
+					got[col] = value
+					return nil
+				})
+				if err != nil {
+					t.Fatalf("Each: %v", err)
+				}
+				if len(got) != len(testCase.values) {
+					t.Errorf("got values %v, want %v", got, testCase.values)
+				}
+				for col, want := range testCase.values {
+					if got[col] != want {
+						t.Errorf("column %d: got %v, want %v", col, got[col], want)
+					}
+				}
+				if (len(p.Warnings()) > 0) != (testCase.problem >= 0) {
+					t.Errorf("got warnings %v, want problem at byte %d", p.Warnings(), testCase.problem)
+				}
+			})
+			t.Run("strict", func(t *testing.T) {
+				p := NewProps(parseSchema, WithParseMode(ParseStrict))
+				p.Reset(parseSchema, testCase.data)
+				var n int
+				err := p.Each(func(int, string, any) error {
+					n++
+					return nil
+				})
+				if testCase.problem < 0 {
+					if err != nil || n != len(testCase.values) {
+						t.Errorf("got %d values and error %v, want %d values", n, err, len(testCase.values))
+					}
+				} else if !errors.Is(err, ErrCorrupt) || n != 0 {
+					t.Errorf("got %d values and error %v, want ErrCorrupt and no values", n, err)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package props
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// MarshalJSON implements json.Marshaler. The property values are
+// written as a JSON object whose keys are the column names, in column
+// order. Absent values are omitted and null values are written as JSON
+// null. Keys collected under the UnknownKeysCollect option follow the
+// column values, in sorted order.
+//
+// Each value is written as the JSON type appropriate to its column
+// type: Bool values as JSON booleans, numeric values as JSON numbers,
+// String and DateTime values as JSON strings, JSON values as embedded
+// JSON, and Binary values as base64 strings, in the same way that
+// encoding/json writes a []byte. MarshalJSON returns an error if a
+// Float or Double value is NaN or infinite, or if a JSON value is not
+// valid JSON, since these cannot be represented.
+func (p *Props) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	_ = buf.WriteByte('{')
+	n := p.numColumns()
+	written := false
+	for col := 0; col < n; col++ {
+		raw, err := p.rawValue(col)
+		if err != nil {
+			return nil, err
+		} else if raw == nil && !p.IsNull(col) {
+			continue
+		}
+		if written {
+			_ = buf.WriteByte(',')
+		}
+		if err = writeJSONString(&buf, p.columnName(col)); err != nil {
+			return nil, err
+		}
+		_ = buf.WriteByte(':')
+		if raw == nil {
+			_, _ = buf.WriteString("null")
+		} else if err = p.writeJSONValue(&buf, col, raw); err != nil {
+			return nil, err
+		}
+		written = true
+	}
+	unknown := make([]string, 0, len(p.unknown))
+	for name := range p.unknown {
+		unknown = append(unknown, name)
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		if written {
+			_ = buf.WriteByte(',')
+		}
+		if err := writeJSONString(&buf, name); err != nil {
+			return nil, err
+		}
+		_ = buf.WriteByte(':')
+		b, err := json.Marshal(p.unknown[name])
+		if err != nil {
+			return nil, fmtErr("key %q: %w", name, err)
+		}
+		_, _ = buf.Write(b)
+		written = true
+	}
+	_ = buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (p *Props) writeJSONValue(buf *bytes.Buffer, col int, raw []byte) error {
+	t := p.columnType(col)
+	var scratch [24]byte
+	var b []byte
+	switch {
+	case t == flat.ColumnTypeBool:
+		b = strconv.AppendBool(scratch[:0], raw[0] != 0)
+	case t == flat.ColumnTypeULong:
+		b = strconv.AppendUint(scratch[:0], rawUint64(t, raw), 10)
+	case isSigned(t) || isUnsigned(t):
+		b = strconv.AppendInt(scratch[:0], rawInt64(t, raw), 10)
+	case isFloat(t):
+		f := rawFloat64(t, raw)
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmtErr("column %q: value %v cannot be represented in JSON", p.columnName(col), f)
+		}
+		var err error
+		if t == flat.ColumnTypeFloat {
+			b, err = json.Marshal(float32(f))
+		} else {
+			b, err = json.Marshal(f)
+		}
+		if err != nil {
+			return err
+		}
+	case t == flat.ColumnTypeJson:
+		if err := json.Compact(buf, raw[flatbuffers.SizeUint32:]); err != nil {
+			return p.jsonErr(col, err)
+		}
+		return nil
+	case t == flat.ColumnTypeBinary:
+		var err error
+		if b, err = json.Marshal(raw[flatbuffers.SizeUint32:]); err != nil {
+			return err
+		}
+	case isText(t):
+		return writeJSONString(buf, viewString(raw[flatbuffers.SizeUint32:]))
+	default:
+		return errInvalidColumnType(t)
+	}
+	_, _ = buf.Write(b)
+	return nil
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	_, _ = buf.Write(b)
+	return nil
+}
+
+// UnmarshalJSON decodes a JSON object into a new Props under a schema.
+// The object keys are column names, and each value is coerced to the
+// type of its column:
+//   - Bool columns accept JSON booleans, and the strings "true" and
+//     "false" (or any other string accepted by strconv.ParseBool).
+//   - Integer columns accept JSON numbers and numeric strings with an
+//     integral value in range for the column type, so 5, 5.0, 5e0,
+//     and "5" are all accepted for an Int column but 5.5 is not.
+//     JSON booleans are accepted as zero or one.
+//   - Float and Double columns accept JSON numbers, numeric strings,
+//     and JSON booleans as zero or one.
+//   - String columns accept JSON strings, and JSON numbers and booleans
+//     as their literal text.
+//   - JSON columns accept any JSON value, which is stored as compact
+//     JSON text.
+//   - Binary columns accept JSON strings in standard base64 encoding.
+//   - DateTime columns accept JSON strings in ISO 8601 format (see
+//     ParseDateTime).
+//
+// JSON null sets the column to null, which is an error for a required
+// column. Values are stored in ascending column order. The rules above
+// are those of CoerceLoose, and can be changed with WithCoercion.
+//
+// UnmarshalJSON returns an error if data is not a JSON object, or for
+// the same reasons as FromMap, including for keys which are not column
+// names unless WithUnknownKeys says otherwise. Collected values of
+// unknown keys are decoded as by json.Unmarshal into an any.
+func UnmarshalJSON(schema *Schema, data []byte, opts ...Option) (*Props, error) {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmtErr("%w", err)
+	} else if m == nil {
+		return nil, textErr("JSON props must be an object")
+	}
+	p := NewProps(schema, opts...)
+	err := setMap(p, m, func(raw json.RawMessage) (v any, err error) {
+		err = json.Unmarshal(raw, &v)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
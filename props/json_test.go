@@ -0,0 +1,170 @@
+package props
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+)
+
+// jsonSchema has a column of every type.
+var jsonSchema = NewSchema([]Column{
+	{Name: "bool", Type: flat.ColumnTypeBool},
+	{Name: "byte", Type: flat.ColumnTypeByte},
+	{Name: "ubyte", Type: flat.ColumnTypeUByte},
+	{Name: "short", Type: flat.ColumnTypeShort},
+	{Name: "ushort", Type: flat.ColumnTypeUShort},
+	{Name: "int", Type: flat.ColumnTypeInt},
+	{Name: "uint", Type: flat.ColumnTypeUInt},
+	{Name: "long", Type: flat.ColumnTypeLong},
+	{Name: "ulong", Type: flat.ColumnTypeULong},
+	{Name: "float", Type: flat.ColumnTypeFloat},
+	{Name: "double", Type: flat.ColumnTypeDouble},
+	{Name: "string", Type: flat.ColumnTypeString},
+	{Name: "json", Type: flat.ColumnTypeJson},
+	{Name: "datetime", Type: flat.ColumnTypeDateTime},
+	{Name: "binary", Type: flat.ColumnTypeBinary},
+})
+
+func TestJSONRoundTrip(t *testing.T) {
+	p := NewProps(jsonSchema)
+	set := []error{
+		p.SetBool(0, true),
+		p.SetByte(1, math.MinInt8),
+		p.SetUByte(2, math.MaxUint8),
+		p.SetShort(3, math.MinInt16),
+		p.SetUShort(4, math.MaxUint16),
+		p.SetInt(5, math.MinInt32),
+		p.SetUInt(6, math.MaxUint32),
+		p.SetLong(7, math.MinInt64),
+		p.SetULong(8, math.MaxUint64),
+		p.SetFloat(9, 1.1),
+		p.SetDouble(10, 0.1),
+		p.SetString(11, "a \"quoted\" é"),
+		p.SetJSON(12, `{ "a": [1, 2] }`),
+		p.SetDateTimeString(13, "2024-01-02T03:04:05Z"),
+		p.SetBinary(14, []byte{0, 1, 0xff}),
+	}
+	if err := errors.Join(set...); err != nil {
+		t.Fatal(err)
+	}
+	data, err := p.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	want := `{"bool":true,"byte":-128,"ubyte":255,"short":-32768,"ushort":65535,` +
+		`"int":-2147483648,"uint":4294967295,"long":-9223372036854775808,` +
+		`"ulong":18446744073709551615,"float":1.1,"double":0.1,` +
+		`"string":"a \"quoted\" é","json":{"a":[1,2]},` +
+		`"datetime":"2024-01-02T03:04:05Z","binary":"AAH/"}`
+	if string(data) != want {
+		t.Errorf("MarshalJSON:\n got %s\nwant %s", data, want)
+	}
+	q, err := UnmarshalJSON(jsonSchema, data)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	for col := 0; col < jsonSchema.ColumnsLength(); col++ {
+		a, _ := p.GetValue(col)
+		b, err := q.GetValue(col)
+		if col == 12 {
+			a = `{"a":[1,2]}`
+		}
+		if err != nil || !reflect.DeepEqual(a, b) {
+			t.Errorf("column %q: got %v (%T), %v, want %v (%T)", jsonSchema.Name(col), b, b, err, a, a)
+		}
+	}
+}
+
+func TestMarshalJSONNonFinite(t *testing.T) {
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		p := NewProps(jsonSchema)
+		if err := p.SetDouble(10, f); err != nil {
+			t.Fatal(err)
+		}
+		if data, err := p.MarshalJSON(); err == nil {
+			t.Errorf("Double %v: got %s, want error", f, data)
+		}
+		p = NewProps(jsonSchema)
+		if err := p.SetFloat(9, float32(f)); err != nil {
+			t.Fatal(err)
+		}
+		if data, err := p.MarshalJSON(); err == nil {
+			t.Errorf("Float %v: got %s, want error", f, data)
+		}
+	}
+}
+
+func TestMarshalJSONInvalidJSON(t *testing.T) {
+	p := NewProps(jsonSchema)
+	if err := p.SetJSON(12, `{"a":`); err != nil {
+		t.Fatal(err)
+	}
+	_, err := p.MarshalJSON()
+	var columnErr *ColumnError
+	if !errors.Is(err, ErrInvalidJSON) || !errors.As(err, &columnErr) || columnErr.Name != "json" {
+		t.Errorf("got error %v, want ErrInvalidJSON for column \"json\"", err)
+	}
+}
+
+func TestJSONNull(t *testing.T) {
+	schema := NewSchema([]Column{
+		{Name: "a", Type: flat.ColumnTypeInt},
+		{Name: "b", Type: flat.ColumnTypeString},
+		{Name: "r", Type: flat.ColumnTypeInt, Required: true},
+	})
+	p := NewProps(schema)
+	if err := p.SetNull(1); err != nil {
+		t.Fatal(err)
+	} else if err = p.SetInt(2, 1); err != nil {
+		t.Fatal(err)
+	}
+	data, err := p.MarshalJSON()
+	if err != nil || string(data) != `{"b":null,"r":1}` {
+		t.Errorf("MarshalJSON: got %s, %v", data, err)
+	}
+	q, err := UnmarshalJSON(schema, []byte(`{"a":null,"r":2}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	} else if !q.IsNull(0) || q.Has(1) {
+		t.Errorf("got IsNull(0) %t, Has(1) %t, want true, false", q.IsNull(0), q.Has(1))
+	}
+	if _, err = UnmarshalJSON(schema, []byte(`{"r":null}`)); !errors.Is(err, ErrRequired) {
+		t.Errorf("null for required column: got error %v, want ErrRequired", err)
+	}
+}
+
+func TestUnmarshalJSONUnknownKeys(t *testing.T) {
+	schema := NewSchema([]Column{{Name: "a", Type: flat.ColumnTypeInt}})
+	data := []byte(`{"z":[true],"a":1,"y":"x"}`)
+	if _, err := UnmarshalJSON(schema, data); !errors.Is(err, ErrNoColumn) {
+		t.Errorf("default: got error %v, want ErrNoColumn", err)
+	}
+	p, err := UnmarshalJSON(schema, data, WithUnknownKeys(UnknownKeysIgnore))
+	if err != nil {
+		t.Fatalf("ignore: %v", err)
+	} else if got, err := p.MarshalJSON(); err != nil || string(got) != `{"a":1}` {
+		t.Errorf("ignore: got %s, %v", got, err)
+	}
+	p, err = UnmarshalJSON(schema, data, WithUnknownKeys(UnknownKeysCollect))
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	want := map[string]any{"z": []any{true}, "y": "x"}
+	if got := p.Unknown(); !reflect.DeepEqual(got, want) {
+		t.Errorf("collect: got unknown %v, want %v", got, want)
+	}
+	if got, err := p.MarshalJSON(); err != nil || string(got) != `{"a":1,"y":"x","z":[true]}` {
+		t.Errorf("collect: got %s, %v", got, err)
+	}
+}
+
+func TestUnmarshalJSONErrors(t *testing.T) {
+	for _, data := range []string{``, `null`, `[1]`, `"a"`, `{"a":}`, `{"a":1.5}`, `{"a":"x"}`} {
+		if p, err := UnmarshalJSON(parseSchema, []byte(data)); err == nil {
+			t.Errorf("%s: got %v, want error", data, p)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package props
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+)
+
+// FromMap creates a new Props under a schema from a map whose keys are
+// column names, such as the properties of a GeoJSON feature. Values
+// are stored in ascending column order.
+//
+// A nil or Null value sets the column to null, which is an error for a
+// required column. Other values are converted to the column type under
+// the rule set by WithCoercion. Under the default rule, CoerceLoose:
+//   - Bool columns accept bools, and the strings accepted by
+//     strconv.ParseBool.
+//   - Integer columns accept Go integer and floating point values,
+//     json.Number values, and numeric strings, as long as the value is
+//     integral and in range for the column type. So 5, 5.0, and "5"
+//     are all accepted for an Int column, but 5.5 and 1e10 are not.
+//     Bools are accepted as zero or one.
+//   - Float and Double columns accept numbers, numeric strings, and
+//     bools as zero or one.
+//   - String columns accept strings, and json.Number values and bools
+//     as their text.
+//   - JSON columns accept any value, which is stored as its
+//     encoding/json encoding.
+//   - Binary columns accept a []byte, or a string in standard base64
+//     encoding, which is how encoding/json represents a []byte.
+//   - DateTime columns accept a time.Time, a DateTime, or a string in
+//     ISO 8601 format (see ParseDateTime).
+//
+// Keys which are not column names are treated as set by
+// WithUnknownKeys. FromMap returns an error if any value cannot be
+// converted, or if there are unknown keys and the UnknownKeysError
+// rule applies. Errors for individual keys are joined using
+// errors.Join. Those caused by values of the wrong type wrap
+// ErrTypeMismatch, and those caused by unknown keys wrap ErrNoColumn.
+func FromMap(schema *Schema, m map[string]any, opts ...Option) (*Props, error) {
+	p := NewProps(schema, opts...)
+	if err := setMap(p, m, func(v any) (any, error) { return v, nil }); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// setMap sets the values of p from a map keyed by column name. The
+// decode function converts values of unknown keys into the form in
+// which they are collected.
+func setMap[V any](p *Props, m map[string]V, decode func(V) (any, error)) error {
+	var errs []error
+	schema := p.fastSchema
+	n := schema.ColumnsLength()
+	for col := 0; col < n; col++ {
+		if v, ok := m[schema.Name(col)]; ok {
+			if err := p.coerce(col, v); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if p.opts.unknownKeys == UnknownKeysIgnore {
+		return errors.Join(errs...)
+	}
+	var unknown []string
+	for name := range m {
+		if _, ok := schema.Index(name); !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		if p.opts.unknownKeys == UnknownKeysError {
+			errs = append(errs, p.noColumnErr(name))
+			continue
+		}
+		v, err := decode(m[name])
+		if err != nil {
+			errs = append(errs, fmtErr("key %q: %w", name, err))
+			continue
+		}
+		if p.unknown == nil {
+			p.unknown = make(map[string]any, len(unknown))
+		}
+		p.unknown[name] = v
+	}
+	return errors.Join(errs...)
+}
+
+// ToMap returns the property values as a map keyed by column name.
+// Absent values are omitted and null values map to nil. Other values
+// have the Go type returned by GetValue, except that date/time values
+// are returned as their stored text if p has the WithDateTimeStrings
+// option. Keys collected under the UnknownKeysCollect option are
+// included with their collected values.
+//
+// The returned map is newly allocated and may be retained and modified
+// by the caller. The error return value is non-nil if the underlying
+// property buffer is corrupt.
+func (p *Props) ToMap() (map[string]any, error) {
+	n := p.numColumns()
+	m := make(map[string]any, n+len(p.unknown))
+	for k, v := range p.unknown {
+		m[k] = v
+	}
+	for col := 0; col < n; col++ {
+		raw, err := p.rawValue(col)
+		if err != nil {
+			return nil, err
+		} else if raw == nil {
+			if p.IsNull(col) {
+				m[p.columnName(col)] = nil
+			}
+			continue
+		}
+		var v any
+		if p.opts.dateTimeStrings && p.columnType(col) == flat.ColumnTypeDateTime {
+			v, err = p.GetDateTimeString(col)
+		} else {
+			v, err = p.GetValue(col)
+		}
+		if err != nil {
+			return nil, err
+		}
+		m[p.columnName(col)] = v
+	}
+	return m, nil
+}
+
+// Unknown returns the keys which were not column names, and their
+// values, collected by FromMap or UnmarshalJSON under the
+// UnknownKeysCollect option. The return value is nil if no keys were
+// collected. The returned map belongs to p and must not be modified.
+func (p *Props) Unknown() map[string]any {
+	return p.unknown
+}
@@ -0,0 +1,186 @@
+package props
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+)
+
+func TestFromMapCoercion(t *testing.T) {
+	type want struct {
+		value any
+		ok    bool
+	}
+	// fail is the want for a value which is rejected.
+	fail := want{}
+	testCases := []struct {
+		name  string
+		typ   flat.ColumnType
+		value any
+		// loose, numeric, and none are the outcomes under CoerceLoose,
+		// CoerceNumeric, and CoerceNone.
+		loose, numeric, none want
+	}{
+		{"bool", flat.ColumnTypeBool, true, want{true, true}, want{true, true}, want{true, true}},
+		{"bool from string", flat.ColumnTypeBool, "false", want{false, true}, fail, fail},
+		{"bool from bad string", flat.ColumnTypeBool, "maybe", fail, fail, fail},
+		{"bool from number", flat.ColumnTypeBool, 1, fail, fail, fail},
+		{"int", flat.ColumnTypeInt, int32(5), want{int32(5), true}, want{int32(5), true}, want{int32(5), true}},
+		{"int from int", flat.ColumnTypeInt, 5, want{int32(5), true}, want{int32(5), true}, fail},
+		{"int from integral float", flat.ColumnTypeInt, 5.0, want{int32(5), true}, want{int32(5), true}, fail},
+		{"int from non-integral float", flat.ColumnTypeInt, 1.5, fail, fail, fail},
+		{"int from string", flat.ColumnTypeInt, "5", want{int32(5), true}, fail, fail},
+		{"int from exponent string", flat.ColumnTypeInt, "5e0", want{int32(5), true}, fail, fail},
+		{"int from json.Number", flat.ColumnTypeInt, json.Number("-7"), want{int32(-7), true}, want{int32(-7), true}, fail},
+		{"int from non-integral json.Number", flat.ColumnTypeInt, json.Number("1.5"), fail, fail, fail},
+		{"int from bool", flat.ColumnTypeInt, true, want{int32(1), true}, fail, fail},
+		{"int out of range", flat.ColumnTypeInt, int64(math.MaxInt32 + 1), fail, fail, fail},
+		{"int float out of range", flat.ColumnTypeInt, 1e10, fail, fail, fail},
+		{"byte out of range", flat.ColumnTypeByte, 128, fail, fail, fail},
+		{"byte at limit", flat.ColumnTypeByte, -128, want{int8(-128), true}, want{int8(-128), true}, fail},
+		{"long from uint64 out of range", flat.ColumnTypeLong, uint64(math.MaxUint64), fail, fail, fail},
+		{"ubyte negative", flat.ColumnTypeUByte, -1, fail, fail, fail},
+		{"ubyte at limit", flat.ColumnTypeUByte, 255, want{uint8(255), true}, want{uint8(255), true}, fail},
+		{"ushort out of range", flat.ColumnTypeUShort, 65536, fail, fail, fail},
+		{"ulong from json.Number", flat.ColumnTypeULong, json.Number("18446744073709551615"), want{uint64(math.MaxUint64), true}, want{uint64(math.MaxUint64), true}, fail},
+		{"ulong from bool", flat.ColumnTypeULong, false, want{uint64(0), true}, fail, fail},
+		{"uint from non-integral float", flat.ColumnTypeUInt, 1.5, fail, fail, fail},
+		{"float from double", flat.ColumnTypeFloat, 1.5, want{float32(1.5), true}, want{float32(1.5), true}, fail},
+		{"float out of range", flat.ColumnTypeFloat, 1e39, fail, fail, fail},
+		{"float from bool", flat.ColumnTypeFloat, true, want{float32(1), true}, fail, fail},
+		{"double from int", flat.ColumnTypeDouble, 3, want{3.0, true}, want{3.0, true}, fail},
+		{"double from string", flat.ColumnTypeDouble, "2.5", want{2.5, true}, fail, fail},
+		{"double from json.Number", flat.ColumnTypeDouble, json.Number("2.5"), want{2.5, true}, want{2.5, true}, fail},
+		{"double from bool", flat.ColumnTypeDouble, true, want{1.0, true}, fail, fail},
+		{"double from bad string", flat.ColumnTypeDouble, "x", fail, fail, fail},
+		{"string", flat.ColumnTypeString, "x", want{"x", true}, want{"x", true}, want{"x", true}},
+		{"string from json.Number", flat.ColumnTypeString, json.Number("1.50"), want{"1.50", true}, fail, fail},
+		{"string from bool", flat.ColumnTypeString, true, want{"true", true}, fail, fail},
+		{"string from int", flat.ColumnTypeString, 5, fail, fail, fail},
+		{"json", flat.ColumnTypeJson, map[string]any{"a": 1}, want{`{"a":1}`, true}, want{`{"a":1}`, true}, fail},
+		{"json from raw message", flat.ColumnTypeJson, json.RawMessage(` [1, 2] `), want{`[1,2]`, true}, want{`[1,2]`, true}, want{`[1,2]`, true}},
+		{"binary", flat.ColumnTypeBinary, []byte{1}, want{[]byte{1}, true}, want{[]byte{1}, true}, want{[]byte{1}, true}},
+		{"binary from base64", flat.ColumnTypeBinary, "AQ==", want{[]byte{1}, true}, want{[]byte{1}, true}, fail},
+		{"binary from bad base64", flat.ColumnTypeBinary, "!", fail, fail, fail},
+		{"datetime from string", flat.ColumnTypeDateTime, "2024-01-02", want{"2024-01-02", true}, want{"2024-01-02", true}, fail},
+		{"datetime from bad string", flat.ColumnTypeDateTime, "soon", fail, fail, fail},
+		{"datetime from time", flat.ColumnTypeDateTime, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), want{"2024-01-02T00:00:00Z", true}, want{"2024-01-02T00:00:00Z", true}, want{"2024-01-02T00:00:00Z", true}},
+	}
+	modes := []struct {
+		name     string
+		coercion Coercion
+		want     func(i int) want
+	}{
+		{"loose", CoerceLoose, func(i int) want { return testCases[i].loose }},
+		{"numeric", CoerceNumeric, func(i int) want { return testCases[i].numeric }},
+		{"none", CoerceNone, func(i int) want { return testCases[i].none }},
+	}
+	for i, testCase := range testCases {
+		for _, mode := range modes {
+			want := mode.want(i)
+			t.Run(testCase.name+"/"+mode.name, func(t *testing.T) {
+				schema := NewSchema([]Column{{Name: "x", Type: testCase.typ}})
+				p, err := FromMap(schema, map[string]any{"x": testCase.value}, WithCoercion(mode.coercion), WithDateTimeStrings())
+				if !want.ok {
+					if !errors.Is(err, ErrTypeMismatch) && !errors.Is(err, ErrInvalidJSON) {
+						t.Errorf("got %v, want ErrTypeMismatch", err)
+					}
+					return
+				} else if err != nil {
+					t.Fatalf("FromMap: %v", err)
+				}
+				m, err := p.ToMap()
+				if err != nil {
+					t.Fatalf("ToMap: %v", err)
+				} else if got := m["x"]; !reflect.DeepEqual(got, want.value) {
+					t.Errorf("got %v (%T), want %v (%T)", got, got, want.value, want.value)
+				}
+			})
+		}
+	}
+}
+
+func TestFromMap(t *testing.T) {
+	schema := NewSchema([]Column{
+		{Name: "a", Type: flat.ColumnTypeInt},
+		{Name: "b", Type: flat.ColumnTypeString},
+		{Name: "c", Type: flat.ColumnTypeInt, Required: true},
+		{Name: "d", Type: flat.ColumnTypeDouble},
+	})
+	p, err := FromMap(schema, map[string]any{"d": 1.5, "c": 2, "b": nil, "a": Null{}}, WithInsertionOrder())
+	if err != nil {
+		t.Fatalf("FromMap: %v", err)
+	}
+	if !p.IsNull(0) || !p.IsNull(1) {
+		t.Errorf("got IsNull %t, %t, want true, true", p.IsNull(0), p.IsNull(1))
+	}
+	// Values are stored in ascending column order.
+	want := binary.LittleEndian.AppendUint64(appendHeader(intEntry(2, 2), 3), math.Float64bits(1.5))
+	if got, err := p.AppendBytes(nil); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("got %v, %v, want %v", got, err, want)
+	}
+
+	// Every bad key is reported.
+	_, err = FromMap(schema, map[string]any{"a": "x", "c": nil, "z": 1})
+	if !errors.Is(err, ErrTypeMismatch) || !errors.Is(err, ErrRequired) || !errors.Is(err, ErrNoColumn) {
+		t.Errorf("got %v, want ErrTypeMismatch, ErrRequired, and ErrNoColumn", err)
+	}
+	var typeErr *TypeError
+	if !errors.As(err, &typeErr) || typeErr.Name != "a" || typeErr.Value != "x" {
+		t.Errorf("got %#v, want *TypeError for column \"a\"", typeErr)
+	}
+}
+
+func TestToMap(t *testing.T) {
+	schema := NewSchema([]Column{
+		{Name: "a", Type: flat.ColumnTypeInt},
+		{Name: "b", Type: flat.ColumnTypeString},
+		{Name: "c", Type: flat.ColumnTypeDateTime},
+		{Name: "d", Type: flat.ColumnTypeDouble},
+	})
+	p := NewProps(schema)
+	if err := errors.Join(p.SetInt(0, 1), p.SetNull(1), p.SetDateTimeString(2, "2024-01-02T03:04:05+01:00")); err != nil {
+		t.Fatal(err)
+	}
+	m, err := p.ToMap()
+	if err != nil {
+		t.Fatalf("ToMap: %v", err)
+	}
+	want := map[string]any{
+		"a": int32(1),
+		"b": nil,
+		"c": time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("", 3600)),
+	}
+	if len(m) != len(want) || m["a"] != want["a"] || m["b"] != nil {
+		t.Errorf("got %v, want %v", m, want)
+	} else if c, ok := m["c"].(time.Time); !ok || !c.Equal(want["c"].(time.Time)) {
+		t.Errorf("got c %v, want %v", m["c"], want["c"])
+	}
+	m["a"] = "changed"
+	if v, err := p.GetInt(0); err != nil || v != 1 {
+		t.Errorf("modifying map changed Props: got %d, %v", v, err)
+	}
+
+	p = NewProps(schema, WithDateTimeStrings())
+	if err := p.SetDateTimeString(2, "2024-01-02"); err != nil {
+		t.Fatal(err)
+	}
+	if m, err = p.ToMap(); err != nil || m["c"] != "2024-01-02" {
+		t.Errorf("WithDateTimeStrings: got %v, %v", m, err)
+	}
+
+	p, err = FromMap(schema, map[string]any{"a": 1, "z": []any{"x"}}, WithUnknownKeys(UnknownKeysCollect))
+	if err != nil {
+		t.Fatalf("FromMap: %v", err)
+	}
+	if m, err = p.ToMap(); err != nil || !reflect.DeepEqual(m, map[string]any{"a": int32(1), "z": []any{"x"}}) {
+		t.Errorf("UnknownKeysCollect: got %v, %v", m, err)
+	}
+}
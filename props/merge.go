@@ -0,0 +1,197 @@
+package props
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+)
+
+// Merge is the result of merging several schemas with MergeSchemas.
+type Merge struct {
+	// Schema is the union of the merged schemas.
+	Schema *Schema
+	// Tables contains one column remapping table per merged schema, in
+	// the order the schemas were given to MergeSchemas. Tables[k][i] is
+	// the index, within Schema, of column i of schema k.
+	Tables [][]int
+	// migrators contains one Migrator per merged schema.
+	migrators []*Migrator
+}
+
+// Remap converts p, whose schema must have the same columns as merged
+// schema number k, into a new Props under the union schema.
+func (m *Merge) Remap(k int, p *Props) (*Props, error) {
+	if k < 0 || k >= len(m.migrators) {
+		return nil, fmtErr("no merged schema %d", k)
+	}
+	return m.migrators[k].Migrate(p)
+}
+
+// MergeSchemas computes the union of several schemas, for example to
+// concatenate FlatGeobuf files with different schemas into one file.
+//
+// Columns are matched by name. The union schema contains every column
+// which is in any of the input schemas, in order of first appearance.
+// The attributes of each union column are unified as follows:
+//   - Type is the supertype of the input column types: the smallest
+//     type which every input type widens to (see Widens and Supertype).
+//     If there is no such type, MergeSchemas returns an error.
+//   - Required is only kept if the column is present and required in
+//     every input schema.
+//   - Unique and PrimaryKey are only kept if there is exactly one input
+//     schema, since uniqueness within each input does not imply
+//     uniqueness across inputs, and a column missing from some inputs
+//     is null in all of their rows.
+//   - Width takes the largest input value, and is unset if any input
+//     leaves it unset.
+//   - Precision and Scale are chosen so that every value which fits an
+//     input column also fits the union column: Scale is the largest
+//     input scale, and Precision is Scale plus the largest number of
+//     digits any input allows before the decimal point. For example,
+//     merging precision 5 and scale 2 with precision 10 and scale 0
+//     gives precision 12 and scale 2. Precision is unset if any input
+//     leaves it unset, and Scale is unset if any input with a precision
+//     leaves it unset.
+//   - Title, Description, and Metadata take the first non-empty input
+//     value.
+//
+// Use the returned Merge to find where each input column went, and to
+// re-encode Props from an input schema under the union schema.
+func MergeSchemas(schemas ...*Schema) (*Merge, error) {
+	var union []Column
+	index := make(map[string]int)
+	var count []int
+	m := &Merge{
+		Tables: make([][]int, len(schemas)),
+	}
+	var errs []error
+	for k, s := range schemas {
+		s = orEmpty(s)
+		n := s.ColumnsLength()
+		m.Tables[k] = make([]int, n)
+		for i := 0; i < n; i++ {
+			c := s.Column(i)
+			j, ok := index[c.Name]
+			if !ok {
+				j = len(union)
+				index[c.Name] = j
+				union = append(union, c)
+				count = append(count, 1)
+				m.Tables[k][i] = j
+				continue
+			}
+			m.Tables[k][i] = j
+			count[j]++
+			u := &union[j]
+			if t, ok := Supertype(u.Type, c.Type); ok {
+				u.Type = t
+			} else {
+				errs = append(errs, fmtErr("column %q has types %s and %s with no common supertype", c.Name, u.Type, c.Type))
+			}
+			u.Required = u.Required && c.Required
+			u.Width = maxSize(u.Width, c.Width, 0)
+			u.Precision, u.Scale = mergeDecimal(u.Precision, u.Scale, c.Precision, c.Scale)
+			if u.Title == "" {
+				u.Title = c.Title
+			}
+			if u.Description == "" {
+				u.Description = c.Description
+			}
+			if u.Metadata == "" {
+				u.Metadata = c.Metadata
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	for j := range union {
+		if count[j] < len(schemas) {
+			union[j].Required = false
+		}
+		if len(schemas) > 1 {
+			union[j].Unique = false
+			union[j].PrimaryKey = false
+		}
+	}
+	if errs = checkColumns(union); len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	m.Schema = newSchema(union)
+	m.migrators = make([]*Migrator, len(schemas))
+	for k, s := range schemas {
+		var err error
+		if m.migrators[k], err = NewMigrator(s, m.Schema, Mapping{}); err != nil {
+			return nil, fmt.Errorf("schema %d: %w", k, err)
+		}
+	}
+	return m, nil
+}
+
+// supertypeOrder lists the column types in the order in which
+// Supertype considers them as candidates, smallest first.
+var supertypeOrder = []flat.ColumnType{
+	flat.ColumnTypeBool,
+	flat.ColumnTypeByte,
+	flat.ColumnTypeUByte,
+	flat.ColumnTypeShort,
+	flat.ColumnTypeUShort,
+	flat.ColumnTypeInt,
+	flat.ColumnTypeUInt,
+	flat.ColumnTypeLong,
+	flat.ColumnTypeULong,
+	flat.ColumnTypeFloat,
+	flat.ColumnTypeDouble,
+	flat.ColumnTypeDateTime,
+	flat.ColumnTypeJson,
+	flat.ColumnTypeString,
+	flat.ColumnTypeBinary,
+}
+
+// Supertype returns the smallest column type which both a and b widen
+// to (see Widens). The return value ok is false if there is no such
+// type. For example, the supertype of Int and UShort is Int, the
+// supertype of Int and Float is Double, and the supertype of DateTime
+// and String is String.
+func Supertype(a, b flat.ColumnType) (t flat.ColumnType, ok bool) {
+	for _, t = range supertypeOrder {
+		if Widens(a, t) && Widens(b, t) {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// mergeDecimal returns the precision and scale of a union column which
+// holds every value allowed by precision pa and scale sa, and by
+// precision pb and scale sb.
+func mergeDecimal(pa, sa, pb, sb int32) (precision, scale int32) {
+	if pa <= 0 || pb <= 0 {
+		return 0, maxSize(sa, sb, -1)
+	} else if sa < 0 || sb < 0 {
+		// Without a scale, precision limits the total number of digits,
+		// however they are split around the decimal point.
+		return maxSize(pa, pb, 0), -1
+	}
+	scale = sa
+	if sb > scale {
+		scale = sb
+	}
+	intDigits := pa - sa
+	if pb-sb > intDigits {
+		intDigits = pb - sb
+	}
+	return intDigits + scale, scale
+}
+
+// maxSize returns the larger of two size limits, where any value less
+// than or equal to unset means there is no limit.
+func maxSize(a, b, unset int32) int32 {
+	if a <= unset || b <= unset {
+		return unset
+	} else if a > b {
+		return a
+	}
+	return b
+}
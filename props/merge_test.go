@@ -0,0 +1,175 @@
+package props
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+)
+
+func TestMergeSchemas(t *testing.T) {
+	s1 := NewSchema([]Column{
+		{Name: "id", Type: flat.ColumnTypeInt, PrimaryKey: true, Required: true},
+		{Name: "name", Type: flat.ColumnTypeString, Width: 10, Required: true, Title: "Name"},
+		{Name: "code", Type: flat.ColumnTypeString, Unique: true},
+	})
+	s2 := NewSchema([]Column{
+		{Name: "name", Type: flat.ColumnTypeString, Width: 20, Required: true, Description: "The name"},
+		{Name: "id", Type: flat.ColumnTypeUShort, PrimaryKey: true, Required: true},
+		{Name: "score", Type: flat.ColumnTypeFloat, Precision: 4, Scale: 1},
+	})
+	testCases := []struct {
+		name    string
+		schemas []*Schema
+		want    []Column
+		tables  [][]int
+	}{
+		{
+			name:    "one schema keeps keys",
+			schemas: []*Schema{s1},
+			want: []Column{
+				{Name: "id", Type: flat.ColumnTypeInt, PrimaryKey: true, Required: true},
+				{Name: "name", Type: flat.ColumnTypeString, Width: 10, Required: true, Title: "Name"},
+				{Name: "code", Type: flat.ColumnTypeString, Unique: true},
+			},
+			tables: [][]int{{0, 1, 2}},
+		},
+		{
+			name:    "two schemas",
+			schemas: []*Schema{s1, s2},
+			want: []Column{
+				{Name: "id", Type: flat.ColumnTypeInt, Required: true},
+				{Name: "name", Type: flat.ColumnTypeString, Width: 20, Required: true, Title: "Name", Description: "The name"},
+				{Name: "code", Type: flat.ColumnTypeString},
+				{Name: "score", Type: flat.ColumnTypeFloat, Precision: 4, Scale: 1},
+			},
+			tables: [][]int{{0, 1, 2}, {1, 0, 3}},
+		},
+		{
+			name:    "nil schema",
+			schemas: []*Schema{s2, nil},
+			want: []Column{
+				{Name: "name", Type: flat.ColumnTypeString, Width: 20, Description: "The name"},
+				{Name: "id", Type: flat.ColumnTypeUShort},
+				{Name: "score", Type: flat.ColumnTypeFloat, Precision: 4, Scale: 1},
+			},
+			tables: [][]int{{0, 1, 2}, {}},
+		},
+		{
+			name:    "size limits unset",
+			schemas: []*Schema{s1, NewSchema([]Column{{Name: "name", Type: flat.ColumnTypeString}})},
+			want: []Column{
+				{Name: "id", Type: flat.ColumnTypeInt},
+				{Name: "name", Type: flat.ColumnTypeString, Title: "Name"},
+				{Name: "code", Type: flat.ColumnTypeString},
+			},
+			tables: [][]int{{0, 1, 2}, {1}},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			m, err := MergeSchemas(testCase.schemas...)
+			if err != nil {
+				t.Fatalf("MergeSchemas: %v", err)
+			}
+			var got []Column
+			for i := 0; i < m.Schema.ColumnsLength(); i++ {
+				got = append(got, m.Schema.Column(i))
+			}
+			if !reflect.DeepEqual(got, testCase.want) {
+				t.Errorf("got columns %+v, want %+v", got, testCase.want)
+			}
+			if !reflect.DeepEqual(m.Tables, testCase.tables) {
+				t.Errorf("got tables %v, want %v", m.Tables, testCase.tables)
+			}
+		})
+	}
+}
+
+func TestMergeSchemasNoSupertype(t *testing.T) {
+	a := NewSchema([]Column{{Name: "x", Type: flat.ColumnTypeBinary}})
+	b := NewSchema([]Column{{Name: "x", Type: flat.ColumnTypeInt}})
+	if _, err := MergeSchemas(a, b); err == nil {
+		t.Fatal("MergeSchemas: got nil error for Binary and Int")
+	}
+}
+
+func TestMergeRemap(t *testing.T) {
+	s1 := NewSchema([]Column{{Name: "a", Type: flat.ColumnTypeShort}})
+	s2 := NewSchema([]Column{{Name: "b", Type: flat.ColumnTypeString}, {Name: "a", Type: flat.ColumnTypeInt}})
+	m, err := MergeSchemas(s1, s2)
+	if err != nil {
+		t.Fatalf("MergeSchemas: %v", err)
+	}
+	p := NewProps(s1)
+	if err := p.SetShort(0, -7); err != nil {
+		t.Fatalf("SetShort: %v", err)
+	}
+	q, err := m.Remap(0, p)
+	if err != nil {
+		t.Fatalf("Remap: %v", err)
+	}
+	if v, err := q.GetInt(0); err != nil || v != -7 {
+		t.Errorf("got %v, %v, want -7", v, err)
+	} else if _, err := q.GetString(1); !errors.Is(err, ErrNoValue) {
+		t.Errorf("got error %v for column missing from schema 0, want ErrNoValue", err)
+	}
+	if _, err := m.Remap(2, p); err == nil {
+		t.Error("Remap: got nil error for schema 2")
+	}
+}
+
+func TestMergeDecimal(t *testing.T) {
+	testCases := []struct {
+		name           string
+		pa, sa, pb, sb int32
+		precision      int32
+		scale          int32
+	}{
+		{"same", 5, 2, 5, 2, 5, 2},
+		{"more integer digits", 5, 2, 10, 0, 12, 2},
+		{"more fraction digits", 10, 0, 5, 2, 12, 2},
+		{"one dominates", 10, 4, 6, 2, 10, 4},
+		{"no scale", 5, -1, 10, 3, 10, -1},
+		{"no precision", 0, 2, 10, 3, 0, 3},
+		{"neither set", 0, 0, 0, 0, 0, 0},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			precision, scale := mergeDecimal(testCase.pa, testCase.sa, testCase.pb, testCase.sb)
+			if precision != testCase.precision || scale != testCase.scale {
+				t.Errorf("got precision %d and scale %d, want %d and %d", precision, scale, testCase.precision, testCase.scale)
+			}
+		})
+	}
+}
+
+func TestMergeSchemasDecimalValues(t *testing.T) {
+	a := NewSchema([]Column{{Name: "x", Type: flat.ColumnTypeDouble, Precision: 5, Scale: 2}})
+	b := NewSchema([]Column{{Name: "x", Type: flat.ColumnTypeDouble, Precision: 10, Scale: 0}})
+	m, err := MergeSchemas(a, b)
+	if err != nil {
+		t.Fatalf("MergeSchemas: %v", err)
+	}
+	if c := m.Schema.Column(0); c.Precision != 12 || c.Scale != 2 {
+		t.Fatalf("got precision %d and scale %d, want 12 and 2", c.Precision, c.Scale)
+	}
+	v := NewValidator(m.Schema)
+	// Each value is the largest allowed by one of the input schemas.
+	for _, s := range []string{"999.99", "9999999999"} {
+		p := NewProps(m.Schema)
+		r, _ := new(big.Rat).SetString(s)
+		if err := p.SetDecimal(0, r); err != nil {
+			t.Errorf("SetDecimal(%s): %v", s, err)
+		}
+		x, _ := r.Float64()
+		if err := p.SetDouble(0, x); err != nil {
+			t.Fatal(err)
+		}
+		if violations, err := v.Validate(p); err != nil || len(violations) != 0 {
+			t.Errorf("Validate(%s): got %v, %v, want no violations", s, violations, err)
+		}
+	}
+}
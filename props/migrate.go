@@ -0,0 +1,186 @@
+package props
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Mapping describes how the columns of one schema map to the columns of
+// another, for use by a Migrator.
+//
+// Columns which are neither renamed nor dropped map to the column of
+// the same name in the target schema.
+type Mapping struct {
+	// Rename maps source column names to target column names.
+	Rename map[string]string
+	// Drop lists source column names whose values are discarded.
+	Drop []string
+	// Default maps target column names to default values, which are
+	// written whenever the source has no value for the column. Each
+	// default value must have a Go type accepted by Props.SetValue
+	// for the target column type.
+	Default map[string]any
+}
+
+// Migrator converts Props under one schema into Props under another.
+//
+// A Migrator works directly on the encoded property buffer, so values
+// are copied, or converted if the column type was widened, without
+// being decoded into Go values.
+//
+// A Migrator is immutable once constructed, and is safe for concurrent
+// use by multiple goroutines.
+type Migrator struct {
+	from, to *Schema
+	// to2From maps each target column index to the source column
+	// index it takes its value from, or -1 if no source column maps to
+	// the target column.
+	to2From []int
+	// defaults contains the encoded default value for each target
+	// column, or nil if the column has no default.
+	defaults [][]byte
+}
+
+// NewMigrator creates a Migrator from schema from to schema to under a
+// mapping.
+//
+// NewMigrator returns an error if the mapping refers to columns which
+// don't exist (wrapping ErrNoColumn), if a source column which isn't
+// dropped has no target column (ErrNoColumn), if two source columns
+// map to the same target column (ErrInvalidMapping), if a source column
+// type cannot be widened to its target column type (ErrTypeMismatch;
+// see Widens), if a default value does not suit its column
+// (ErrTypeMismatch), or if a required target column has neither a
+// source column nor a default (ErrRequired). Problems with different
+// columns are joined using errors.Join.
+func NewMigrator(from, to *Schema, mapping Mapping) (*Migrator, error) {
+	from, to = orEmpty(from), orEmpty(to)
+	m := &Migrator{
+		from:     from,
+		to:       to,
+		to2From:  make([]int, to.ColumnsLength()),
+		defaults: make([][]byte, to.ColumnsLength()),
+	}
+	for j := range m.to2From {
+		m.to2From[j] = -1
+	}
+	var errs []error
+	for name := range mapping.Rename {
+		if _, ok := from.Index(name); !ok {
+			errs = append(errs, fmt.Errorf("%w: rename of unknown source column %q", ErrNoColumn, name))
+		}
+	}
+	drop := make(map[string]bool, len(mapping.Drop))
+	for _, name := range mapping.Drop {
+		if _, ok := from.Index(name); !ok {
+			errs = append(errs, fmt.Errorf("%w: drop of unknown source column %q", ErrNoColumn, name))
+		}
+		drop[name] = true
+	}
+	for i := 0; i < from.ColumnsLength(); i++ {
+		src := from.Column(i)
+		if drop[src.Name] {
+			continue
+		}
+		name := src.Name
+		if rename, ok := mapping.Rename[name]; ok {
+			name = rename
+		}
+		j, ok := to.Index(name)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%w: source column %q has no target column %q: rename or drop it", ErrNoColumn, src.Name, name))
+			continue
+		} else if m.to2From[j] >= 0 {
+			errs = append(errs, fmt.Errorf("%w: source columns %q and %q both map to target column %q", ErrInvalidMapping, from.Name(m.to2From[j]), src.Name, name))
+			continue
+		} else if !Widens(src.Type, to.Type(j)) {
+			errs = append(errs, &TypeError{
+				Feature:  -1,
+				Col:      j,
+				Name:     name,
+				Expected: to.Type(j),
+				Actual:   src.Type,
+				Cause:    fmt.Errorf("source column %q type cannot be widened to target column type", src.Name),
+			})
+			continue
+		}
+		m.to2From[j] = i
+	}
+	for name, value := range mapping.Default {
+		j, ok := to.Index(name)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%w: default for unknown target column %q", ErrNoColumn, name))
+			continue
+		}
+		p := NewProps(to)
+		if err := p.SetValue(j, value); err != nil {
+			errs = append(errs, fmt.Errorf("default for target column %q: %w", name, err))
+			continue
+		} else if raw, _ := p.rawValue(j); raw != nil {
+			m.defaults[j] = raw
+		}
+	}
+	for j := range m.to2From {
+		if m.to2From[j] < 0 && m.defaults[j] == nil && to.Column(j).Required {
+			errs = append(errs, fmt.Errorf("%w: required target column %q has no source column or default", ErrRequired, to.Name(j)))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return m, nil
+}
+
+// Migrate converts p, whose schema must have the same columns as the
+// source schema of the Migrator, into a new Props under the target
+// schema. It returns an error wrapping ErrSchemaMismatch if p has a
+// different number of columns, and a *TypeError if a column of p has
+// a different type. The new Props inherits the options of p and has its values
+// stored in ascending column order.
+//
+// Explicit nulls in p are preserved. Target columns with no value in p
+// take their default value, if the mapping gave them one.
+func (m *Migrator) Migrate(p *Props) (*Props, error) {
+	if p.numColumns() != m.from.ColumnsLength() {
+		return nil, fmt.Errorf("%w: props has %d columns but migrator source schema has %d", ErrSchemaMismatch, p.numColumns(), m.from.ColumnsLength())
+	}
+	q := &Props{
+		flatSchema: m.to,
+		fastSchema: m.to,
+		mutable:    true,
+		offset:     make([]int, len(m.to2From)),
+		opts:       p.opts,
+		feature:    p.feature,
+	}
+	q.data.Grow(p.data.Len())
+	for j, i := range m.to2From {
+		if i >= 0 {
+			raw, err := p.rawValue(i)
+			if err != nil {
+				return nil, err
+			} else if raw != nil {
+				fromType, toType := m.from.Type(i), m.to.Type(j)
+				if p.columnType(i) != fromType {
+					return nil, &TypeError{Feature: p.feature, Col: i, Name: p.columnName(i), Expected: fromType, Actual: p.columnType(i)}
+				}
+				b, err := q.extend(j, convertedSize(fromType, toType, raw))
+				if err != nil {
+					return nil, err
+				}
+				convertRaw(b, fromType, toType, raw)
+				continue
+			} else if p.IsNull(i) {
+				q.offset[j] = nullOffset
+				continue
+			}
+		}
+		if def := m.defaults[j]; def != nil {
+			b, err := q.extend(j, len(def))
+			if err != nil {
+				return nil, err
+			}
+			copy(b, def)
+		}
+	}
+	return q, nil
+}
@@ -0,0 +1,262 @@
+package props
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+)
+
+// widenSources gives, for each column type which widens to another, a
+// value of that type and the number it represents, if it is numeric.
+var widenSources = map[flat.ColumnType]struct {
+	value any
+	n     float64
+}{
+	flat.ColumnTypeByte:     {int8(-7), -7},
+	flat.ColumnTypeUByte:    {uint8(200), 200},
+	flat.ColumnTypeShort:    {int16(-300), -300},
+	flat.ColumnTypeUShort:   {uint16(60000), 60000},
+	flat.ColumnTypeInt:      {int32(-70000), -70000},
+	flat.ColumnTypeUInt:     {uint32(4000000000), 4000000000},
+	flat.ColumnTypeFloat:    {float32(1.5), 1.5},
+	flat.ColumnTypeDateTime: {"2024-01-02T03:04:05Z", 0},
+	flat.ColumnTypeJson:     {`{"a":[1,2]}`, 0},
+}
+
+// numberAs returns n as the Go type GetValue returns for column type t.
+func numberAs(t flat.ColumnType, n float64) any {
+	switch t {
+	case flat.ColumnTypeShort:
+		return int16(n)
+	case flat.ColumnTypeUShort:
+		return uint16(n)
+	case flat.ColumnTypeInt:
+		return int32(n)
+	case flat.ColumnTypeUInt:
+		return uint32(n)
+	case flat.ColumnTypeLong:
+		return int64(n)
+	case flat.ColumnTypeULong:
+		return uint64(n)
+	case flat.ColumnTypeFloat:
+		return float32(n)
+	default:
+		return n
+	}
+}
+
+func TestMigrateWidening(t *testing.T) {
+	var pairs int
+	for from, src := range widenSources {
+		for _, to := range supertypeOrder {
+			if from == to || !Widens(from, to) {
+				continue
+			}
+			pairs++
+			t.Run(from.String()+" to "+to.String(), func(t *testing.T) {
+				fromSchema := NewSchema([]Column{{Name: "x", Type: from}})
+				toSchema := NewSchema([]Column{{Name: "x", Type: to}})
+				m, err := NewMigrator(fromSchema, toSchema, Mapping{})
+				if err != nil {
+					t.Fatalf("NewMigrator: %v", err)
+				}
+				p := NewProps(fromSchema)
+				var want any
+				if s, ok := src.value.(string); ok {
+					if from == flat.ColumnTypeJson {
+						err = p.SetJSON(0, s)
+					} else {
+						err = p.SetDateTimeString(0, s)
+					}
+					want = s
+				} else {
+					err = p.SetValue(0, src.value)
+					want = numberAs(to, src.n)
+				}
+				if err != nil {
+					t.Fatalf("set: %v", err)
+				}
+				q, err := m.Migrate(p)
+				if err != nil {
+					t.Fatalf("Migrate: %v", err)
+				}
+				if got, err := q.GetValue(0); err != nil || got != want {
+					t.Errorf("got %v (%T), %v, want %v (%T)", got, got, err, want, want)
+				}
+				if _, err := q.Bytes(); err != nil {
+					t.Errorf("Bytes: %v", err)
+				}
+			})
+		}
+	}
+	if pairs < 30 {
+		t.Errorf("tested only %d widening pairs", pairs)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	from := NewSchema([]Column{
+		{Name: "id", Type: flat.ColumnTypeInt},
+		{Name: "old_name", Type: flat.ColumnTypeString},
+		{Name: "junk", Type: flat.ColumnTypeBinary},
+		{Name: "note", Type: flat.ColumnTypeString},
+		{Name: "count", Type: flat.ColumnTypeShort},
+	})
+	to := NewSchema([]Column{
+		{Name: "count", Type: flat.ColumnTypeLong, Required: true},
+		{Name: "id", Type: flat.ColumnTypeLong, Required: true},
+		{Name: "name", Type: flat.ColumnTypeString},
+		{Name: "note", Type: flat.ColumnTypeString},
+		{Name: "status", Type: flat.ColumnTypeString, Required: true},
+	})
+	m, err := NewMigrator(from, to, Mapping{
+		Rename:  map[string]string{"old_name": "name"},
+		Drop:    []string{"junk"},
+		Default: map[string]any{"status": "new", "count": int64(-1)},
+	})
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	testCases := []struct {
+		name string
+		set  func(p *Props) error
+		want []any
+		null []bool
+	}{
+		{
+			name: "every value",
+			set: func(p *Props) error {
+				return errors.Join(p.SetInt(0, 7), p.SetString(1, "a"), p.SetBinary(2, []byte{1}), p.SetString(3, "n"), p.SetShort(4, 3))
+			},
+			want: []any{int64(3), int64(7), "a", "n", "new"},
+		},
+		{
+			name: "defaults",
+			set:  func(p *Props) error { return p.SetInt(0, 8) },
+			want: []any{int64(-1), int64(8), nil, nil, "new"},
+		},
+		{
+			name: "nulls kept",
+			set: func(p *Props) error {
+				return errors.Join(p.SetInt(0, 9), p.SetNull(1), p.SetNull(3))
+			},
+			want: []any{int64(-1), int64(9), nil, nil, "new"},
+			null: []bool{false, false, true, true, false},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			p := NewProps(from)
+			p.SetFeature(4)
+			if err := testCase.set(p); err != nil {
+				t.Fatal(err)
+			}
+			q, err := m.Migrate(p)
+			if err != nil {
+				t.Fatalf("Migrate: %v", err)
+			}
+			for col, want := range testCase.want {
+				got, err := q.GetValue(col)
+				if testCase.null != nil && testCase.null[col] {
+					if want := (Null{Type: q.Schema().Type(col)}); got != want || err != nil {
+						t.Errorf("column %d: got %v, %v, want null", col, got, err)
+					}
+				} else if want == nil {
+					if err == nil {
+						t.Errorf("column %d: got %v, want no value", col, got)
+					}
+				} else if err != nil || !reflect.DeepEqual(got, want) {
+					t.Errorf("column %d: got %v, %v, want %v", col, got, err, want)
+				}
+				if null := testCase.null != nil && testCase.null[col]; q.IsNull(col) != null {
+					t.Errorf("column %d: got IsNull %t, want %t", col, q.IsNull(col), null)
+				}
+			}
+			if q.feature != 4 {
+				t.Errorf("got feature %d, want 4", q.feature)
+			}
+		})
+	}
+	if _, err := m.Migrate(NewProps(NewSchema([]Column{{Name: "id", Type: flat.ColumnTypeInt}}))); !errors.Is(err, ErrSchemaMismatch) {
+		t.Errorf("Migrate: got %v for props under the wrong schema, want ErrSchemaMismatch", err)
+	}
+}
+
+func TestNewMigratorErrors(t *testing.T) {
+	from := NewSchema([]Column{
+		{Name: "a", Type: flat.ColumnTypeLong},
+		{Name: "b", Type: flat.ColumnTypeString},
+	})
+	testCases := []struct {
+		name    string
+		to      []Column
+		mapping Mapping
+		want    string
+		is      error
+	}{
+		{
+			name: "incompatible types",
+			to:   []Column{{Name: "a", Type: flat.ColumnTypeInt}, {Name: "b", Type: flat.ColumnTypeString}},
+			want: "cannot be widened",
+			is:   ErrTypeMismatch,
+		},
+		{
+			name: "missing required column",
+			to:   []Column{{Name: "a", Type: flat.ColumnTypeLong}, {Name: "b", Type: flat.ColumnTypeString}, {Name: "c", Type: flat.ColumnTypeInt, Required: true}},
+			want: "required target column",
+			is:   ErrRequired,
+		},
+		{
+			name: "no target column",
+			to:   []Column{{Name: "a", Type: flat.ColumnTypeLong}},
+			want: "has no target column",
+			is:   ErrNoColumn,
+		},
+		{
+			name:    "two sources for one target",
+			to:      []Column{{Name: "a", Type: flat.ColumnTypeLong}},
+			mapping: Mapping{Rename: map[string]string{"b": "a"}},
+			want:    "both map to target column",
+			is:      ErrInvalidMapping,
+		},
+		{
+			name:    "unknown rename",
+			to:      []Column{{Name: "a", Type: flat.ColumnTypeLong}, {Name: "b", Type: flat.ColumnTypeString}},
+			mapping: Mapping{Rename: map[string]string{"z": "a"}},
+			want:    "rename of unknown source column",
+			is:      ErrNoColumn,
+		},
+		{
+			name:    "unknown drop",
+			to:      []Column{{Name: "a", Type: flat.ColumnTypeLong}, {Name: "b", Type: flat.ColumnTypeString}},
+			mapping: Mapping{Drop: []string{"z"}},
+			want:    "drop of unknown source column",
+			is:      ErrNoColumn,
+		},
+		{
+			name:    "unknown default",
+			to:      []Column{{Name: "a", Type: flat.ColumnTypeLong}, {Name: "b", Type: flat.ColumnTypeString}},
+			mapping: Mapping{Default: map[string]any{"z": int64(1)}},
+			want:    "default for unknown target column",
+			is:      ErrNoColumn,
+		},
+		{
+			name:    "default of wrong type",
+			to:      []Column{{Name: "a", Type: flat.ColumnTypeLong}, {Name: "b", Type: flat.ColumnTypeString}, {Name: "c", Type: flat.ColumnTypeInt}},
+			mapping: Mapping{Default: map[string]any{"c": "x"}},
+			want:    "default for target column",
+			is:      ErrTypeMismatch,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := NewMigrator(from, NewSchema(testCase.to), testCase.mapping)
+			if !errors.Is(err, testCase.is) || !strings.Contains(err.Error(), testCase.want) {
+				t.Errorf("got error %v, want %v containing %q", err, testCase.is, testCase.want)
+			}
+		})
+	}
+}
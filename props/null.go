@@ -0,0 +1,23 @@
+package props
+
+import "github.com/gogama/flatgeobuf/flatgeobuf/flat"
+
+// nullOffset is the value offset recorded for a column which has been
+// explicitly set to null.
+const nullOffset = -1
+
+// Null is the value returned by Props.GetValue for a column which has
+// been explicitly set to null. Type is the type of the null column, so
+// that a Null can be converted to a typed SQL NULL without consulting
+// the schema.
+//
+// Null values may also be passed to Props.SetValue to set a column to
+// null.
+type Null struct {
+	Type flat.ColumnType
+}
+
+// String returns "null".
+func (n Null) String() string {
+	return "null"
+}
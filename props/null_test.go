@@ -0,0 +1,220 @@
+package props
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+)
+
+// nullColumns has an optional column on either side of a required one.
+var nullColumns = []Column{
+	{Name: "a", Type: flat.ColumnTypeInt},
+	{Name: "b", Type: flat.ColumnTypeString, Required: true},
+	{Name: "c", Type: flat.ColumnTypeInt},
+}
+
+var nullSchema = NewSchema(nullColumns)
+
+func TestNullString(t *testing.T) {
+	if s := (Null{Type: flat.ColumnTypeInt}).String(); s != "null" {
+		t.Errorf("got %q, want %q", s, "null")
+	}
+}
+
+func TestSetNull(t *testing.T) {
+	data := bytesJoin(intEntry(0, 1), stringEntry(1, 1, "x"), intEntry(2, 3))
+	input := append([]byte(nil), data...)
+	p := PropsFromFlat(nullSchema, input)
+
+	if p.IsNull(0) || p.IsNullName("a") {
+		t.Fatal("column with a value is null")
+	}
+	if err := p.SetNull(0); err != nil {
+		t.Fatalf("SetNull: %v", err)
+	}
+	if !bytes.Equal(input, data) {
+		t.Error("SetNull modified the buffer passed to PropsFromFlat")
+	}
+	if !p.IsNull(0) || !p.IsNullName("a") {
+		t.Error("IsNull: got false, want true")
+	}
+	if p.Has(0) || p.HasName("a") {
+		t.Error("Has: got true for null column, want false")
+	}
+	if _, err := p.GetInt(0); !errors.Is(err, ErrNull) {
+		t.Errorf("GetInt: got %v, want ErrNull", err)
+	}
+	if v, err := p.GetValue(0); err != nil || v != (Null{Type: flat.ColumnTypeInt}) {
+		t.Errorf("GetValue: got %v, %v, want Null{Int}", v, err)
+	}
+	if v, err := p.GetInt(2); err != nil || v != 3 {
+		t.Errorf("column after null: got %v, %v, want 3", v, err)
+	}
+	if s, err := p.GetString(1); err != nil || s != "x" {
+		t.Errorf("column after null: got %q, %v, want %q", s, err, "x")
+	}
+	// The property format represents null by omission.
+	if got, want := mustBytes(t, p), bytesJoin(stringEntry(1, 1, "x"), intEntry(2, 3)); !bytes.Equal(got, want) {
+		t.Errorf("Bytes: got %v, want %v", got, want)
+	}
+
+	if !p.Delete(0) {
+		t.Error("Delete of null column: got false, want true")
+	}
+	if p.IsNull(0) || p.Has(0) {
+		t.Error("deleted column is still null or has a value")
+	}
+	if _, err := p.GetInt(0); !errors.Is(err, ErrNoValue) {
+		t.Errorf("GetInt after Delete: got %v, want ErrNoValue", err)
+	}
+	if p.Delete(0) {
+		t.Error("second Delete: got true, want false")
+	}
+
+	if err := p.SetNullName("c"); err != nil || !p.IsNull(2) {
+		t.Errorf("SetNullName: got %v, IsNull %v", err, p.IsNull(2))
+	}
+	if err := p.SetInt(2, 4); err != nil {
+		t.Fatal(err)
+	}
+	if p.IsNull(2) {
+		t.Error("column is still null after SetInt")
+	}
+	if err := p.SetValue(2, Null{Type: flat.ColumnTypeInt}); err != nil || !p.IsNull(2) {
+		t.Errorf("SetValue(Null): got %v, IsNull %v", err, p.IsNull(2))
+	}
+	if err := p.SetInt(2, 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetValue(2, nil); err != nil || !p.IsNull(2) {
+		t.Errorf("SetValue(nil): got %v, IsNull %v", err, p.IsNull(2))
+	}
+
+	if err := p.SetNullName("missing"); !errors.Is(err, ErrNoColumn) {
+		t.Errorf("missing name: got %v, want ErrNoColumn", err)
+	}
+	if err := p.SetNull(3); !errors.Is(err, ErrNoColumn) {
+		t.Errorf("out of range column: got %v, want ErrNoColumn", err)
+	}
+	if p.IsNull(3) || p.IsNullName("missing") {
+		t.Error("IsNull: got true for missing column, want false")
+	}
+}
+
+func TestSetNullAbsent(t *testing.T) {
+	p := NewProps(nullSchema)
+	if p.IsNull(0) {
+		t.Error("absent column is null")
+	}
+	if err := p.SetNull(0); err != nil {
+		t.Fatal(err)
+	}
+	if !p.IsNull(0) {
+		t.Error("IsNull: got false, want true")
+	}
+	if got := mustBytes(t, p); len(got) != 0 {
+		t.Errorf("Bytes: got %v, want empty", got)
+	}
+}
+
+func TestSetNullRequired(t *testing.T) {
+	for _, testCase := range []struct {
+		name   string
+		schema func(t *testing.T) *Props
+	}{
+		{"Schema", func(t *testing.T) *Props { return NewProps(nullSchema) }},
+		{"flat", func(t *testing.T) *Props { return PropsFromFlat(flatSchema(t, nullColumns...), nil) }},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			p := testCase.schema(t)
+			if err := p.SetString(1, "x"); err != nil {
+				t.Fatal(err)
+			}
+			err := p.SetNull(1)
+			var colErr *ColumnError
+			if !errors.Is(err, ErrRequired) || !errors.As(err, &colErr) || colErr.Col != 1 || colErr.Name != "b" {
+				t.Fatalf("got %v, want ColumnError for column 1 wrapping ErrRequired", err)
+			}
+			if s, err := p.GetString(1); err != nil || s != "x" {
+				t.Errorf("value after failed SetNull: got %q, %v, want %q", s, err, "x")
+			}
+			if err = p.SetValue(1, nil); !errors.Is(err, ErrRequired) {
+				t.Errorf("SetValue(nil): got %v, want ErrRequired", err)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	columns := []Column{
+		{Name: "a", Type: flat.ColumnTypeInt, Required: true},
+		{Name: "b", Type: flat.ColumnTypeInt},
+		{Name: "c", Type: flat.ColumnTypeString, Required: true},
+	}
+	schema := NewSchema(columns)
+	for _, testCase := range []struct {
+		name string
+		set  func(p *Props) error
+		cols []int
+	}{
+		{
+			name: "all present",
+			set: func(p *Props) error {
+				return errors.Join(p.SetInt(0, 1), p.SetString(2, "x"))
+			},
+		},
+		{
+			name: "optional absent or null",
+			set: func(p *Props) error {
+				return errors.Join(p.SetInt(0, 1), p.SetNull(1), p.SetString(2, ""))
+			},
+		},
+		{
+			name: "one absent",
+			set:  func(p *Props) error { return p.SetString(2, "x") },
+			cols: []int{0},
+		},
+		{
+			name: "all absent",
+			set:  func(p *Props) error { return p.SetInt(1, 1) },
+			cols: []int{0, 2},
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			for _, kind := range []string{"Schema", "flat"} {
+				var p *Props
+				if kind == "Schema" {
+					p = NewProps(schema)
+				} else {
+					p = PropsFromFlat(flatSchema(t, columns...), nil)
+				}
+				if err := testCase.set(p); err != nil {
+					t.Fatal(err)
+				}
+				err := p.Validate()
+				if len(testCase.cols) == 0 {
+					if err != nil {
+						t.Errorf("%s: got %v, want nil", kind, err)
+					}
+					continue
+				}
+				joined, ok := err.(interface{ Unwrap() []error })
+				if !ok || !errors.Is(err, ErrRequired) {
+					t.Fatalf("%s: got %v, want joined errors wrapping ErrRequired", kind, err)
+				}
+				errs := joined.Unwrap()
+				if len(errs) != len(testCase.cols) {
+					t.Fatalf("%s: got %d errors, want %d: %v", kind, len(errs), len(testCase.cols), err)
+				}
+				for i, col := range testCase.cols {
+					var colErr *ColumnError
+					if !errors.As(errs[i], &colErr) || colErr.Col != col || !errors.Is(colErr, ErrRequired) {
+						t.Errorf("%s: error %d: got %v, want ColumnError for column %d", kind, i, errs[i], col)
+					}
+				}
+			}
+		})
+	}
+}
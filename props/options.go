@@ -0,0 +1,161 @@
+package props
+
+// An Option configures optional behavior of a Props. Options are
+// passed to NewProps and PropsFromFlat.
+//
+// Props derived from another Props, for example by a Migrator, inherit
+// the options of the original.
+type Option func(*options)
+
+// options holds the optional behaviors of a Props.
+type options struct {
+	// dateTimeLayout is the time layout used to format time.Time
+	// values written to date/time columns which do not specify their
+	// own layout in the column metadata. The empty string means the
+	// default layout, time.RFC3339Nano.
+	dateTimeLayout string
+	// insertionOrder indicates that serialized property buffers keep
+	// values in storage order rather than ascending column order.
+	insertionOrder bool
+	// coercion is the rule for converting loosely typed values to
+	// column types in FromMap and UnmarshalJSON.
+	coercion Coercion
+	// unknownKeys is the treatment of keys which are not column names
+	// in FromMap and UnmarshalJSON.
+	unknownKeys UnknownKeys
+	// dateTimeStrings indicates that ToMap returns date/time values as
+	// their stored text.
+	dateTimeStrings bool
+	// strictJSON indicates that SetJSON rejects invalid JSON text.
+	strictJSON bool
+	// parseMode is the treatment of problems found when parsing a
+	// property buffer.
+	parseMode ParseMode
+}
+
+// WithDateTimeLayout sets the time layout, in the format understood by
+// time.Time.Format, used when writing time.Time values to date/time
+// columns.
+//
+// The layout applies to every date/time column except those whose
+// column metadata specifies their own layout (see
+// DateTimeLayoutFromMetadata). If this option is not given, the
+// default layout is time.RFC3339Nano, which preserves sub-second
+// precision.
+func WithDateTimeLayout(layout string) Option {
+	return func(o *options) {
+		o.dateTimeLayout = layout
+	}
+}
+
+// WithInsertionOrder makes Props.Bytes, Props.AppendBytes, and
+// Props.ToBuilder write values in the order they are stored in the
+// property buffer, which is the order they were first set or, for
+// properties read from a FlatGeobuf file, the order they were in the
+// file. Without this option, values are written in ascending column
+// order.
+func WithInsertionOrder() Option {
+	return func(o *options) {
+		o.insertionOrder = true
+	}
+}
+
+// Coercion controls how FromMap and UnmarshalJSON convert values whose
+// Go type does not match the column type.
+type Coercion int
+
+const (
+	// CoerceLoose converts between numbers, strings, and bools wherever
+	// the conversion is unambiguous, for example the string "5" or the
+	// number 5.0 to an Int column, or the number 5 to a String column.
+	// See FromMap for the full rules. This is the default.
+	CoerceLoose Coercion = iota
+	// CoerceNumeric converts numbers between numeric column types, as
+	// long as the value is in range and, for integer column types,
+	// integral. Strings are not parsed as numbers or bools, and numbers
+	// and bools are not converted to strings. Strings are still
+	// accepted by JSON, Binary, and DateTime columns.
+	CoerceNumeric
+	// CoerceNone does not convert values: each value must have the Go
+	// type which Props.SetValue accepts for the column type. Since JSON
+	// numbers are not decoded to a specific Go type, CoerceNone is
+	// mainly useful with FromMap.
+	CoerceNone
+)
+
+// WithCoercion sets the rule used by FromMap and UnmarshalJSON to
+// convert values to column types. The default is CoerceLoose.
+func WithCoercion(c Coercion) Option {
+	return func(o *options) {
+		o.coercion = c
+	}
+}
+
+// UnknownKeys controls how FromMap and UnmarshalJSON treat keys which
+// are not column names.
+type UnknownKeys int
+
+const (
+	// UnknownKeysError makes an unknown key an error wrapping
+	// ErrNoColumn. This is the default.
+	UnknownKeysError UnknownKeys = iota
+	// UnknownKeysIgnore silently discards unknown keys.
+	UnknownKeysIgnore
+	// UnknownKeysCollect keeps unknown keys and their values alongside
+	// the property values, where they can be read with Props.Unknown.
+	// Props.ToMap and Props.MarshalJSON write them back out.
+	UnknownKeysCollect
+)
+
+// WithUnknownKeys sets the treatment of keys which are not column names
+// by FromMap and UnmarshalJSON. The default is UnknownKeysError.
+func WithUnknownKeys(u UnknownKeys) Option {
+	return func(o *options) {
+		o.unknownKeys = u
+	}
+}
+
+// WithDateTimeStrings makes Props.ToMap return the values of date/time
+// columns as their stored text, instead of as time.Time values.
+func WithDateTimeStrings() Option {
+	return func(o *options) {
+		o.dateTimeStrings = true
+	}
+}
+
+// WithStrictJSON makes Props.SetJSON check that its value is valid JSON
+// before storing it, so that JSON columns are always well-formed for
+// downstream consumers such as GDAL. Without this option, SetJSON stores
+// any string. Values set by other means, such as Props.SetJSONValue,
+// FromMap, and UnmarshalJSON, are always valid JSON.
+func WithStrictJSON() Option {
+	return func(o *options) {
+		o.strictJSON = true
+	}
+}
+
+// ParseMode controls how a Props treats problems found when parsing a
+// property buffer read from a FlatGeobuf file, such as truncated
+// values, out of range column indexes, and duplicate entries for the
+// same column.
+type ParseMode int
+
+const (
+	// ParseLenient keeps the values parsed before a problem. Each
+	// problem is recorded as a ParseError which can be read with
+	// Props.Warnings. Parsing stops at the first problem which makes
+	// the rest of the buffer unreadable. Where a column has duplicate
+	// entries, the last one wins. This is the default.
+	ParseLenient ParseMode = iota
+	// ParseStrict makes every method which reads values return a
+	// ParseError describing the first problem found.
+	ParseStrict
+)
+
+// WithParseMode sets the treatment of problems found when parsing a
+// property buffer. The default is ParseLenient.
+func WithParseMode(m ParseMode) Option {
+	return func(o *options) {
+		o.parseMode = m
+	}
+}
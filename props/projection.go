@@ -0,0 +1,202 @@
+package props
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/gogama/flatgeobuf-convert/interop"
+	"github.com/gogama/flatgeobuf/flatgeobuf"
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// Projection selects a subset of the columns of a schema, for reading
+// or writing only those columns of each feature.
+//
+// A Projection works directly on FlatGeobuf property buffers. Values of
+// unselected columns are skipped over without being decoded, and the
+// projected values are copied, with their column indexes renumbered,
+// into a property buffer under the projected schema. So reading 4
+// columns out of 150 costs a single pass over the property buffer and
+// an offset table of 4 entries, instead of an offset table of 150.
+//
+// A Projection is immutable once constructed, and is safe for
+// concurrent use by multiple goroutines.
+type Projection struct {
+	// schema is the projected schema.
+	schema *Schema
+	// cols maps each projected column index to the source column
+	// index.
+	cols []int
+	// source2Col maps each source column index to the projected
+	// column index, or -1 if the source column isn't selected.
+	source2Col []int
+	// types contains the type of each source column.
+	types []flat.ColumnType
+}
+
+// NewProjection creates a Projection which selects the named columns of
+// schema. The projected schema contains copies of the named columns, in
+// the order given, so column i of the projected schema is names[i].
+//
+// NewProjection returns an error wrapping ErrNoColumn if a name is not
+// a column of schema, and an error if a name is given more than once.
+func NewProjection(schema flatgeobuf.Schema, names ...string) (*Projection, error) {
+	schema = flatOrEmpty(schema)
+	var cols []Column
+	pr := &Projection{
+		cols: make([]int, len(names)),
+	}
+	err := interop.FlatBufferSafe(func() error {
+		n := schema.ColumnsLength()
+		pr.source2Col = make([]int, n)
+		pr.types = make([]flat.ColumnType, n)
+		index := make(map[string]int, n)
+		var obj flat.Column
+		for i := 0; i < n; i++ {
+			if !schema.Columns(&obj, i) {
+				return fmtErr("missing column %d of %d", i, n)
+			}
+			pr.source2Col[i] = -1
+			pr.types[i] = obj.Type()
+			name := string(obj.Name())
+			if _, ok := index[name]; !ok {
+				index[name] = i
+			}
+		}
+		cols = make([]Column, len(names))
+		for j, name := range names {
+			i, ok := index[name]
+			if !ok {
+				return &ColumnError{Feature: -1, Col: -1, Name: name, Err: ErrNoColumn}
+			} else if pr.source2Col[i] >= 0 {
+				return fmtErr("column %q projected more than once", name)
+			}
+			pr.cols[j] = i
+			pr.source2Col[i] = j
+			schema.Columns(&obj, i)
+			var err error
+			if cols[j], err = ColumnFromFlat(&obj); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	pr.schema = newSchema(cols)
+	return pr, nil
+}
+
+// Schema returns the projected schema.
+func (pr *Projection) Schema() *Schema {
+	return pr.schema
+}
+
+// Source returns the index, within the source schema, of column col of
+// the projected schema.
+func (pr *Projection) Source(col int) int {
+	return pr.cols[col]
+}
+
+// Project resets dst to hold the projected values of the property
+// buffer data, which must be under the source schema of the Projection,
+// for example the properties of a feature read from a FlatGeobuf file.
+// The schema of dst becomes the projected schema, and its options are
+// kept.
+//
+// Unlike Reset, Project copies the projected values into storage owned
+// by dst, so data may be modified after Project returns. As with Reset,
+// the storage is reused across calls, so a loop which projects every
+// feature into the same Props reaches zero steady-state allocations.
+//
+// Problems found in data are treated according to the parse mode of
+// dst, as if dst had parsed data itself. In ParseStrict mode, Project
+// returns a ParseError describing the first problem, and dst holds no
+// values. In ParseLenient mode, dst keeps the values before the first
+// problem which makes the rest of data unreadable, where a column has
+// duplicate entries the last one wins, and the problems are available
+// from the Warnings method of dst. Duplicate entries for columns which
+// aren't selected are skipped over without being detected.
+func (pr *Projection) Project(dst *Props, data []byte) error {
+	dst.Reset(pr.schema, nil)
+	b := dst.spareData[:0]
+	dst.spareData = nil
+	offset := dst.newOffset(len(pr.cols))
+	b, err := pr.scan(b, data, offset, dst.problem)
+	dst.data = *bytes.NewBuffer(b)
+	dst.offset = offset
+	dst.mutable = true
+	if err != nil {
+		dst.data.Reset()
+		for col := range offset {
+			offset[col] = 0
+		}
+	}
+	return err
+}
+
+// AppendBytes appends the projected values of the property buffer data,
+// which must be under the source schema of the Projection, to dst and
+// returns the extended buffer. The result is a property buffer under
+// the projected schema, suitable for writing a FlatGeobuf file which
+// only has the projected columns. Values keep the order they have in
+// data.
+//
+// AppendBytes treats data as in ParseStrict mode: it returns a
+// ParseError if data is corrupt or has duplicate entries for a selected
+// column. To project a buffer leniently, use Project with a Props in
+// ParseLenient mode and call its Bytes method.
+func (pr *Projection) AppendBytes(dst, data []byte) ([]byte, error) {
+	offset := make([]int, len(pr.cols))
+	return pr.scan(dst, data, offset, func(*ParseError) bool { return false })
+}
+
+// scan appends the projected values of data to dst. On entry, offset
+// must hold a zero for each projected column. On return, it holds the
+// offset within dst of each projected value appended, or zero if the
+// column has no value.
+//
+// Problems found in data are passed to problem, which reports whether
+// scanning may continue, in the same way as Props.problem. If it
+// returns false, scan returns the problem as an error. Where a selected
+// column has duplicate entries, only the last one is appended.
+func (pr *Projection) scan(dst, data []byte, offset []int, problem func(*ParseError) bool) ([]byte, error) {
+	typeOf := func(i int) flat.ColumnType { return pr.types[i] }
+	// The first pass checks data and records in offset the position of
+	// the entry for each projected column which wins.
+	end := 0
+	for end < len(data) {
+		i, valueOffset, size, err := nextEntry(data, end, len(pr.types), typeOf)
+		if err != nil {
+			if !problem(err) {
+				return dst, err
+			}
+			break
+		}
+		if j := pr.source2Col[i]; j >= 0 {
+			if first := offset[j]; first > 0 {
+				dup := &ParseError{Feature: -1, Offset: end, Col: i, Reason: fmt.Sprintf("duplicate entry for column, first at byte %d", first-flatbuffers.SizeUint16)}
+				if !problem(dup) {
+					return dst, dup
+				}
+			}
+			offset[j] = valueOffset
+		}
+		end = valueOffset + size
+	}
+	// The second pass appends the winning entries, which are known to
+	// be readable, and replaces their positions in data by their
+	// positions in dst.
+	for i := 0; i < end; {
+		k, valueOffset, size, _ := nextEntry(data, i, len(pr.types), typeOf)
+		if j := pr.source2Col[k]; j >= 0 && offset[j] == valueOffset {
+			dst = appendHeader(dst, j)
+			offset[j] = len(dst)
+			dst = append(dst, data[valueOffset:valueOffset+size]...)
+		}
+		i = valueOffset + size
+	}
+	return dst, nil
+}
@@ -0,0 +1,88 @@
+package props
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestProjection(t *testing.T) {
+	pr, err := NewProjection(parseSchema, "c", "a")
+	if err != nil {
+		t.Fatalf("NewProjection: %v", err)
+	}
+	// want maps each projected column to its source column.
+	want := []int{2, 0}
+	for _, testCase := range parseBuffers {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Run("lenient", func(t *testing.T) {
+				dst := NewProps(nil)
+				if err := pr.Project(dst, testCase.data); err != nil {
+					t.Fatalf("Project: %v", err)
+				}
+				for col, source := range want {
+					got, err := dst.GetValue(col)
+					if v, ok := testCase.values[source]; !ok {
+						if !errors.Is(err, ErrNoValue) {
+							t.Errorf("column %d: got %v, %v, want ErrNoValue", col, got, err)
+						}
+					} else if err != nil || got != v {
+						t.Errorf("column %d: got %v, %v, want %v", col, got, err, v)
+					}
+				}
+				if (len(dst.Warnings()) > 0) != (testCase.problem >= 0) {
+					t.Errorf("got warnings %v, want problem at byte %d", dst.Warnings(), testCase.problem)
+				}
+				// The projected buffer must parse cleanly, with each
+				// column stored once.
+				b := mustBytes(t, dst)
+				q := NewProps(pr.Schema(), WithParseMode(ParseStrict))
+				q.Reset(pr.Schema(), b)
+				if err := q.Parse(); err != nil {
+					t.Errorf("projected buffer %x: %v", b, err)
+				}
+			})
+			t.Run("strict", func(t *testing.T) {
+				dst := NewProps(nil, WithParseMode(ParseStrict))
+				err := pr.Project(dst, testCase.data)
+				if testCase.problem < 0 {
+					if err != nil {
+						t.Fatalf("Project: %v", err)
+					}
+					return
+				}
+				var parseErr *ParseError
+				if !errors.As(err, &parseErr) || parseErr.Offset != testCase.problem {
+					t.Fatalf("got error %v, want *ParseError at byte %d", err, testCase.problem)
+				} else if dst.data.Len() != 0 {
+					t.Errorf("got values after error, want none")
+				}
+			})
+			t.Run("AppendBytes", func(t *testing.T) {
+				prefix := []byte{0xff}
+				b, err := pr.AppendBytes(prefix, testCase.data)
+				if testCase.problem < 0 {
+					if err != nil || !bytes.HasPrefix(b, prefix) {
+						t.Fatalf("got %x, %v, want buffer with prefix %x", b, err, prefix)
+					}
+				} else if !errors.Is(err, ErrCorrupt) {
+					t.Errorf("got error %v, want ErrCorrupt", err)
+				}
+			})
+		})
+	}
+}
+
+func TestProjectionIgnoresUnselectedDuplicates(t *testing.T) {
+	pr, err := NewProjection(parseSchema, "a")
+	if err != nil {
+		t.Fatalf("NewProjection: %v", err)
+	}
+	data := append(append(intEntry(2, 1), intEntry(0, 5)...), intEntry(2, 2)...)
+	b, err := pr.AppendBytes(nil, data)
+	if err != nil {
+		t.Fatalf("AppendBytes: %v", err)
+	} else if want := intEntry(0, 5); !bytes.Equal(b, want) {
+		t.Errorf("got %x, want %x", b, want)
+	}
+}
@@ -2,6 +2,7 @@ package props
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
@@ -42,36 +43,133 @@ type Props struct {
 	// read or write a property yet. For string-ish column types
 	// (string, binary, JSON, date/time), the value offset is really
 	// the offset of the 32-bit length field that comes after the
-	// column index and before the value proper.
+	// column index and before the value proper. The special value
+	// nullOffset indicates the column has been explicitly set to null.
 	offset []int
 	// mutable is true if-and-only if this is a mutable property set.
 	// An immutable set can be switched to mutable using the mutate
 	// function. This requires duplicating the data array.
 	mutable bool
-}
-
-func PropsFromFlat(schema flatgeobuf.Schema, data []byte) *Props {
-	if schema == nil {
-		textPanic("nil schema")
-	}
-	return &Props{
+	// opts contains the optional behaviors set by Option values.
+	opts options
+	// spareOffset and spareData hold storage from before the last call
+	// to Reset, kept so it can be reused for the offset table and for
+	// the mutable copy of the property buffer respectively.
+	spareOffset []int
+	spareData   []byte
+	// unknown holds the keys which were not column names, and their
+	// values, collected by FromMap or UnmarshalJSON under the
+	// UnknownKeysCollect option.
+	unknown map[string]any
+	// feature is the index of the feature whose properties these are,
+	// or -1 if unknown. It is only used to describe parse problems.
+	feature int
+	// parseErr is the first problem found parsing the property buffer
+	// in ParseStrict mode.
+	parseErr error
+	// warnings lists the problems found parsing the property buffer in
+	// ParseLenient mode.
+	warnings []*ParseError
+}
+
+// PropsFromFlat creates a Props holding the properties in data under
+// schema. A nil schema is treated as a schema with no columns.
+func PropsFromFlat(schema flatgeobuf.Schema, data []byte, opts ...Option) *Props {
+	p := &Props{}
+	p.apply(opts)
+	p.Reset(schema, data)
+	return p
+}
+
+// NewProps creates an empty, mutable Props under schema. A nil schema is
+// treated as a schema with no columns.
+func NewProps(schema *Schema, opts ...Option) *Props {
+	schema = orEmpty(schema)
+	p := &Props{
 		flatSchema: schema,
-		data:       *bytes.NewBuffer(data),
-		mutable:    false,
+		fastSchema: schema,
+		mutable:    true,
+		feature:    -1,
 	}
+	p.apply(opts)
+	return p
 }
 
-func NewProps(schema *Schema) *Props {
-	if schema == nil {
-		textPanic("nil schema")
-	}
-	return &Props{
-		flatSchema: schema,
-		fastSchema: schema,
-		mutable:    true,
+func (p *Props) apply(opts []Option) {
+	for _, opt := range opts {
+		opt(&p.opts)
 	}
 }
 
+// Reset discards the current property values and reinitializes p to
+// hold the properties in data under schema, exactly as if p had been
+// created by PropsFromFlat, except that the options of p are kept.
+//
+// Reset reuses the memory p allocated for its offset table and for any
+// mutable copy of its property buffer, so a loop which decodes the
+// properties of every feature in a FlatGeobuf file into the same Props
+// reaches zero steady-state allocations. The zero value of Props is
+// ready for Reset, which makes Props suitable for a sync.Pool:
+//
+//	var pool = sync.Pool{New: func() any { return new(props.Props) }}
+//
+//	p := pool.Get().(*props.Props)
+//	p.Reset(schema, feature.PropertiesBytes())
+//	// ... read properties ...
+//	pool.Put(p)
+//
+// As with PropsFromFlat, data is not copied until p is mutated, so it
+// must not be modified while p refers to it.
+func (p *Props) Reset(schema flatgeobuf.Schema, data []byte) {
+	schema = flatOrEmpty(schema)
+	if p.offset != nil {
+		p.spareOffset = p.offset[:0]
+	}
+	if p.mutable && cap(p.data.Bytes()) > cap(p.spareData) {
+		p.spareData = p.data.Bytes()[:0]
+	}
+	p.flatSchema = schema
+	p.fastSchema, _ = schema.(*Schema)
+	p.data = *bytes.NewBuffer(data)
+	p.offset = nil
+	p.mutable = false
+	p.unknown = nil
+	p.feature = -1
+	p.parseErr = nil
+	p.warnings = nil
+}
+
+// SetFeature sets the index of the feature whose properties p holds,
+// which is reported in any ParseError describing a problem with the
+// property buffer. Reset clears the feature index, so call SetFeature
+// after Reset.
+func (p *Props) SetFeature(i int) {
+	p.feature = i
+}
+
+// Warnings returns the problems found parsing the property buffer in
+// ParseLenient mode, in the order they were found. The property buffer
+// is parsed the first time a value is read or written, or by Parse, so
+// call Warnings after that. The return value is nil if there were no
+// problems.
+func (p *Props) Warnings() []*ParseError {
+	return p.warnings
+}
+
+// newOffset returns a zeroed offset table for n columns, reusing the
+// spare offset table if it is big enough.
+func (p *Props) newOffset(n int) []int {
+	if cap(p.spareOffset) < n {
+		return make([]int, n)
+	}
+	offset := p.spareOffset[:n]
+	p.spareOffset = nil
+	for i := range offset {
+		offset[i] = 0
+	}
+	return offset
+}
+
 func (p *Props) columnType(col int) flat.ColumnType {
 	if p.fastSchema != nil {
 		return p.fastSchema.Type(col)
@@ -90,6 +188,24 @@ func (p *Props) columnType(col int) flat.ColumnType {
 	}
 }
 
+func (p *Props) columnMetadata(col int) string {
+	if p.fastSchema != nil {
+		return p.fastSchema.Column(col).Metadata
+	} else if p.flatSchema != nil {
+		var metadata string
+		_ = interop.FlatBufferSafe(func() error {
+			var obj flat.Column
+			if p.flatSchema.Columns(&obj, col) {
+				metadata = string(obj.Metadata())
+			}
+			return nil
+		})
+		return metadata
+	} else {
+		return ""
+	}
+}
+
 func (p *Props) numColumns() int {
 	if p.fastSchema != nil {
 		return p.fastSchema.ColumnsLength()
@@ -101,80 +217,91 @@ func (p *Props) numColumns() int {
 }
 
 func (p *Props) sizeOfValue(col, offset int) (int, error) {
-	columnType := p.columnType(col)
-	switch columnType {
-	case flat.ColumnTypeBool:
-		return flatbuffers.SizeBool, nil
-	case flat.ColumnTypeByte:
-		return flatbuffers.SizeInt8, nil
-	case flat.ColumnTypeUByte:
-		return flatbuffers.SizeUint8, nil
-	case flat.ColumnTypeShort:
-		return flatbuffers.SizeInt16, nil
-	case flat.ColumnTypeUShort:
-		return flatbuffers.SizeUint16, nil
-	case flat.ColumnTypeInt:
-		return flatbuffers.SizeInt32, nil
-	case flat.ColumnTypeUInt:
-		return flatbuffers.SizeUint32, nil
-	case flat.ColumnTypeLong:
-		return flatbuffers.SizeInt64, nil
-	case flat.ColumnTypeULong:
-		return flatbuffers.SizeUint64, nil
-	case flat.ColumnTypeFloat:
-		return flatbuffers.SizeFloat32, nil
-	case flat.ColumnTypeDouble:
-		return flatbuffers.SizeFloat64, nil
-	case flat.ColumnTypeString, flat.ColumnTypeJson, flat.ColumnTypeBinary, flat.ColumnTypeDateTime:
-		rem := uint64(p.data.Len() - offset)
-		if rem > flatbuffers.SizeUint32 {
-			n := uint64(flatbuffers.GetUint32(p.data.Bytes()[offset:]))
-			rem = rem - n
-			if n <= math.MaxInt-flatbuffers.SizeUint32 {
-				return int(n) + flatbuffers.SizeUint32, nil
-			}
-			return 0, errStringSizeOverflowsInt
-		}
-		return 0, errStringSizeCorrupt
-	default:
-		return 0, errUnknownColumnType
-	}
+	return valueSize(p.columnType(col), p.data.Bytes()[offset:])
 }
 
 func (p *Props) col2Offset(col int) (int, error) {
 	n := p.numColumns()
 	if col < 0 || col >= n {
-		return 0, ErrNoColumn
-	} else if p.offset != nil {
-		return p.offset[col], nil
-	} else if p.mutable {
-		return 0, nil
-	} else {
-		p.offset = make([]int, n)
-		offset := 0
-		for offset < p.data.Len()-flatbuffers.SizeUint16 {
-			j := flatbuffers.GetUint16(p.data.Bytes()[offset:])
-			offset += flatbuffers.SizeUint16
-			if int(j) >= n {
-				continue
-			}
-			sz, err := p.sizeOfValue(int(j), offset)
-			if err != nil || offset+sz > p.data.Len() {
-				break
+		return 0, &ColumnError{Feature: p.feature, Col: col, Err: ErrNoColumn}
+	} else if err := p.Parse(); err != nil {
+		return 0, err
+	}
+	return p.offset[col], nil
+}
+
+// Parse parses the property buffer now, instead of the first time a
+// value is read or written. In ParseStrict mode, Parse returns the
+// first problem found. In ParseLenient mode, Parse returns nil and the
+// problems are available from Warnings. Calling Parse again has no
+// effect.
+func (p *Props) Parse() error {
+	if p.offset == nil {
+		p.offset = p.newOffset(p.numColumns())
+		if !p.mutable {
+			p.parse(len(p.offset))
+		}
+	}
+	return p.parseErr
+}
+
+// parse builds the offset table of an immutable Props from its property
+// buffer, handling problems according to the parse mode.
+func (p *Props) parse(n int) {
+	data := p.data.Bytes()
+	offset := 0
+	for offset < len(data) {
+		col, valueOffset, size, err := nextEntry(data, offset, n, p.columnType)
+		if err != nil {
+			p.problem(err)
+			return
+		} else if first := p.offset[col]; first > 0 {
+			dup := &ParseError{Offset: offset, Col: col, Reason: fmt.Sprintf("duplicate entry for column, first at byte %d", first-flatbuffers.SizeUint16)}
+			if !p.problem(dup) {
+				return
 			}
-			p.offset[j] = offset
-			offset += sz
 		}
-		return p.offset[col], nil
+		p.offset[col] = valueOffset
+		offset = valueOffset + size
 	}
 }
 
+// problem records a problem found parsing the property buffer, either
+// as the parse error in ParseStrict mode or as a warning in
+// ParseLenient mode. It reports whether parsing may continue.
+func (p *Props) problem(err *ParseError) bool {
+	err.Feature = p.feature
+	if p.opts.parseMode == ParseStrict {
+		p.parseErr = err
+		return false
+	}
+	p.warnings = append(p.warnings, err)
+	return true
+}
+
+// rawValue returns the encoded bytes of a column value, including the
+// length prefix for string-ish column types. The return value is nil if
+// the column is absent or null.
+func (p *Props) rawValue(col int) ([]byte, error) {
+	offset, err := p.col2Offset(col)
+	if err != nil || offset <= 0 {
+		return nil, err
+	}
+	sz, err := p.sizeOfValue(col, offset)
+	if err != nil {
+		return nil, err
+	}
+	return p.data.Bytes()[offset : offset+sz], nil
+}
+
 func (p *Props) name2Col(name string) (int, error) {
 	if p.fastSchema != nil {
 		if col, ok := p.fastSchema.Index(name); ok {
 			return col, nil
 		}
-		return 0, ErrNoColumn
+		return 0, p.noColumnErr(name)
+	} else if p.flatSchema == nil {
+		return 0, p.noColumnErr(name)
 	} else {
 		n := p.flatSchema.ColumnsLength()
 		var col int
@@ -189,19 +316,25 @@ func (p *Props) name2Col(name string) (int, error) {
 					}
 					for j := range b {
 						if b[j] != name[j] {
-							break columns
+							continue columns
 						}
 					}
 					col = i
 					return nil
 				}
 			}
-			return ErrNoColumn
+			return p.noColumnErr(name)
 		})
 		return col, err
 	}
 }
 
+// noColumnErr returns the error for looking up a column by a name which
+// isn't in the schema.
+func (p *Props) noColumnErr(name string) error {
+	return &ColumnError{Feature: p.feature, Col: -1, Name: name, Err: ErrNoColumn}
+}
+
 func (p *Props) name2Offset(name string) (int, error) {
 	col, err := p.name2Col(name)
 	if err != nil {
@@ -212,8 +345,8 @@ func (p *Props) name2Offset(name string) (int, error) {
 
 func (p *Props) mutate() {
 	if !p.mutable {
-		data := make([]byte, p.data.Len())
-		copy(data, p.data.Bytes())
+		data := append(p.spareData[:0], p.data.Bytes()...)
+		p.spareData = nil
 		p.data = *bytes.NewBuffer(data)
 		p.mutable = true
 	}
@@ -222,22 +355,71 @@ func (p *Props) mutate() {
 // minCap is the minimum capacity of a newly-allocated data slice.
 const minCap = 64
 
-func (p *Props) extend(col, n int) []byte {
+// extend appends a new value of n bytes for a column which has no value
+// and returns the buffer starting at the new value, whose bytes the
+// caller must overwrite.
+func (p *Props) extend(col, n int) ([]byte, error) {
 	if !p.mutable {
-		// FIXME: Panic here, it's a logic error
-	} else if p.offset[col] != 0 {
-		// FIXME: Panic here, it's a logic error.
+		return nil, textErr("extend called on immutable props")
+	} else if p.offset[col] > 0 {
+		return nil, textErr("extend called on column which already has a value")
 	} else if col > math.MaxUint16 {
-		// FIXME: Panic here, it's a logic error.
-	} else if n > math.MaxInt-flatbuffers.SizeUint16 {
-		// FIXME: Panic here, it's a logic error.
+		return nil, fmtErr("column index %d overflows uint16", col)
+	} else if n > math.MaxInt-flatbuffers.SizeUint16-p.data.Len() {
+		return nil, fmtErr("column %d: value size %d overflows int", col, n)
+	}
+	if p.data.Len() < minCap {
+		p.data.Grow(minCap)
+	}
+	var hdr [flatbuffers.SizeUint16]byte
+	flatbuffers.WriteUint16(hdr[:], uint16(col))
+	_, _ = p.data.Write(hdr[:])
+	i := p.data.Len()
+	p.data.Grow(n)
+	// Writing the grown region onto itself extends the buffer length
+	// without an intermediate allocation. The caller overwrites the
+	// new bytes.
+	_, _ = p.data.Write(p.data.Bytes()[i : i+n])
+	p.offset[col] = i
+	return p.data.Bytes()[i:], nil
+}
+
+// overlaps reports whether a and the backing array of b, up to its
+// capacity, share any memory.
+func overlaps(a, b []byte) bool {
+	if len(a) == 0 || cap(b) == 0 {
+		return false
+	}
+	a0 := uintptr(unsafe.Pointer(&a[0]))
+	b0 := uintptr(unsafe.Pointer(&b[:cap(b)][0]))
+	return a0 < b0+uintptr(cap(b)) && b0 < a0+uintptr(len(a))
+}
+
+// resize changes the size of the value at offset from oldSize to
+// newSize bytes, moving the values after it up or down as necessary,
+// and returns the buffer starting at offset.
+func (p *Props) resize(offset, oldSize, newSize int) []byte {
+	d := newSize - oldSize
+	if d == 0 {
+		return p.data.Bytes()[offset:]
+	}
+	n := p.data.Len()
+	if d > 0 {
+		p.data.Grow(d)
+		_, _ = p.data.Write(p.data.Bytes()[n : n+d]) // Extend length, see extend.
 	}
-	p.data.Grow(flatbuffers.SizeUint16 + n)
 	b := p.data.Bytes()
-	flatbuffers.WriteUint16(b, uint16(n))
-	i := len(b) + flatbuffers.SizeUint16
-	p.offset[col] = i
-	return b[i:]
+	end := offset + oldSize
+	copy(b[end+d:], b[end:n])
+	if d < 0 {
+		p.data.Truncate(n + d)
+	}
+	for j := range p.offset {
+		if p.offset[j] > offset {
+			p.offset[j] += d
+		}
+	}
+	return p.data.Bytes()[offset:]
 }
 
 func (p *Props) delete(col, offset int) {
@@ -246,43 +428,179 @@ func (p *Props) delete(col, offset int) {
 	if err != nil {
 		return
 	}
-	if offset+sz < p.data.Len() {
-		b := p.data.Bytes()
-		copy(b[offset-flatbuffers.SizeUint16:], b[offset+sz:])
-	}
-	p.data.Truncate(offset - flatbuffers.SizeUint16)
+	start, end := offset-flatbuffers.SizeUint16, offset+sz
+	b := p.data.Bytes()
+	copy(b[start:], b[end:])
+	p.data.Truncate(len(b) - (end - start))
 	p.offset[col] = 0
+	for j := range p.offset {
+		if p.offset[j] > start {
+			p.offset[j] -= end - start
+		}
+	}
+}
+
+func (p *Props) nullable(col int) bool {
+	if p.fastSchema != nil {
+		return !p.fastSchema.Column(col).Required
+	} else if p.flatSchema != nil {
+		nullable := true
+		_ = interop.FlatBufferSafe(func() error {
+			var obj flat.Column
+			if p.flatSchema.Columns(&obj, col) {
+				nullable = obj.Nullable()
+			}
+			return nil
+		})
+		return nullable
+	} else {
+		return true
+	}
+}
+
+func (p *Props) columnName(col int) string {
+	if p.fastSchema != nil {
+		return p.fastSchema.Name(col)
+	} else if p.flatSchema != nil {
+		var name string
+		_ = interop.FlatBufferSafe(func() error {
+			var obj flat.Column
+			if p.flatSchema.Columns(&obj, col) {
+				name = string(obj.Name())
+			}
+			return nil
+		})
+		return name
+	} else {
+		return ""
+	}
+}
+
+// columnErr returns a ColumnError for a column of p wrapping err.
+func (p *Props) columnErr(col int, err error) error {
+	return &ColumnError{Feature: p.feature, Col: col, Name: p.columnName(col), Err: err}
+}
+
+// noValueErr returns the error for reading a column which is absent or
+// null, according to its offset.
+func (p *Props) noValueErr(col, offset int) error {
+	if offset == nullOffset {
+		return p.columnErr(col, ErrNull)
+	}
+	return p.columnErr(col, ErrNoValue)
+}
+
+// typeErr returns a TypeError for using a column of p as type actual.
+func (p *Props) typeErr(col int, actual flat.ColumnType) error {
+	return &TypeError{Feature: p.feature, Col: col, Name: p.columnName(col), Expected: p.columnType(col), Actual: actual}
+}
+
+// valueErr returns the error for a Go value which cannot be converted
+// to the type of a column. The cause may be nil.
+func (p *Props) valueErr(col int, value any, cause error) error {
+	return &TypeError{Feature: p.feature, Col: col, Name: p.columnName(col), Expected: p.columnType(col), Value: value, Cause: cause}
+}
+
+// jsonErr returns the error for JSON text which is not valid in the
+// context of a column. The cause may be nil.
+func (p *Props) jsonErr(col int, cause error) error {
+	return &ColumnError{Feature: p.feature, Col: col, Name: p.columnName(col), Err: ErrInvalidJSON, Cause: cause}
 }
 
 func (p *Props) check(col int, expectedType flat.ColumnType) error {
-	actualType := p.columnType(col)
-	if actualType != expectedType {
-		return ErrTypeMismatch
+	if p.columnType(col) != expectedType {
+		return p.typeErr(col, expectedType)
 	}
 	return nil
 }
 
+// Schema returns the schema of p. If p was created from a FlatGeobuf
+// schema, rather than a Schema from this package, the schema is
+// converted using SchemaFromFlat, and Schema returns nil if it cannot
+// be converted.
 func (p *Props) Schema() *Schema {
-	// TODO: Do we want FlatSchema and Schema? The former would always have a return value.
-	return nil
+	if p.fastSchema != nil {
+		return p.fastSchema
+	} else if p.flatSchema == nil {
+		return nil
+	}
+	s, err := SchemaFromFlat(p.flatSchema)
+	if err != nil {
+		return nil
+	}
+	return s
 }
 
+// Has reports whether a column has a non-null value.
 func (p *Props) Has(col int) bool {
 	offset, err := p.col2Offset(col)
-	return err != nil && offset > 0
+	return err == nil && offset > 0
 }
 
 func (p *Props) HasName(name string) bool {
 	offset, err := p.name2Offset(name)
-	return err != nil && offset > 0
+	return err == nil && offset > 0
+}
+
+// IsNull reports whether a column has been explicitly set to null using
+// SetNull.
+//
+// A column which has never been given a value is absent, not null, and
+// IsNull returns false for it. Note that the FlatGeobuf property format
+// represents null by omitting the value, so when properties are read
+// from a FlatGeobuf file, null columns are indistinguishable from
+// absent ones and IsNull always returns false.
+func (p *Props) IsNull(col int) bool {
+	offset, err := p.col2Offset(col)
+	return err == nil && offset == nullOffset
+}
+
+func (p *Props) IsNullName(name string) bool {
+	offset, err := p.name2Offset(name)
+	return err == nil && offset == nullOffset
+}
+
+// SetNull explicitly sets a column to null, removing any existing
+// value. Getters for a null column return an error wrapping ErrNull,
+// except GetValue, which returns a Null value.
+//
+// If the column is required, SetNull returns a ColumnError wrapping
+// ErrRequired and leaves the column unchanged.
+func (p *Props) SetNull(col int) error {
+	offset, err := p.col2Offset(col)
+	if err != nil {
+		return err
+	} else if !p.nullable(col) {
+		return p.columnErr(col, ErrRequired)
+	} else if offset > 0 {
+		p.delete(col, offset)
+	} else {
+		p.mutate()
+	}
+	p.offset[col] = nullOffset
+	return nil
+}
+
+func (p *Props) SetNullName(name string) error {
+	col, err := p.name2Col(name)
+	if err != nil {
+		return err
+	}
+	return p.SetNull(col)
 }
 
+// Delete removes the value from a column, or clears the column's null
+// mark if it is null, leaving the column absent. The return value
+// indicates whether the column had a value or was null.
 func (p *Props) Delete(col int) bool {
 	offset, err := p.col2Offset(col)
-	if err == nil || offset == 0 {
+	if err != nil || offset == 0 {
 		return false
+	} else if offset == nullOffset {
+		p.offset[col] = 0
+	} else {
+		p.delete(col, offset)
 	}
-	p.delete(col, offset)
 	return true
 }
 
@@ -294,8 +612,36 @@ func (p *Props) DeleteName(name string) bool {
 	return p.Delete(index)
 }
 
+// Validate checks that every required column has a value. If any
+// required columns are absent or null, the returned error joins one
+// error per such column, each of which wraps ErrRequired.
+func (p *Props) Validate() error {
+	n := p.numColumns()
+	var errs []error
+	for col := 0; col < n; col++ {
+		if p.nullable(col) {
+			continue
+		}
+		offset, err := p.col2Offset(col)
+		if err != nil {
+			return err
+		} else if offset <= 0 {
+			errs = append(errs, p.columnErr(col, ErrRequired))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// GetValue returns the value of a column, whatever its type.
+//
+// If the column has been explicitly set to null, GetValue returns a
+// Null value whose Type is the column type, and a nil error. If the
+// column is absent, GetValue returns a ColumnError wrapping ErrNoValue.
 func (p *Props) GetValue(col int) (any, error) {
 	columnType := p.columnType(col)
+	if p.IsNull(col) {
+		return Null{Type: columnType}, nil
+	}
 	switch columnType {
 	case flat.ColumnTypeBool:
 		return p.GetBool(col)
@@ -326,12 +672,16 @@ func (p *Props) GetValue(col int) (any, error) {
 	case flat.ColumnTypeBinary:
 		return p.GetBinary(col)
 	case flat.ColumnTypeDateTime:
-		v, err := p.GetDateTime(col)
+		v, err := p.GetDateTimeValue(col)
 		var x *time.ParseError
 		if errors.As(err, &x) {
 			return p.GetDateTimeString(col)
+		} else if err != nil {
+			return nil, err
+		} else if v.IsPartial() {
+			return v, nil
 		}
-		return v, err
+		return v.Time, nil
 	default:
 		return nil, errInvalidColumnType(columnType)
 	}
@@ -345,8 +695,13 @@ func (p *Props) GetValueName(name string) (any, error) {
 	return p.GetValue(col)
 }
 
+// SetValue sets the value of a column from a Go value whose type
+// corresponds to the column type. A nil value or a Null value sets the
+// column to null.
 func (p *Props) SetValue(col int, value any) error {
 	switch v := value.(type) {
+	case nil, Null:
+		return p.SetNull(col)
 	case bool:
 		return p.SetBool(col, v)
 	case int8:
@@ -375,8 +730,10 @@ func (p *Props) SetValue(col int, value any) error {
 		return p.SetBinary(col, v)
 	case time.Time:
 		return p.SetDateTime(col, v)
+	case DateTime:
+		return p.SetDateTimeValue(col, v)
 	default:
-		return fmtErr("value %v of type %T does not map to a FlatGeobuf column type", value, v)
+		return p.valueErr(col, value, nil)
 	}
 }
 
@@ -394,8 +751,8 @@ func (p *Props) GetBool(col int) (bool, error) {
 		return false, err
 	} else if err = p.check(col, flat.ColumnTypeBool); err != nil {
 		return false, err
-	} else if offset == 0 {
-		return false, ErrNoValue
+	} else if offset <= 0 {
+		return false, p.noValueErr(col, offset)
 	}
 	return p.data.Bytes()[offset] != 0, nil
 }
@@ -420,7 +777,9 @@ func (p *Props) SetBool(col int, value bool) error {
 	if offset > 0 {
 		b = p.data.Bytes()[offset:]
 	} else {
-		b = p.extend(col, flatbuffers.SizeBool)
+		if b, err = p.extend(col, flatbuffers.SizeBool); err != nil {
+			return err
+		}
 	}
 	var v byte
 	if value {
@@ -444,8 +803,8 @@ func (p *Props) GetByte(col int) (int8, error) {
 		return 0, err
 	} else if err = p.check(col, flat.ColumnTypeByte); err != nil {
 		return 0, err
-	} else if offset == 0 {
-		return 0, ErrNoValue
+	} else if offset <= 0 {
+		return 0, p.noValueErr(col, offset)
 	}
 	return int8(p.data.Bytes()[offset]), nil
 }
@@ -470,7 +829,9 @@ func (p *Props) SetByte(col int, value int8) error {
 	if offset > 0 {
 		b = p.data.Bytes()[offset:]
 	} else {
-		b = p.extend(col, flatbuffers.SizeInt8)
+		if b, err = p.extend(col, flatbuffers.SizeInt8); err != nil {
+			return err
+		}
 	}
 	b[0] = byte(value)
 	return nil
@@ -490,8 +851,8 @@ func (p *Props) GetUByte(col int) (uint8, error) {
 		return 0, err
 	} else if err = p.check(col, flat.ColumnTypeUByte); err != nil {
 		return 0, err
-	} else if offset == 0 {
-		return 0, ErrNoValue
+	} else if offset <= 0 {
+		return 0, p.noValueErr(col, offset)
 	}
 	return p.data.Bytes()[offset], nil
 }
@@ -516,7 +877,9 @@ func (p *Props) SetUByte(col int, value uint8) error {
 	if offset > 0 {
 		b = p.data.Bytes()[offset:]
 	} else {
-		b = p.extend(col, flatbuffers.SizeUint8)
+		if b, err = p.extend(col, flatbuffers.SizeUint8); err != nil {
+			return err
+		}
 	}
 	b[0] = value
 	return nil
@@ -536,8 +899,8 @@ func (p *Props) GetShort(col int) (int16, error) {
 		return 0, err
 	} else if err = p.check(col, flat.ColumnTypeShort); err != nil {
 		return 0, err
-	} else if offset == 0 {
-		return 0, ErrNoValue
+	} else if offset <= 0 {
+		return 0, p.noValueErr(col, offset)
 	}
 	return flatbuffers.GetInt16(p.data.Bytes()[offset:]), nil
 }
@@ -562,7 +925,9 @@ func (p *Props) SetShort(col int, value int16) error {
 	if offset > 0 {
 		b = p.data.Bytes()[offset:]
 	} else {
-		b = p.extend(col, flatbuffers.SizeInt16)
+		if b, err = p.extend(col, flatbuffers.SizeInt16); err != nil {
+			return err
+		}
 	}
 	flatbuffers.WriteInt16(b, value)
 	return nil
@@ -582,8 +947,8 @@ func (p *Props) GetUShort(col int) (uint16, error) {
 		return 0, err
 	} else if err = p.check(col, flat.ColumnTypeUShort); err != nil {
 		return 0, err
-	} else if offset == 0 {
-		return 0, ErrNoValue
+	} else if offset <= 0 {
+		return 0, p.noValueErr(col, offset)
 	}
 	return flatbuffers.GetUint16(p.data.Bytes()[offset:]), nil
 }
@@ -608,7 +973,9 @@ func (p *Props) SetUShort(col int, value uint16) error {
 	if offset > 0 {
 		b = p.data.Bytes()[offset:]
 	} else {
-		b = p.extend(col, flatbuffers.SizeUint16)
+		if b, err = p.extend(col, flatbuffers.SizeUint16); err != nil {
+			return err
+		}
 	}
 	flatbuffers.WriteUint16(b, value)
 	return nil
@@ -628,8 +995,8 @@ func (p *Props) GetInt(col int) (int32, error) {
 		return 0, err
 	} else if err = p.check(col, flat.ColumnTypeInt); err != nil {
 		return 0, err
-	} else if offset == 0 {
-		return 0, ErrNoValue
+	} else if offset <= 0 {
+		return 0, p.noValueErr(col, offset)
 	}
 	return flatbuffers.GetInt32(p.data.Bytes()[offset:]), nil
 }
@@ -654,7 +1021,9 @@ func (p *Props) SetInt(col int, value int32) error {
 	if offset > 0 {
 		b = p.data.Bytes()[offset:]
 	} else {
-		b = p.extend(col, flatbuffers.SizeInt32)
+		if b, err = p.extend(col, flatbuffers.SizeInt32); err != nil {
+			return err
+		}
 	}
 	flatbuffers.WriteInt32(b, value)
 	return nil
@@ -674,8 +1043,8 @@ func (p *Props) GetUInt(col int) (uint32, error) {
 		return 0, err
 	} else if err = p.check(col, flat.ColumnTypeUInt); err != nil {
 		return 0, err
-	} else if offset == 0 {
-		return 0, ErrNoValue
+	} else if offset <= 0 {
+		return 0, p.noValueErr(col, offset)
 	}
 	return flatbuffers.GetUint32(p.data.Bytes()[offset:]), nil
 }
@@ -700,7 +1069,9 @@ func (p *Props) SetUInt(col int, value uint32) error {
 	if offset > 0 {
 		b = p.data.Bytes()[offset:]
 	} else {
-		b = p.extend(col, flatbuffers.SizeUint32)
+		if b, err = p.extend(col, flatbuffers.SizeUint32); err != nil {
+			return err
+		}
 	}
 	flatbuffers.WriteUint32(b, value)
 	return nil
@@ -720,8 +1091,8 @@ func (p *Props) GetLong(col int) (int64, error) {
 		return 0, err
 	} else if err = p.check(col, flat.ColumnTypeLong); err != nil {
 		return 0, err
-	} else if offset == 0 {
-		return 0, ErrNoValue
+	} else if offset <= 0 {
+		return 0, p.noValueErr(col, offset)
 	}
 	return flatbuffers.GetInt64(p.data.Bytes()[offset:]), nil
 }
@@ -746,7 +1117,9 @@ func (p *Props) SetLong(col int, value int64) error {
 	if offset > 0 {
 		b = p.data.Bytes()[offset:]
 	} else {
-		b = p.extend(col, flatbuffers.SizeInt64)
+		if b, err = p.extend(col, flatbuffers.SizeInt64); err != nil {
+			return err
+		}
 	}
 	flatbuffers.WriteInt64(b, value)
 	return nil
@@ -766,8 +1139,8 @@ func (p *Props) GetULong(col int) (uint64, error) {
 		return 0, err
 	} else if err = p.check(col, flat.ColumnTypeULong); err != nil {
 		return 0, err
-	} else if offset == 0 {
-		return 0, ErrNoValue
+	} else if offset <= 0 {
+		return 0, p.noValueErr(col, offset)
 	}
 	return flatbuffers.GetUint64(p.data.Bytes()[offset:]), nil
 }
@@ -792,7 +1165,9 @@ func (p *Props) SetULong(col int, value uint64) error {
 	if offset > 0 {
 		b = p.data.Bytes()[offset:]
 	} else {
-		b = p.extend(col, flatbuffers.SizeUint64)
+		if b, err = p.extend(col, flatbuffers.SizeUint64); err != nil {
+			return err
+		}
 	}
 	flatbuffers.WriteUint64(b, value)
 	return nil
@@ -812,8 +1187,8 @@ func (p *Props) GetFloat(col int) (float32, error) {
 		return 0, err
 	} else if err = p.check(col, flat.ColumnTypeFloat); err != nil {
 		return 0, err
-	} else if offset == 0 {
-		return 0, ErrNoValue
+	} else if offset <= 0 {
+		return 0, p.noValueErr(col, offset)
 	}
 	return flatbuffers.GetFloat32(p.data.Bytes()[offset:]), nil
 }
@@ -838,7 +1213,9 @@ func (p *Props) SetFloat(col int, value float32) error {
 	if offset > 0 {
 		b = p.data.Bytes()[offset:]
 	} else {
-		b = p.extend(col, flatbuffers.SizeFloat32)
+		if b, err = p.extend(col, flatbuffers.SizeFloat32); err != nil {
+			return err
+		}
 	}
 	flatbuffers.WriteFloat32(b, value)
 	return nil
@@ -858,8 +1235,8 @@ func (p *Props) GetDouble(col int) (float64, error) {
 		return 0, err
 	} else if err = p.check(col, flat.ColumnTypeDouble); err != nil {
 		return 0, err
-	} else if offset == 0 {
-		return 0, ErrNoValue
+	} else if offset <= 0 {
+		return 0, p.noValueErr(col, offset)
 	}
 	return flatbuffers.GetFloat64(p.data.Bytes()[offset:]), nil
 }
@@ -884,7 +1261,9 @@ func (p *Props) SetDouble(col int, value float64) error {
 	if offset > 0 {
 		b = p.data.Bytes()[offset:]
 	} else {
-		b = p.extend(col, flatbuffers.SizeFloat64)
+		if b, err = p.extend(col, flatbuffers.SizeFloat64); err != nil {
+			return err
+		}
 	}
 	flatbuffers.WriteFloat64(b, value)
 	return nil
@@ -904,8 +1283,8 @@ func (p *Props) getBinary(col int, columnType flat.ColumnType) ([]byte, error) {
 		return nil, err
 	} else if err = p.check(col, columnType); err != nil {
 		return nil, err
-	} else if offset == 0 {
-		return nil, ErrNoValue
+	} else if offset <= 0 {
+		return nil, p.noValueErr(col, offset)
 	}
 	b := p.data.Bytes()[offset:]
 	n := uint64(flatbuffers.GetUint32(b))
@@ -921,21 +1300,26 @@ func (p *Props) setBinary(col int, columnType flat.ColumnType, value []byte) err
 		return err
 	} else if err = p.check(col, columnType); err != nil {
 		return err
-	} else /* IF ... TODO: Do an overflow check on this branch. */ {
+	} else if uint64(len(value)) > math.MaxUint32 {
+		return fmtErr("value length %d overflows uint32 size prefix", len(value))
 	}
 	var b []byte
 	p.mutate()
+	if overlaps(value, p.data.Bytes()) {
+		// The value is a view of this buffer, which resizing would
+		// overwrite, so copy it first.
+		value = append([]byte(nil), value...)
+	}
 	if offset > 0 {
-		b = p.data.Bytes()[offset:]
-		n := flatbuffers.GetUint32(b)
-		if n != uint32(len(value)) {
-			b = nil
-			p.delete(col, offset) // Length changed. Delete and re-extend.
+		n := int(flatbuffers.GetUint32(p.data.Bytes()[offset:]))
+		// Resize in place if the length changed, so that the value keeps
+		// its position in the buffer.
+		b = p.resize(offset, flatbuffers.SizeUint32+n, flatbuffers.SizeUint32+len(value))
+	} else {
+		if b, err = p.extend(col, flatbuffers.SizeUint32+len(value)); err != nil {
+			return err
 		}
 	}
-	if b == nil {
-		b = p.extend(col, len(value))
-	}
 	flatbuffers.WriteUint32(b, uint32(len(value)))
 	copy(b[flatbuffers.SizeUint32:], value)
 	return nil
@@ -985,8 +1369,19 @@ func (p *Props) GetJSONName(name string) (string, error) {
 	return p.GetJSON(col)
 }
 
+// SetJSON sets the value of a JSON column from JSON text. If p has the
+// WithStrictJSON option, SetJSON returns an error wrapping
+// ErrInvalidJSON if value is not valid JSON, otherwise value is stored
+// without being checked.
 func (p *Props) SetJSON(col int, value string) error {
-	return p.setBinary(col, flat.ColumnTypeJson, unsafe.Slice(unsafe.StringData(value), len(value)))
+	b := unsafe.Slice(unsafe.StringData(value), len(value))
+	if p.opts.strictJSON && !json.Valid(b) {
+		if err := p.check(col, flat.ColumnTypeJson); err != nil {
+			return err
+		}
+		return p.jsonErr(col, nil)
+	}
+	return p.setBinary(col, flat.ColumnTypeJson, b)
 }
 
 func (p *Props) SetJSONName(name string, value string) error {
@@ -997,6 +1392,51 @@ func (p *Props) SetJSONName(name string, value string) error {
 	return p.SetJSON(col, value)
 }
 
+// GetJSONInto decodes the value of a JSON column into v using
+// json.Unmarshal.
+func (p *Props) GetJSONInto(col int, v any) error {
+	b, err := p.getBinary(col, flat.ColumnTypeJson)
+	if err != nil {
+		return err
+	} else if err = json.Unmarshal(b, v); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return p.jsonErr(col, err)
+		}
+		return p.valueErr(col, v, err)
+	}
+	return nil
+}
+
+func (p *Props) GetJSONIntoName(name string, v any) error {
+	col, err := p.name2Col(name)
+	if err != nil {
+		return err
+	}
+	return p.GetJSONInto(col, v)
+}
+
+// SetJSONValue sets the value of a JSON column to the encoding of v
+// produced by json.Marshal.
+func (p *Props) SetJSONValue(col int, v any) error {
+	if err := p.check(col, flat.ColumnTypeJson); err != nil {
+		return err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return p.valueErr(col, v, err)
+	}
+	return p.setBinary(col, flat.ColumnTypeJson, b)
+}
+
+func (p *Props) SetJSONValueName(name string, v any) error {
+	col, err := p.name2Col(name)
+	if err != nil {
+		return err
+	}
+	return p.SetJSONValue(col, v)
+}
+
 func (p *Props) GetBinary(col int) ([]byte, error) {
 	b, err := p.getBinary(col, flat.ColumnTypeBinary)
 	if err != nil {
@@ -1027,15 +1467,19 @@ func (p *Props) SetBinaryName(name string, value []byte) error {
 	return p.SetBinary(col, value)
 }
 
-// TODO: Will also need a version of this to get date/time as string, not time.Time, in case parse fails.
-
+// GetDateTime returns the value of a date/time column as a time.Time.
+//
+// The stored value is parsed as ISO 8601 using ParseDateTime. If the
+// stored value is partial, the missing parts are filled in as described
+// on DateTime.Time, in particular a value with no zone is returned in
+// UTC. Use GetDateTimeValue to find out which parts were present, or
+// GetDateTimeString to get the stored text.
 func (p *Props) GetDateTime(col int) (time.Time, error) {
-	b, err := p.getBinary(col, flat.ColumnTypeDateTime)
+	v, err := p.GetDateTimeValue(col)
 	if err != nil {
 		return time.Time{}, err
 	}
-	s := unsafe.String(&b[0], len(b)) // Temporary unsafe string pointing into buffer.
-	return time.Parse(time.RFC3339, s)
+	return v.Time, nil
 }
 
 func (p *Props) GetDateTimeName(name string) (time.Time, error) {
@@ -1046,11 +1490,15 @@ func (p *Props) GetDateTimeName(name string) (time.Time, error) {
 	return p.GetDateTime(col)
 }
 
+// SetDateTime sets the value of a date/time column from a time.Time.
+//
+// The value is formatted using the layout from the column metadata, if
+// the column metadata specifies one (see DateTimeLayoutFromMetadata),
+// otherwise the layout given by WithDateTimeLayout, otherwise
+// time.RFC3339Nano.
 func (p *Props) SetDateTime(col int, value time.Time) error {
-	s := value.Format(time.RFC3339) // TODO: Use our own format string????
-	ptr := unsafe.StringData(s)
-	b := unsafe.Slice(ptr, len(s))
-	return p.setBinary(col, flat.ColumnTypeDateTime, b)
+	s := value.Format(p.dateTimeLayoutOf(col))
+	return p.setBinary(col, flat.ColumnTypeDateTime, unsafe.Slice(unsafe.StringData(s), len(s)))
 }
 
 func (p *Props) SetDateTimeName(name string, value time.Time) error {
@@ -1061,6 +1509,56 @@ func (p *Props) SetDateTimeName(name string, value time.Time) error {
 	return p.SetDateTime(col, value)
 }
 
+// GetDateTimeValue returns the value of a date/time column as a
+// DateTime, which records which parts of the ISO 8601 value were
+// present in the stored text.
+func (p *Props) GetDateTimeValue(col int) (DateTime, error) {
+	b, err := p.getBinary(col, flat.ColumnTypeDateTime)
+	if err != nil {
+		return DateTime{}, err
+	}
+	v, err := ParseDateTime(viewString(b)) // Temporary view string pointing into buffer.
+	if err != nil {
+		// Detach the error from the buffer, which may change later.
+		err = &time.ParseError{Value: string(b), Message: err.(*time.ParseError).Message}
+	}
+	return v, err
+}
+
+func (p *Props) GetDateTimeValueName(name string) (DateTime, error) {
+	col, err := p.name2Col(name)
+	if err != nil {
+		return DateTime{}, err
+	}
+	return p.GetDateTimeValue(col)
+}
+
+// SetDateTimeValue sets the value of a date/time column from a
+// DateTime. Only the parts of the DateTime which are present are
+// written, in the ISO 8601 form produced by DateTime.String. The
+// date/time layout options do not apply.
+func (p *Props) SetDateTimeValue(col int, value DateTime) error {
+	s := value.String()
+	return p.setBinary(col, flat.ColumnTypeDateTime, unsafe.Slice(unsafe.StringData(s), len(s)))
+}
+
+func (p *Props) SetDateTimeValueName(name string, value DateTime) error {
+	col, err := p.name2Col(name)
+	if err != nil {
+		return err
+	}
+	return p.SetDateTimeValue(col, value)
+}
+
+func (p *Props) dateTimeLayoutOf(col int) string {
+	if layout := DateTimeLayoutFromMetadata(p.columnMetadata(col)); layout != "" {
+		return layout
+	} else if p.opts.dateTimeLayout != "" {
+		return p.opts.dateTimeLayout
+	}
+	return time.RFC3339Nano
+}
+
 func (p *Props) GetDateTimeString(col int) (string, error) {
 	b, err := p.getBinary(col, flat.ColumnTypeDateTime)
 	if err != nil {
@@ -1106,8 +1604,7 @@ func (p *Props) String() string {
 		} else {
 			var obj flat.Column
 			if p.flatSchema.Columns(&obj, i) {
-				b := obj.Name()
-				name = unsafe.String(&b[0], len(b))
+				name = viewString(obj.Name())
 			}
 		}
 		if printed {
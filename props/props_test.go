@@ -0,0 +1,239 @@
+package props
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+)
+
+func TestParseMode(t *testing.T) {
+	for _, testCase := range parseBuffers {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Run("lenient", func(t *testing.T) {
+				p := NewProps(parseSchema)
+				p.Reset(parseSchema, testCase.data)
+				p.SetFeature(3)
+				if err := p.Parse(); err != nil {
+					t.Fatalf("Parse: %v", err)
+				}
+				for col := 0; col < parseSchema.ColumnsLength(); col++ {
+					got, err := p.GetValue(col)
+					want, ok := testCase.values[col]
+					if !ok {
+						if !errors.Is(err, ErrNoValue) {
+							t.Errorf("column %d: got %v, %v, want ErrNoValue", col, got, err)
+						}
+					} else if err != nil || got != want {
+						t.Errorf("column %d: got %v, %v, want %v", col, got, err, want)
+					}
+				}
+				warnings := p.Warnings()
+				if testCase.problem < 0 {
+					if len(warnings) != 0 {
+						t.Errorf("got warnings %v, want none", warnings)
+					}
+				} else if len(warnings) != 1 || warnings[0].Offset != testCase.problem || warnings[0].Feature != 3 {
+					t.Errorf("got warnings %v, want one at byte %d of feature 3", warnings, testCase.problem)
+				}
+			})
+			t.Run("strict", func(t *testing.T) {
+				p := NewProps(parseSchema, WithParseMode(ParseStrict))
+				p.Reset(parseSchema, testCase.data)
+				p.SetFeature(3)
+				_, err := p.GetValue(0)
+				if testCase.problem < 0 {
+					if err != nil {
+						t.Fatalf("GetValue: %v", err)
+					}
+					return
+				}
+				var parseErr *ParseError
+				if !errors.As(err, &parseErr) || !errors.Is(err, ErrCorrupt) {
+					t.Fatalf("got error %v, want *ParseError", err)
+				} else if parseErr.Offset != testCase.problem || parseErr.Feature != 3 {
+					t.Errorf("got error at byte %d of feature %d, want byte %d of feature 3", parseErr.Offset, parseErr.Feature, testCase.problem)
+				}
+				if len(p.Warnings()) != 0 {
+					t.Errorf("got warnings %v, want none in ParseStrict mode", p.Warnings())
+				}
+			})
+		})
+	}
+}
+
+func TestResetAllocations(t *testing.T) {
+	schema := NewSchema([]Column{
+		{Name: "a", Type: flat.ColumnTypeInt},
+		{Name: "b", Type: flat.ColumnTypeString},
+		{Name: "c", Type: flat.ColumnTypeDouble},
+		{Name: "d", Type: flat.ColumnTypeBool},
+	})
+	features := [][]byte{
+		bytesJoin(intEntry(0, 1), stringEntry(1, 3, "abc")),
+		bytesJoin(stringEntry(1, 1, "x"), intEntry(0, 2)),
+		{},
+	}
+	var p Props
+	check := func(err error) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	loop := func() {
+		for i, data := range features {
+			p.Reset(schema, data)
+			p.SetFeature(i)
+			if len(data) > 0 {
+				_, err := p.GetInt(0)
+				check(err)
+				_, err = p.StringView(1)
+				check(err)
+			}
+			check(p.SetInt(0, int32(i)))
+			check(p.SetString(1, "a longer value"))
+			check(p.SetDouble(2, 1.5))
+			check(p.SetBool(3, true))
+			_, err := p.GetDouble(2)
+			check(err)
+		}
+	}
+	loop() // Grow the spare offset table and data buffer.
+	if allocs := testing.AllocsPerRun(100, loop); allocs != 0 {
+		t.Errorf("got %v allocations per Reset loop, want 0", allocs)
+	}
+}
+
+// jsonColumnSchema has a JSON column and a column of another type.
+var jsonColumnSchema = NewSchema([]Column{
+	{Name: "j", Type: flat.ColumnTypeJson},
+	{Name: "s", Type: flat.ColumnTypeString},
+})
+
+func TestSetJSON(t *testing.T) {
+	t.Run("lax", func(t *testing.T) {
+		p := NewProps(jsonColumnSchema)
+		if err := p.SetJSON(0, "{"); err != nil {
+			t.Fatalf("SetJSON: %v", err)
+		}
+		if s, err := p.GetJSON(0); err != nil || s != "{" {
+			t.Errorf("GetJSON: got %q, %v, want %q", s, err, "{")
+		}
+		var v any
+		err := p.GetJSONInto(0, &v)
+		var syntaxErr *json.SyntaxError
+		if !errors.Is(err, ErrInvalidJSON) || !errors.As(err, &syntaxErr) {
+			t.Errorf("GetJSONInto: got %v, want ErrInvalidJSON wrapping *json.SyntaxError", err)
+		}
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		p := NewProps(jsonColumnSchema, WithStrictJSON())
+		if err := p.SetJSONName("j", `{"a":1}`); err != nil {
+			t.Fatalf("SetJSONName: %v", err)
+		}
+		for _, text := range []string{"{", "", "nul", `{"a":1}}`} {
+			err := p.SetJSON(0, text)
+			var colErr *ColumnError
+			if !errors.Is(err, ErrInvalidJSON) || !errors.As(err, &colErr) || colErr.Col != 0 {
+				t.Errorf("SetJSON(%q): got %v, want ColumnError wrapping ErrInvalidJSON", text, err)
+			}
+		}
+		if s, err := p.GetJSON(0); err != nil || s != `{"a":1}` {
+			t.Errorf("value after rejected SetJSON: got %q, %v, want %q", s, err, `{"a":1}`)
+		}
+		if err := p.SetJSON(1, "{"); !errors.Is(err, ErrTypeMismatch) || errors.Is(err, ErrInvalidJSON) {
+			t.Errorf("String column: got %v, want ErrTypeMismatch only", err)
+		}
+		if err := p.SetJSONName("missing", "{"); !errors.Is(err, ErrNoColumn) {
+			t.Errorf("missing column: got %v, want ErrNoColumn", err)
+		}
+
+		// Values read from a property buffer are not checked.
+		q := PropsFromFlat(jsonColumnSchema, stringEntry(0, 1, "{"), WithStrictJSON())
+		if s, err := q.GetJSON(0); err != nil || s != "{" {
+			t.Errorf("GetJSON from buffer: got %q, %v, want %q", s, err, "{")
+		}
+	})
+}
+
+func TestSetJSONValue(t *testing.T) {
+	p := NewProps(jsonColumnSchema, WithStrictJSON())
+	type value struct {
+		A int      `json:"a"`
+		B []string `json:"b"`
+	}
+	in := value{A: 1, B: []string{"x", "y"}}
+	if err := p.SetJSONValue(0, in); err != nil {
+		t.Fatalf("SetJSONValue: %v", err)
+	}
+	if s, err := p.GetJSON(0); err != nil || s != `{"a":1,"b":["x","y"]}` {
+		t.Errorf("GetJSON: got %q, %v", s, err)
+	}
+	var out value
+	if err := p.GetJSONIntoName("j", &out); err != nil || out.A != in.A || len(out.B) != 2 || out.B[1] != "y" {
+		t.Errorf("GetJSONIntoName: got %+v, %v, want %+v", out, err, in)
+	}
+
+	// A nil value is stored as JSON null text, not as a null column.
+	if err := p.SetJSONValueName("j", nil); err != nil {
+		t.Fatalf("SetJSONValueName: %v", err)
+	}
+	if s, err := p.GetJSON(0); err != nil || s != "null" || p.IsNull(0) {
+		t.Errorf("nil: got %q, %v, IsNull %v, want %q", s, err, p.IsNull(0), "null")
+	}
+
+	for _, v := range []any{math.NaN(), make(chan int), func() {}} {
+		err := p.SetJSONValue(0, v)
+		var typeErr *TypeError
+		if !errors.Is(err, ErrTypeMismatch) || !errors.As(err, &typeErr) || typeErr.Cause == nil {
+			t.Errorf("%T: got %v, want TypeError with a json.Marshal cause", v, err)
+		}
+	}
+	if s, err := p.GetJSON(0); err != nil || s != "null" {
+		t.Errorf("value after rejected SetJSONValue: got %q, %v, want %q", s, err, "null")
+	}
+	if err := p.SetJSONValue(1, 1); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("String column: got %v, want ErrTypeMismatch", err)
+	}
+	if err := p.SetJSONValueName("missing", 1); !errors.Is(err, ErrNoColumn) {
+		t.Errorf("missing column: got %v, want ErrNoColumn", err)
+	}
+}
+
+func TestGetJSONInto(t *testing.T) {
+	p := PropsFromFlat(jsonColumnSchema, bytesJoin(stringEntry(0, 7, `{"a":1}`), stringEntry(1, 2, "{}")))
+	var m map[string]int
+	if err := p.GetJSONInto(0, &m); err != nil || m["a"] != 1 {
+		t.Errorf("map: got %v, %v, want a=1", m, err)
+	}
+
+	var s string
+	err := p.GetJSONInto(0, &s)
+	var unmarshalErr *json.UnmarshalTypeError
+	if !errors.Is(err, ErrTypeMismatch) || !errors.As(err, &unmarshalErr) {
+		t.Errorf("wrong Go type: got %v, want ErrTypeMismatch wrapping *json.UnmarshalTypeError", err)
+	}
+	if err = p.GetJSONInto(0, m); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("non-pointer: got %v, want ErrTypeMismatch", err)
+	}
+	if err = p.GetJSONInto(1, &m); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("String column: got %v, want ErrTypeMismatch", err)
+	}
+	if err = p.GetJSONIntoName("missing", &m); !errors.Is(err, ErrNoColumn) {
+		t.Errorf("missing column: got %v, want ErrNoColumn", err)
+	}
+
+	q := NewProps(jsonColumnSchema)
+	if err = q.GetJSONInto(0, &m); !errors.Is(err, ErrNoValue) {
+		t.Errorf("absent: got %v, want ErrNoValue", err)
+	}
+	if err = q.SetNull(0); err != nil {
+		t.Fatal(err)
+	}
+	if err = q.GetJSONInto(0, &m); !errors.Is(err, ErrNull) {
+		t.Errorf("null: got %v, want ErrNull", err)
+	}
+}
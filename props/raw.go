@@ -0,0 +1,174 @@
+package props
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// This file contains helpers for working directly with encoded property
+// values, without going through the typed getters and setters.
+
+// isSigned reports whether a column type is a signed integer type.
+func isSigned(t flat.ColumnType) bool {
+	return t == flat.ColumnTypeByte || t == flat.ColumnTypeShort || t == flat.ColumnTypeInt || t == flat.ColumnTypeLong
+}
+
+// isUnsigned reports whether a column type is an unsigned integer type.
+func isUnsigned(t flat.ColumnType) bool {
+	return t == flat.ColumnTypeUByte || t == flat.ColumnTypeUShort || t == flat.ColumnTypeUInt || t == flat.ColumnTypeULong
+}
+
+// isFloat reports whether a column type is a floating point type.
+func isFloat(t flat.ColumnType) bool {
+	return t == flat.ColumnTypeFloat || t == flat.ColumnTypeDouble
+}
+
+// isText reports whether a column type is a string-ish type, i.e. its
+// values are prefixed with a 32-bit length.
+func isText(t flat.ColumnType) bool {
+	return t == flat.ColumnTypeString || t == flat.ColumnTypeJson || t == flat.ColumnTypeBinary || t == flat.ColumnTypeDateTime
+}
+
+// rawInt64 decodes an encoded signed or unsigned integer value. The
+// result is only meaningful for unsigned 64-bit values which fit in
+// an int64.
+func rawInt64(t flat.ColumnType, b []byte) int64 {
+	switch t {
+	case flat.ColumnTypeByte:
+		return int64(int8(b[0]))
+	case flat.ColumnTypeUByte, flat.ColumnTypeBool:
+		return int64(b[0])
+	case flat.ColumnTypeShort:
+		return int64(flatbuffers.GetInt16(b))
+	case flat.ColumnTypeUShort:
+		return int64(flatbuffers.GetUint16(b))
+	case flat.ColumnTypeInt:
+		return int64(flatbuffers.GetInt32(b))
+	case flat.ColumnTypeUInt:
+		return int64(flatbuffers.GetUint32(b))
+	case flat.ColumnTypeLong:
+		return flatbuffers.GetInt64(b)
+	case flat.ColumnTypeULong:
+		return int64(flatbuffers.GetUint64(b))
+	default:
+		return 0
+	}
+}
+
+// rawUint64 decodes an encoded unsigned integer value.
+func rawUint64(t flat.ColumnType, b []byte) uint64 {
+	if t == flat.ColumnTypeULong {
+		return flatbuffers.GetUint64(b)
+	}
+	return uint64(rawInt64(t, b))
+}
+
+// rawFloat64 decodes an encoded numeric value as a float64.
+func rawFloat64(t flat.ColumnType, b []byte) float64 {
+	switch t {
+	case flat.ColumnTypeFloat:
+		return float64(flatbuffers.GetFloat32(b))
+	case flat.ColumnTypeDouble:
+		return flatbuffers.GetFloat64(b)
+	case flat.ColumnTypeULong:
+		return float64(flatbuffers.GetUint64(b))
+	default:
+		return float64(rawInt64(t, b))
+	}
+}
+
+// convertRaw writes the encoded value raw, of column type from, into
+// dst as a value of column type to, which must be widened by from (see
+// Widens). dst must have room for the converted value, whose size is
+// returned by convertedSize.
+func convertRaw(dst []byte, from, to flat.ColumnType, raw []byte) {
+	switch {
+	case from == to || isText(to):
+		copy(dst, raw)
+	case to == flat.ColumnTypeFloat:
+		flatbuffers.WriteFloat32(dst, float32(rawFloat64(from, raw)))
+	case to == flat.ColumnTypeDouble:
+		flatbuffers.WriteFloat64(dst, rawFloat64(from, raw))
+	case to == flat.ColumnTypeShort:
+		flatbuffers.WriteInt16(dst, int16(rawInt64(from, raw)))
+	case to == flat.ColumnTypeUShort:
+		flatbuffers.WriteUint16(dst, uint16(rawUint64(from, raw)))
+	case to == flat.ColumnTypeInt:
+		flatbuffers.WriteInt32(dst, int32(rawInt64(from, raw)))
+	case to == flat.ColumnTypeUInt:
+		flatbuffers.WriteUint32(dst, uint32(rawUint64(from, raw)))
+	case to == flat.ColumnTypeLong:
+		flatbuffers.WriteInt64(dst, rawInt64(from, raw))
+	case to == flat.ColumnTypeULong:
+		flatbuffers.WriteUint64(dst, rawUint64(from, raw))
+	}
+}
+
+// convertedSize returns the encoded size of the value raw, of column
+// type from, once converted to column type to.
+func convertedSize(from, to flat.ColumnType, raw []byte) int {
+	if from == to || isText(to) {
+		return len(raw)
+	}
+	return fixedSize(to)
+}
+
+// fixedSize returns the encoded size of a value of a fixed size column
+// type, or zero for string-ish column types.
+func fixedSize(t flat.ColumnType) int {
+	switch t {
+	case flat.ColumnTypeBool, flat.ColumnTypeByte, flat.ColumnTypeUByte:
+		return 1
+	case flat.ColumnTypeShort, flat.ColumnTypeUShort:
+		return 2
+	case flat.ColumnTypeInt, flat.ColumnTypeUInt, flat.ColumnTypeFloat:
+		return 4
+	case flat.ColumnTypeLong, flat.ColumnTypeULong, flat.ColumnTypeDouble:
+		return 8
+	default:
+		return 0
+	}
+}
+
+// valueSize returns the encoded size of a value of column type t at the
+// start of b, including the length prefix for string-ish column types.
+func valueSize(t flat.ColumnType, b []byte) (int, error) {
+	if sz := fixedSize(t); sz > 0 {
+		return sz, nil
+	} else if !isText(t) {
+		return 0, errUnknownColumnType
+	} else if len(b) < flatbuffers.SizeUint32 {
+		return 0, errStringSizeCorrupt
+	}
+	n := uint64(flatbuffers.GetUint32(b))
+	if n > math.MaxInt-flatbuffers.SizeUint32 {
+		return 0, errStringSizeOverflowsInt
+	}
+	return int(n) + flatbuffers.SizeUint32, nil
+}
+
+// nextEntry decodes the header of the property buffer entry which starts
+// at offset in data, under a schema with n columns whose types are
+// given by typeOf. It returns the column index, and the offset and size
+// of the value, which are checked to lie within data.
+func nextEntry(data []byte, offset, n int, typeOf func(col int) flat.ColumnType) (col, valueOffset, size int, err *ParseError) {
+	if len(data)-offset < flatbuffers.SizeUint16 {
+		return 0, 0, 0, &ParseError{Feature: -1, Offset: offset, Col: -1, Reason: "truncated column index"}
+	}
+	col = int(flatbuffers.GetUint16(data[offset:]))
+	if col >= n {
+		return 0, 0, 0, &ParseError{Feature: -1, Offset: offset, Col: col, Reason: fmt.Sprintf("column index out of range for %d columns", n)}
+	}
+	valueOffset = offset + flatbuffers.SizeUint16
+	size, sizeErr := valueSize(typeOf(col), data[valueOffset:])
+	if sizeErr != nil {
+		return 0, 0, 0, &ParseError{Feature: -1, Offset: valueOffset, Col: col, Reason: strings.TrimPrefix(sizeErr.Error(), packageName)}
+	} else if size > len(data)-valueOffset {
+		return 0, 0, 0, &ParseError{Feature: -1, Offset: valueOffset, Col: col, Reason: fmt.Sprintf("value of %d bytes is truncated", size)}
+	}
+	return col, valueOffset, size, nil
+}
@@ -1,6 +1,11 @@
 package props
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
 	"github.com/gogama/flatgeobuf-convert/interop"
 	"github.com/gogama/flatgeobuf/flatgeobuf"
 	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
@@ -12,40 +17,198 @@ var (
 	_ flatgeobuf.Schema = &Schema{}
 )
 
+// Schema is a property schema: an ordered list of columns.
+//
+// A Schema is immutable once constructed, and is safe for concurrent
+// use by multiple goroutines.
 type Schema struct {
-	cols       []Column
+	cols []Column
+	// name2Index maps column names to column indexes. It is only built
+	// for schemas with at least name2IndexThreshold columns. For
+	// smaller schemas, a linear search is faster.
 	name2Index map[string]int
+	// flatBytes is a finished FlatGeobuf buffer containing one
+	// flat.Column table per column, and flatPos contains the position
+	// of each column's table within the buffer.
+	flatBytes []byte
+	flatPos   []flatbuffers.UOffsetT
 }
 
 func SchemaFromFlat(obj flatgeobuf.Schema) (schema *Schema, err error) {
 	var cols []Column
 	err = interop.FlatBufferSafe(func() error {
+		// The length of a corrupt columns vector may be far larger than
+		// its buffer, so grow cols as the columns are read rather than
+		// allocating them all up front.
 		n := obj.ColumnsLength()
-		cols = make([]Column, n)
 		var col flat.Column
-		for i := range cols {
+		for i := 0; i < n; i++ {
 			if !obj.Columns(&col, i) {
-				// FIXME: Missing indicated column: return error
+				return fmtErr("missing column %d of %d", i, n)
 			}
-			var err error
-			if cols[i], err = ColumnFromFlat(&col); err != nil {
+			c, err := ColumnFromFlat(&col)
+			if err != nil {
 				return err
 			}
+			cols = append(cols, c)
 		}
 		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &Schema{
-		cols: cols,
-	}, nil
+	return newSchema(cols), nil
 }
 
+// NewSchema creates a Schema containing a copy of cols.
+//
+// NewSchema does not check the columns. Use SchemaBuilder to create a
+// Schema whose structure is checked.
 func NewSchema(cols []Column) *Schema {
-	return &Schema{
-		cols: cols,
+	return newSchema(cols)
+}
+
+// emptySchema is the schema with no columns which stands in for a nil
+// schema.
+var emptySchema = newSchema(nil)
+
+// orEmpty returns s, or emptySchema if s is nil.
+func orEmpty(s *Schema) *Schema {
+	if s == nil {
+		return emptySchema
+	}
+	return s
+}
+
+// flatOrEmpty returns schema, or emptySchema if schema is nil or holds a
+// nil *Schema.
+func flatOrEmpty(schema flatgeobuf.Schema) flatgeobuf.Schema {
+	if s, ok := schema.(*Schema); schema == nil || ok && s == nil {
+		return emptySchema
+	}
+	return schema
+}
+
+// newSchema creates a Schema holding a copy of cols. Everything the
+// Schema needs is computed up front, so that the Schema is never
+// written to after it is returned.
+func newSchema(cols []Column) *Schema {
+	s := &Schema{
+		cols: make([]Column, len(cols)),
+	}
+	copy(s.cols, cols)
+	if len(s.cols) >= name2IndexThreshold {
+		s.name2Index = make(map[string]int, len(s.cols))
+		for i := len(s.cols) - 1; i >= 0; i-- {
+			s.name2Index[s.cols[i].Name] = i // Reverse order so first duplicate wins.
+		}
+	}
+	if len(s.cols) > 0 {
+		b := flatbuffers.NewBuilder(64 * len(s.cols))
+		offsets := make([]flatbuffers.UOffsetT, len(s.cols))
+		for i := range s.cols {
+			offsets[i] = s.cols[i].ToBuilder(b)
+		}
+		b.Finish(offsets[len(offsets)-1])
+		s.flatBytes = b.FinishedBytes()
+		s.flatPos = make([]flatbuffers.UOffsetT, len(s.cols))
+		for i := range offsets {
+			s.flatPos[i] = flatbuffers.UOffsetT(len(s.flatBytes)) - offsets[i]
+		}
+	}
+	return s
+}
+
+// schemaJSON is the JSON form of a Schema.
+type schemaJSON struct {
+	Columns []Column `json:"columns"`
+}
+
+// SchemaFromJSON loads a Schema from its JSON form, as written by
+// Schema.MarshalJSON, and checks the structure of the schema in the same
+// way as SchemaBuilder.Build.
+//
+// The JSON document is a single object with a "columns" member holding
+// an array of columns. For example:
+//
+//	{
+//	  "columns": [
+//	    {"name": "id", "type": "Long", "primaryKey": true},
+//	    {"name": "name", "type": "String", "width": 64, "required": true}
+//	  ]
+//	}
+//
+// Unknown members are not allowed. Column attributes which are missing
+// take their zero value, except that every column must have a type.
+func SchemaFromJSON(data []byte) (*Schema, error) {
+	cols, err := decodeSchemaJSON(data, true)
+	if err != nil {
+		return nil, err
 	}
+	return newSchema(cols), nil
+}
+
+// decodeSchemaJSON decodes and checks the columns of the JSON form of
+// a Schema. If strict is true, unknown members are not allowed.
+func decodeSchemaJSON(data []byte, strict bool) ([]Column, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+	// Decode into columnJSON rather than Column, since the decoder's
+	// strictness does not carry through to Column.UnmarshalJSON.
+	var v struct {
+		Columns []columnJSON `json:"columns"`
+	}
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSchema, err)
+	} else if dec.More() {
+		return nil, fmt.Errorf("%w: extra data after JSON schema", ErrInvalidSchema)
+	} else if v.Columns == nil {
+		return nil, fmt.Errorf("%w: missing columns member", ErrInvalidSchema)
+	}
+	cols := make([]Column, len(v.Columns))
+	var errs []error
+	for i := range v.Columns {
+		var ok bool
+		if cols[i], ok = v.Columns[i].column(); !ok {
+			errs = append(errs, fmt.Errorf("%w: column %d (%q) has invalid type name: %q", ErrInvalidSchema, i, v.Columns[i].Name, v.Columns[i].Type))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	} else if errs = checkColumns(cols); len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return cols, nil
+}
+
+// MarshalJSON implements json.Marshaler, writing the schema in the form
+// read by SchemaFromJSON. The output is stable: equal schemas always
+// produce identical JSON.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	cols := s.cols
+	if cols == nil {
+		cols = []Column{}
+	}
+	return json.Marshal(schemaJSON{Columns: cols})
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It reads and checks the
+// schema in the same way as SchemaFromJSON, except that unknown members
+// are ignored. The settings of a json.Decoder, such as
+// DisallowUnknownFields, do not carry through to UnmarshalJSON, so use
+// SchemaFromJSON to reject unknown members.
+//
+// Because a Schema is immutable once constructed, UnmarshalJSON must
+// only be called on a new, zero-valued Schema.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	cols, err := decodeSchemaJSON(data, false)
+	if err != nil {
+		return err
+	}
+	*s = *newSchema(cols)
+	return nil
 }
 
 const name2IndexThreshold = 6
@@ -53,20 +216,12 @@ const name2IndexThreshold = 6
 func (s *Schema) Index(name string) (index int, ok bool) {
 	if s.name2Index != nil {
 		index, ok = s.name2Index[name]
-	} else if len(s.cols) < name2IndexThreshold {
-		for i := range s.cols {
-			if s.cols[i].Name == name {
-				index, ok = i, true
-				break
-			}
-		}
 	} else {
-		s.name2Index = make(map[string]int, len(s.cols))
 		for i := range s.cols {
 			if s.cols[i].Name == name {
 				index, ok = i, true
+				break
 			}
-			s.name2Index[s.cols[i].Name] = i
 		}
 	}
 	return
@@ -98,13 +253,13 @@ func (s *Schema) ColumnsLength() int {
 	return len(s.cols)
 }
 
+// Columns implements flatgeobuf.Schema. On success, obj refers to a
+// flat.Column table cached within the Schema, which must not be mutated.
 func (s *Schema) Columns(obj *flat.Column, j int) bool {
-	if j < 0 || j >= len(s.cols) {
+	if j < 0 || j >= len(s.flatPos) {
 		return false
 	}
-	b := flatbuffers.NewBuilder(64)
-	offset := s.cols[j].ToBuilder(b)
-	obj.Init(b.FinishedBytes(), offset)
+	obj.Init(s.flatBytes, s.flatPos[j])
 	return true
 }
 
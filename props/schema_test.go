@@ -0,0 +1,221 @@
+package props
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+)
+
+func TestSchemaJSON(t *testing.T) {
+	want := []Column{
+		{Name: "id", Type: flat.ColumnTypeLong, PrimaryKey: true},
+		{Name: "name", Type: flat.ColumnTypeString, Width: 64, Required: true},
+	}
+	testCases := []struct {
+		name string
+		data string
+		// strict and lenient are whether SchemaFromJSON and
+		// UnmarshalJSON, respectively, accept data.
+		strict  bool
+		lenient bool
+	}{
+		{
+			name:    "valid",
+			data:    `{"columns":[{"name":"id","type":"Long","primaryKey":true},{"name":"name","type":"String","width":64,"required":true}]}`,
+			strict:  true,
+			lenient: true,
+		},
+		{
+			name:    "unknown members",
+			data:    `{"version":2,"columns":[{"name":"id","type":"Long","primaryKey":true,"x":1},{"name":"name","type":"String","width":64,"required":true}]}`,
+			strict:  false,
+			lenient: true,
+		},
+		{
+			name: "missing columns",
+			data: `{}`,
+		},
+		{
+			name: "null columns",
+			data: `{"columns":null}`,
+		},
+		{
+			name: "invalid type name",
+			data: `{"columns":[{"name":"id","type":"Huge"}]}`,
+		},
+		{
+			name: "duplicate names",
+			data: `{"columns":[{"name":"id","type":"Long"},{"name":"id","type":"Int"}]}`,
+		},
+		{
+			name: "not an object",
+			data: `[]`,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			check := func(t *testing.T, s *Schema, err error, ok bool) {
+				t.Helper()
+				if !ok {
+					if !errors.Is(err, ErrInvalidSchema) {
+						t.Errorf("got error %v, want ErrInvalidSchema", err)
+					}
+					return
+				} else if err != nil {
+					t.Fatalf("got error %v", err)
+				}
+				var got []Column
+				for i := 0; i < s.ColumnsLength(); i++ {
+					got = append(got, s.Column(i))
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("got columns %+v, want %+v", got, want)
+				}
+			}
+			t.Run("SchemaFromJSON", func(t *testing.T) {
+				s, err := SchemaFromJSON([]byte(testCase.data))
+				check(t, s, err, testCase.strict)
+			})
+			t.Run("UnmarshalJSON", func(t *testing.T) {
+				var s Schema
+				err := json.Unmarshal([]byte(testCase.data), &s)
+				check(t, &s, err, testCase.lenient)
+			})
+		})
+	}
+}
+
+func TestSchemaJSONRoundTrip(t *testing.T) {
+	s := NewSchema([]Column{
+		{Name: "when", Type: flat.ColumnTypeDateTime, Title: "When", Metadata: `{"tz":"UTC"}`},
+		{Name: "amount", Type: flat.ColumnTypeDouble, Precision: 10, Scale: 2, Unique: true},
+	})
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	got, err := SchemaFromJSON(b)
+	if err != nil {
+		t.Fatalf("SchemaFromJSON: %v", err)
+	}
+	for i := 0; i < s.ColumnsLength(); i++ {
+		if got.Column(i) != s.Column(i) {
+			t.Errorf("column %d: got %+v, want %+v", i, got.Column(i), s.Column(i))
+		}
+	}
+	if b, err = json.Marshal(NewSchema(nil)); err != nil || string(b) != `{"columns":[]}` {
+		t.Errorf("got %s, %v for empty schema", b, err)
+	}
+}
+
+// cachedColumns returns n columns, all but one with every attribute
+// set. Each column reads back unchanged from its flat.Column table.
+func cachedColumns(n int) []Column {
+	cols := make([]Column, n)
+	for i := range cols {
+		cols[i] = Column{
+			Name:        "col" + strconv.Itoa(i),
+			Type:        flat.ColumnType(i % (int(flat.ColumnTypeBinary) + 1)),
+			Title:       "Column " + strconv.Itoa(i),
+			Description: "Description " + strconv.Itoa(i),
+			Width:       int32(i + 1),
+			Precision:   int32(i + 1),
+			Scale:       int32(i + 2),
+			Required:    i%2 == 0,
+			Unique:      i%3 == 0,
+			PrimaryKey:  i == 0,
+			Metadata:    `{"i":` + strconv.Itoa(i) + `}`,
+		}
+	}
+	if n > 1 {
+		cols[1] = Column{Name: "bare", Width: -1, Precision: -1, Scale: -1}
+	}
+	return cols
+}
+
+func TestSchemaColumns(t *testing.T) {
+	for _, n := range []int{0, 1, name2IndexThreshold - 1, name2IndexThreshold, 20} {
+		t.Run(strconv.Itoa(n), func(t *testing.T) {
+			cols := cachedColumns(n)
+			schema := NewSchema(cols)
+			var obj flat.Column
+			for j := range cols {
+				if !schema.Columns(&obj, j) {
+					t.Fatalf("Columns(%d): got false, want true", j)
+				}
+				got, err := ColumnFromFlat(&obj)
+				if err != nil {
+					t.Fatalf("ColumnFromFlat(%d): %v", j, err)
+				}
+				if got != cols[j] {
+					t.Errorf("column %d: got %+v, want %+v", j, got, cols[j])
+				}
+			}
+			for _, j := range []int{-1, n} {
+				if schema.Columns(&obj, j) {
+					t.Errorf("Columns(%d): got true, want false", j)
+				}
+			}
+
+			// The cached tables agree with the columns vector written
+			// by ToBuilder.
+			other, err := SchemaFromFlat(flatSchema(t, cols...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(other.cols, schema.cols) {
+				t.Errorf("SchemaFromFlat: got %+v, want %+v", other.cols, schema.cols)
+			}
+		})
+	}
+}
+
+// TestSchemaConcurrentReads is meant to be run with -race. It reads one
+// Schema from several goroutines, both directly and through Props.
+func TestSchemaConcurrentReads(t *testing.T) {
+	cols := cachedColumns(20)
+	schema := NewSchema(cols)
+	data := bytesJoin(intEntry(5, 1), stringEntry(11, 1, "x"))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for g := 0; g < cap(errs); g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var obj flat.Column
+			for i := 0; i < 100; i++ {
+				for j := range cols {
+					if !schema.Columns(&obj, j) || string(obj.Name()) != cols[j].Name {
+						errs <- fmt.Errorf("Columns(%d): got %q, want %q", j, obj.Name(), cols[j].Name)
+						return
+					}
+					if k, ok := schema.Index(cols[j].Name); !ok || k != j {
+						errs <- fmt.Errorf("Index(%q): got %d, %v, want %d", cols[j].Name, k, ok, j)
+						return
+					}
+					if schema.Type(j) != cols[j].Type || schema.Name(j) != cols[j].Name {
+						errs <- fmt.Errorf("column %d: got %s %q", j, schema.Type(j), schema.Name(j))
+						return
+					}
+				}
+				p := PropsFromFlat(schema, data)
+				if v, err := p.GetIntName("col5"); err != nil || v != 1 {
+					errs <- fmt.Errorf("GetIntName: got %v, %v, want 1", v, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
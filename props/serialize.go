@@ -0,0 +1,102 @@
+package props
+
+import (
+	"sort"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// Bytes returns the property values serialized in FlatGeobuf property
+// buffer format, suitable for the Properties field of a flat.Feature.
+//
+// By default, values are written in ascending column order, and Bool
+// values are normalized to zero or one, so that Props with equal
+// contents produce byte-identical output which can be hashed or
+// compared. Use the WithInsertionOrder option to keep values in storage
+// order instead. Absent and null values are both omitted, since the
+// FlatGeobuf property format represents null by omission.
+//
+// The returned slice is a copy which the caller may retain. The error
+// return value is non-nil if the underlying property buffer is corrupt.
+func (p *Props) Bytes() ([]byte, error) {
+	return p.AppendBytes(nil)
+}
+
+// AppendBytes appends the property values serialized in FlatGeobuf
+// property buffer format to dst, in the same way as Bytes, and returns
+// the extended buffer.
+func (p *Props) AppendBytes(dst []byte) ([]byte, error) {
+	n := p.numColumns()
+	if n == 0 {
+		return dst, nil
+	}
+	if _, err := p.col2Offset(0); err != nil {
+		return dst, err
+	}
+	if p.opts.insertionOrder {
+		return p.appendStorageOrder(dst)
+	}
+	for col := 0; col < n; col++ {
+		raw, err := p.rawValue(col)
+		if err != nil {
+			return dst, err
+		} else if raw == nil {
+			continue
+		}
+		dst = appendHeader(dst, col)
+		if p.columnType(col) == flat.ColumnTypeBool && raw[0] > 1 {
+			dst = append(dst, 1)
+		} else {
+			dst = append(dst, raw...)
+		}
+	}
+	return dst, nil
+}
+
+func (p *Props) appendStorageOrder(dst []byte) ([]byte, error) {
+	if !p.mutable {
+		// The buffer may contain values the offset table ignored, for
+		// example values for columns not in the schema, so only copy
+		// values with an offset.
+		return p.appendByOffset(dst)
+	}
+	return append(dst, p.data.Bytes()...), nil
+}
+
+func (p *Props) appendByOffset(dst []byte) ([]byte, error) {
+	n := p.numColumns()
+	order := make([]int, 0, n)
+	for col := 0; col < n; col++ {
+		if p.offset[col] > 0 {
+			order = append(order, col)
+		}
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return p.offset[order[i]] < p.offset[order[j]]
+	})
+	for _, col := range order {
+		raw, err := p.rawValue(col)
+		if err != nil {
+			return dst, err
+		}
+		dst = appendHeader(dst, col)
+		dst = append(dst, raw...)
+	}
+	return dst, nil
+}
+
+// ToBuilder serializes the property values, as for Bytes, into a byte
+// vector in a flatbuffers.Builder and returns its offset, suitable for
+// passing to flat.FeatureAddProperties.
+func (p *Props) ToBuilder(b *flatbuffers.Builder) (flatbuffers.UOffsetT, error) {
+	data, err := p.Bytes()
+	if err != nil {
+		return 0, err
+	}
+	return b.CreateByteVector(data), nil
+}
+
+func appendHeader(dst []byte, col int) []byte {
+	return append(dst, byte(col), byte(col>>8))
+}
@@ -0,0 +1,165 @@
+package props
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// serializeSchema is the schema the tests of Bytes build Props under.
+var serializeSchema = NewSchema([]Column{
+	{Name: "a", Type: flat.ColumnTypeInt},
+	{Name: "b", Type: flat.ColumnTypeString},
+	{Name: "c", Type: flat.ColumnTypeBinary},
+	{Name: "d", Type: flat.ColumnTypeBool},
+})
+
+// binaryEntry returns a property buffer entry for a Binary column.
+func binaryEntry(col int, v []byte) []byte {
+	return stringEntry(col, uint32(len(v)), string(v))
+}
+
+// boolEntry returns a property buffer entry for a Bool column holding
+// the raw byte v.
+func boolEntry(col int, v byte) []byte {
+	return append(appendHeader(nil, col), v)
+}
+
+func TestBytesCanonicalOrder(t *testing.T) {
+	set := []func(p *Props) error{
+		func(p *Props) error { return p.SetInt(0, 5) },
+		func(p *Props) error { return p.SetString(1, "xy") },
+		func(p *Props) error { return p.SetBinary(2, []byte{9}) },
+		func(p *Props) error { return p.SetBool(3, true) },
+	}
+	want := bytes.Join([][]byte{intEntry(0, 5), stringEntry(1, 2, "xy"), binaryEntry(2, []byte{9}), boolEntry(3, 1)}, nil)
+	for _, order := range [][]int{{0, 1, 2, 3}, {3, 2, 1, 0}, {2, 0, 3, 1}} {
+		p := NewProps(serializeSchema)
+		for _, i := range order {
+			if err := set[i](p); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := mustBytes(t, p); !bytes.Equal(got, want) {
+			t.Errorf("order %v: got %v, want %v", order, got, want)
+		}
+	}
+}
+
+func TestBytesInsertionOrder(t *testing.T) {
+	p := NewProps(serializeSchema, WithInsertionOrder())
+	if err := p.SetBool(3, false); err != nil {
+		t.Fatal(err)
+	} else if err = p.SetInt(0, 5); err != nil {
+		t.Fatal(err)
+	}
+	want := bytes.Join([][]byte{boolEntry(3, 0), intEntry(0, 5)}, nil)
+	if got := mustBytes(t, p); !bytes.Equal(got, want) {
+		t.Errorf("mutable: got %v, want %v", got, want)
+	}
+
+	// An immutable buffer keeps its storage order, but values ignored by
+	// the offset table, here the first of two values for column 0, are
+	// dropped.
+	data := bytes.Join([][]byte{intEntry(0, 2), stringEntry(1, 1, "x"), intEntry(0, 3)}, nil)
+	p = PropsFromFlat(serializeSchema, data, WithInsertionOrder())
+	want = bytes.Join([][]byte{stringEntry(1, 1, "x"), intEntry(0, 3)}, nil)
+	if got := mustBytes(t, p); !bytes.Equal(got, want) {
+		t.Errorf("immutable: got %v, want %v", got, want)
+	}
+	p = PropsFromFlat(serializeSchema, data)
+	want = bytes.Join([][]byte{intEntry(0, 3), stringEntry(1, 1, "x")}, nil)
+	if got := mustBytes(t, p); !bytes.Equal(got, want) {
+		t.Errorf("canonical: got %v, want %v", got, want)
+	}
+}
+
+func TestBytesBoolNormalized(t *testing.T) {
+	data := bytes.Join([][]byte{boolEntry(3, 7), intEntry(0, 1)}, nil)
+	p := PropsFromFlat(serializeSchema, data)
+	if v, err := p.GetBool(3); err != nil || !v {
+		t.Fatalf("GetBool: got %t, %v, want true", v, err)
+	}
+	want := bytes.Join([][]byte{intEntry(0, 1), boolEntry(3, 1)}, nil)
+	if got := mustBytes(t, p); !bytes.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestBytesAfterResize(t *testing.T) {
+	p := NewProps(serializeSchema)
+	steps := []func() error{
+		func() error { return p.SetBinary(2, []byte{1, 2}) },
+		func() error { return p.SetString(1, "before") },
+		func() error { return p.SetInt(0, 4) },
+		func() error { return p.SetBinary(2, []byte{1, 2, 3, 4, 5, 6, 7, 8}) },
+		func() error { return p.SetString(1, "x") },
+		func() error { return p.SetBinary(2, []byte{3}) },
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+	}
+	want := bytes.Join([][]byte{intEntry(0, 4), stringEntry(1, 1, "x"), binaryEntry(2, []byte{3})}, nil)
+	got := mustBytes(t, p)
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	q := PropsFromFlat(serializeSchema, got, WithParseMode(ParseStrict))
+	if b, err := q.GetBinary(2); err != nil || !bytes.Equal(b, []byte{3}) {
+		t.Errorf("GetBinary after round trip: got %v, %v", b, err)
+	}
+}
+
+func TestAppendBytes(t *testing.T) {
+	p := NewProps(serializeSchema)
+	if got, err := p.AppendBytes([]byte("pre")); err != nil || string(got) != "pre" {
+		t.Errorf("empty: got %q, %v, want \"pre\"", got, err)
+	}
+	if err := p.SetInt(0, 6); err != nil {
+		t.Fatal(err)
+	}
+	want := append([]byte("pre"), intEntry(0, 6)...)
+	if got, err := p.AppendBytes([]byte("pre")); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("got %v, %v, want %v", got, err, want)
+	}
+	if got, err := NewProps(nil).AppendBytes([]byte("pre")); err != nil || string(got) != "pre" {
+		t.Errorf("no columns: got %q, %v, want \"pre\"", got, err)
+	}
+}
+
+func TestPropsToBuilder(t *testing.T) {
+	p := NewProps(serializeSchema)
+	if err := p.SetString(1, "z"); err != nil {
+		t.Fatal(err)
+	} else if err = p.SetInt(0, 2); err != nil {
+		t.Fatal(err)
+	}
+	b := flatbuffers.NewBuilder(0)
+	v, err := p.ToBuilder(b)
+	if err != nil {
+		t.Fatalf("ToBuilder: %v", err)
+	}
+	flat.FeatureStart(b)
+	flat.FeatureAddProperties(b, v)
+	b.Finish(flat.FeatureEnd(b))
+	feature := flat.GetRootAsFeature(b.FinishedBytes(), 0)
+	if got, want := feature.PropertiesBytes(), mustBytes(t, p); !bytes.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestBytesCorrupt(t *testing.T) {
+	data := stringEntry(1, 10, "x")
+	p := PropsFromFlat(serializeSchema, data, WithParseMode(ParseStrict))
+	if _, err := p.Bytes(); err == nil {
+		t.Error("Bytes: got nil error for corrupt buffer")
+	}
+	b := flatbuffers.NewBuilder(0)
+	if _, err := p.ToBuilder(b); err == nil {
+		t.Error("ToBuilder: got nil error for corrupt buffer")
+	}
+}
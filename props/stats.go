@@ -0,0 +1,524 @@
+package props
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// Stats accumulates per-column statistics over the properties of a
+// stream of features, such as all the features written to a FlatGeobuf
+// file. Feed it one Props per feature with Add, then take a
+// StatsSummary, which can be stored in the FlatGeobuf header metadata
+// so consumers can profile the attributes of a file without scanning
+// it.
+//
+// Stats does not keep every value it sees: distinct counts are
+// estimated with HyperLogLog, and the most frequent values are found
+// with the Space-Saving algorithm, so both are approximate. Memory use
+// per column does not grow with the number of features, but the
+// Space-Saving counters hold whole values, so for String, Json, and
+// Binary columns it grows with the length of the longest values.
+//
+// Stats is not safe for concurrent use.
+type Stats struct {
+	schema   *Schema
+	topN     int
+	features int64
+	cols     []columnStats
+	// raws holds the values of the Props being added.
+	raws [][]byte
+}
+
+// columnStats accumulates the statistics of one column.
+type columnStats struct {
+	t              flat.ColumnType
+	count          int64
+	hasMinMax      bool
+	minI, maxI     int64
+	minU, maxU     uint64
+	minF, maxF     float64
+	minT, maxT     time.Time
+	minS, maxS     string // DateTime text of minT and maxT.
+	minLen, maxLen int
+	hll            *hyperLogLog
+	top            *spaceSaving
+}
+
+// NewStats creates an empty Stats for properties under schema, which
+// reports the topN most frequent values of each column. A topN of zero
+// disables tracking of frequent values.
+func NewStats(schema *Schema, topN int) *Stats {
+	schema = orEmpty(schema)
+	s := &Stats{
+		schema: schema,
+		topN:   topN,
+		cols:   make([]columnStats, schema.ColumnsLength()),
+		raws:   make([][]byte, schema.ColumnsLength()),
+	}
+	for col := range s.cols {
+		c := &s.cols[col]
+		c.t = schema.Type(col)
+		c.hll = new(hyperLogLog)
+		if topN > 0 {
+			c.top = newSpaceSaving(4 * topN)
+		}
+	}
+	return s
+}
+
+// Add adds the values of one feature to the statistics. The schema of p
+// must have the same column types as the schema of the Stats.
+//
+// Add returns an error, and leaves the statistics unchanged, if the
+// column types don't match or if the property buffer of p is corrupt.
+func (s *Stats) Add(p *Props) error {
+	if p.numColumns() != len(s.cols) {
+		return fmt.Errorf("%w: props has %d columns but stats schema has %d", ErrSchemaMismatch, p.numColumns(), len(s.cols))
+	}
+	for col := range s.cols {
+		if t := p.columnType(col); t != s.cols[col].t {
+			return &TypeError{Feature: p.feature, Col: col, Name: p.columnName(col), Expected: s.cols[col].t, Actual: t}
+		}
+		raw, err := p.rawValue(col)
+		if err != nil {
+			return err
+		}
+		s.raws[col] = raw
+	}
+	s.features++
+	for col, raw := range s.raws {
+		if raw != nil {
+			s.cols[col].add(raw)
+		}
+		s.raws[col] = nil
+	}
+	return nil
+}
+
+func (c *columnStats) add(raw []byte) {
+	if c.t == flat.ColumnTypeBool && raw[0] > 1 {
+		raw = rawTrue
+	}
+	c.count++
+	c.hll.add(raw)
+	if c.top != nil && !(isFloat(c.t) && !isFinite(rawFloat64(c.t, raw))) {
+		c.top.add(raw) // Non-finite values have no JSON form.
+	}
+	switch {
+	case isSigned(c.t):
+		v := rawInt64(c.t, raw)
+		if !c.hasMinMax || v < c.minI {
+			c.minI = v
+		}
+		if !c.hasMinMax || v > c.maxI {
+			c.maxI = v
+		}
+		c.hasMinMax = true
+	case isUnsigned(c.t):
+		v := rawUint64(c.t, raw)
+		if !c.hasMinMax || v < c.minU {
+			c.minU = v
+		}
+		if !c.hasMinMax || v > c.maxU {
+			c.maxU = v
+		}
+		c.hasMinMax = true
+	case isFloat(c.t):
+		v := rawFloat64(c.t, raw)
+		if !isFinite(v) {
+			break
+		}
+		if !c.hasMinMax || v < c.minF {
+			c.minF = v
+		}
+		if !c.hasMinMax || v > c.maxF {
+			c.maxF = v
+		}
+		c.hasMinMax = true
+	case c.t == flat.ColumnTypeDateTime:
+		text := viewString(raw[flatbuffers.SizeUint32:])
+		v, err := ParseDateTime(text)
+		if err != nil {
+			break
+		}
+		if !c.hasMinMax || v.Time.Before(c.minT) {
+			c.minT, c.minS = v.Time, strings.Clone(text)
+		}
+		if !c.hasMinMax || v.Time.After(c.maxT) {
+			c.maxT, c.maxS = v.Time, strings.Clone(text)
+		}
+		c.hasMinMax = true
+	case c.t == flat.ColumnTypeString || c.t == flat.ColumnTypeJson || c.t == flat.ColumnTypeBinary:
+		n := len(raw) - flatbuffers.SizeUint32
+		if c.t != flat.ColumnTypeBinary {
+			n = utf8.RuneCount(raw[flatbuffers.SizeUint32:])
+		}
+		if c.count == 1 || n < c.minLen {
+			c.minLen = n
+		}
+		if c.count == 1 || n > c.maxLen {
+			c.maxLen = n
+		}
+	}
+}
+
+// rawTrue is the normalized encoding of a true Bool value.
+var rawTrue = []byte{1}
+
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+// Features returns the number of features added.
+func (s *Stats) Features() int64 {
+	return s.features
+}
+
+// Summary returns the statistics of every column, based on the features
+// added so far.
+func (s *Stats) Summary() StatsSummary {
+	summary := StatsSummary{
+		Features: s.features,
+		Columns:  make([]ColumnStats, len(s.cols)),
+	}
+	for col := range s.cols {
+		c := &s.cols[col]
+		cs := ColumnStats{
+			Name:      s.schema.Name(col),
+			Type:      c.t,
+			Count:     c.count,
+			NullCount: s.features - c.count,
+			MinLength: c.minLen,
+			MaxLength: c.maxLen,
+			Distinct:  c.hll.estimate(),
+		}
+		if c.hasMinMax {
+			switch {
+			case isSigned(c.t):
+				cs.Min, cs.Max = c.minI, c.maxI
+			case isUnsigned(c.t):
+				cs.Min, cs.Max = c.minU, c.maxU
+			case isFloat(c.t):
+				cs.Min, cs.Max = c.minF, c.maxF
+			default:
+				cs.Min, cs.Max = c.minS, c.maxS
+			}
+		}
+		if c.top != nil {
+			cs.Top = c.top.top(s.topN, c.t)
+		}
+		summary.Columns[col] = cs
+	}
+	return summary
+}
+
+// StatsSummary is a snapshot of the statistics accumulated by a Stats.
+//
+// The JSON form of a StatsSummary is an object with the members
+// "features" and "columns", where "columns" is an array of ColumnStats
+// objects.
+type StatsSummary struct {
+	// Features is the number of features added.
+	Features int64 `json:"features"`
+	// Columns contains the statistics of each column, in column order.
+	Columns []ColumnStats `json:"columns"`
+}
+
+// ColumnStats holds the statistics of one column.
+type ColumnStats struct {
+	// Name is the column name.
+	Name string
+	// Type is the column type.
+	Type flat.ColumnType
+	// Count is the number of features with a value for the column.
+	Count int64
+	// NullCount is the number of features where the column is absent
+	// or null.
+	NullCount int64
+	// Min and Max are the smallest and largest values, or nil if there
+	// are none. They are only set for numeric and DateTime columns:
+	// as an int64 for signed integer columns, a uint64 for unsigned
+	// integer columns, a float64 for Float and Double columns, ignoring
+	// NaN and infinite values, and the stored text for DateTime
+	// columns, ignoring values which are not valid ISO 8601. When
+	// decoded from JSON, numeric values are json.Number values.
+	Min, Max any
+	// MinLength and MaxLength are the shortest and longest value
+	// lengths of a String, Json, or Binary column, in characters for
+	// String and Json columns and in bytes for Binary columns. They are
+	// zero for other column types.
+	MinLength, MaxLength int
+	// Distinct is the approximate number of distinct values.
+	Distinct uint64
+	// Top lists the most frequent values, most frequent first, or is
+	// nil if Stats did not track them.
+	Top []ValueCount
+}
+
+// ValueCount is a value and the number of times it occurred.
+type ValueCount struct {
+	// Value is the value, as a bool, int64, uint64, float64, string, or
+	// []byte depending on the column type. DateTime and Json values are
+	// their stored text. NaN and infinite values are not counted.
+	Value any `json:"value"`
+	// Count is the approximate number of occurrences of the value. It
+	// may overestimate the true count, but never underestimates it.
+	Count int64 `json:"count"`
+}
+
+// columnStatsJSON is the JSON form of ColumnStats. The type is written
+// by name, and the length members are only written for column types
+// which have lengths.
+type columnStatsJSON struct {
+	Name      string       `json:"name"`
+	Type      string       `json:"type"`
+	Count     int64        `json:"count"`
+	NullCount int64        `json:"nullCount"`
+	Min       any          `json:"min,omitempty"`
+	Max       any          `json:"max,omitempty"`
+	MinLength *int         `json:"minLength,omitempty"`
+	MaxLength *int         `json:"maxLength,omitempty"`
+	Distinct  uint64       `json:"distinct"`
+	Top       []ValueCount `json:"top,omitempty"`
+}
+
+func (c ColumnStats) MarshalJSON() ([]byte, error) {
+	typeName, ok := flat.EnumNamesColumnType[c.Type]
+	if !ok {
+		return nil, errInvalidColumnType(c.Type)
+	}
+	v := columnStatsJSON{
+		Name:      c.Name,
+		Type:      typeName,
+		Count:     c.Count,
+		NullCount: c.NullCount,
+		Min:       c.Min,
+		Max:       c.Max,
+		Distinct:  c.Distinct,
+		Top:       c.Top,
+	}
+	if c.Type == flat.ColumnTypeString || c.Type == flat.ColumnTypeJson || c.Type == flat.ColumnTypeBinary {
+		v.MinLength, v.MaxLength = &c.MinLength, &c.MaxLength
+	}
+	return json.Marshal(v)
+}
+
+func (c *ColumnStats) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v columnStatsJSON
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+	columnType, ok := flat.EnumValuesColumnType[v.Type]
+	if !ok {
+		return fmtErr("invalid column type name: %q", v.Type)
+	}
+	*c = ColumnStats{
+		Name:      v.Name,
+		Type:      columnType,
+		Count:     v.Count,
+		NullCount: v.NullCount,
+		Min:       v.Min,
+		Max:       v.Max,
+		Distinct:  v.Distinct,
+		Top:       v.Top,
+	}
+	if v.MinLength != nil {
+		c.MinLength = *v.MinLength
+	}
+	if v.MaxLength != nil {
+		c.MaxLength = *v.MaxLength
+	}
+	return nil
+}
+
+// statsMetadataKey is the member of the header metadata JSON object
+// which holds a StatsSummary.
+const statsMetadataKey = "stats"
+
+// AddToMetadata returns FlatGeobuf header metadata which contains the
+// summary in the "stats" member of a JSON object, for example as the
+// value of header.Header.Metadata. If metadata is empty, the result is
+// a new JSON object. Otherwise metadata must be a JSON object, and the
+// result keeps its other members and replaces any existing "stats"
+// member. AddToMetadata returns an error wrapping ErrInvalidJSON if
+// metadata is not empty and not a JSON object.
+func (s StatsSummary) AddToMetadata(metadata string) (string, error) {
+	m := make(map[string]json.RawMessage)
+	if strings.TrimSpace(metadata) != "" {
+		if err := json.Unmarshal([]byte(metadata), &m); err != nil {
+			return "", fmt.Errorf("%w: metadata is not a JSON object: %w", ErrInvalidJSON, err)
+		} else if m == nil {
+			return "", fmt.Errorf("%w: metadata is not a JSON object", ErrInvalidJSON)
+		}
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	m[statsMetadataKey] = b
+	if b, err = json.Marshal(m); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// StatsFromMetadata returns the StatsSummary stored in FlatGeobuf header
+// metadata by StatsSummary.AddToMetadata. The return value is nil if
+// the metadata does not contain one. The error return value wraps
+// ErrInvalidJSON if the "stats" member is not a valid StatsSummary.
+func StatsFromMetadata(metadata string) (*StatsSummary, error) {
+	if !strings.HasPrefix(strings.TrimSpace(metadata), "{") {
+		return nil, nil
+	}
+	var v map[string]json.RawMessage
+	if json.Unmarshal([]byte(metadata), &v) != nil {
+		return nil, nil
+	}
+	raw, ok := v[statsMetadataKey]
+	if !ok {
+		return nil, nil
+	}
+	var s StatsSummary
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, fmt.Errorf("%w: invalid stats in metadata: %w", ErrInvalidJSON, err)
+	}
+	return &s, nil
+}
+
+// hyperLogLogP is the precision of hyperLogLog: it has 2^p registers,
+// for a standard error of about 1.04/sqrt(2^p), or 1.6%.
+const hyperLogLogP = 12
+
+// hyperLogLog estimates the number of distinct values added to it.
+type hyperLogLog struct {
+	registers [1 << hyperLogLogP]uint8
+}
+
+func (h *hyperLogLog) add(b []byte) {
+	x := hash64(b)
+	i := x >> (64 - hyperLogLogP)
+	rank := uint8(bits.LeadingZeros64(x<<hyperLogLogP|1<<(hyperLogLogP-1))) + 1
+	if rank > h.registers[i] {
+		h.registers[i] = rank
+	}
+}
+
+func (h *hyperLogLog) estimate() uint64 {
+	const m = float64(len(h.registers))
+	var sum float64
+	zeros := 0
+	for _, r := range h.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	e := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if e <= 2.5*m && zeros > 0 {
+		e = m * math.Log(m/float64(zeros)) // Linear counting for small cardinalities.
+	}
+	return uint64(e + 0.5)
+}
+
+// hash64 returns a 64-bit hash of b: FNV-1a, followed by the MurmurHash3
+// finalizer to spread the bits, which HyperLogLog depends on.
+func hash64(b []byte) uint64 {
+	x := uint64(14695981039346656037)
+	for _, c := range b {
+		x ^= uint64(c)
+		x *= 1099511628211
+	}
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// spaceSaving finds the most frequent values added to it using the
+// Space-Saving algorithm, which keeps a fixed number of counters.
+type spaceSaving struct {
+	capacity int
+	counts   map[string]int64
+}
+
+func newSpaceSaving(capacity int) *spaceSaving {
+	return &spaceSaving{
+		capacity: capacity,
+		counts:   make(map[string]int64, capacity),
+	}
+}
+
+func (s *spaceSaving) add(raw []byte) {
+	if _, ok := s.counts[string(raw)]; ok {
+		s.counts[string(raw)]++
+		return
+	} else if len(s.counts) < s.capacity {
+		s.counts[string(raw)] = 1
+		return
+	}
+	// Replace the value with the smallest count, which the new value
+	// may have occurred up to that many times in place of.
+	var minKey string
+	minCount := int64(math.MaxInt64)
+	for k, n := range s.counts {
+		if n < minCount || n == minCount && k < minKey {
+			minKey, minCount = k, n
+		}
+	}
+	delete(s.counts, minKey)
+	s.counts[string(raw)] = minCount + 1
+}
+
+// top returns up to n of the most frequent values, decoded from their
+// encoded form in a column of type t.
+func (s *spaceSaving) top(n int, t flat.ColumnType) []ValueCount {
+	keys := make([]string, 0, len(s.counts))
+	for k := range s.counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ni, nj := s.counts[keys[i]], s.counts[keys[j]]
+		return ni > nj || ni == nj && keys[i] < keys[j]
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	top := make([]ValueCount, len(keys))
+	for i, k := range keys {
+		top[i] = ValueCount{Value: decodeRaw(t, []byte(k)), Count: s.counts[k]}
+	}
+	return top
+}
+
+// decodeRaw decodes an encoded value of column type t as a bool, int64,
+// uint64, float64, string, or []byte.
+func decodeRaw(t flat.ColumnType, raw []byte) any {
+	switch {
+	case t == flat.ColumnTypeBool:
+		return raw[0] != 0
+	case isSigned(t):
+		return rawInt64(t, raw)
+	case isUnsigned(t):
+		return rawUint64(t, raw)
+	case isFloat(t):
+		return rawFloat64(t, raw)
+	case t == flat.ColumnTypeBinary:
+		return raw[flatbuffers.SizeUint32:]
+	default:
+		return string(raw[flatbuffers.SizeUint32:])
+	}
+}
@@ -0,0 +1,236 @@
+package props
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+)
+
+// statsColumns are the columns of statsSchema.
+var statsColumns = []Column{
+	{Name: "i", Type: flat.ColumnTypeInt},
+	{Name: "u", Type: flat.ColumnTypeUShort},
+	{Name: "d", Type: flat.ColumnTypeDouble},
+	{Name: "s", Type: flat.ColumnTypeString},
+	{Name: "t", Type: flat.ColumnTypeDateTime},
+	{Name: "b", Type: flat.ColumnTypeBool},
+	{Name: "x", Type: flat.ColumnTypeBinary},
+}
+
+// statsSchema is the schema the tests of Stats add Props under.
+var statsSchema = NewSchema(statsColumns)
+
+// statsRows are the values added to a Stats by TestStats. A nil value
+// sets the column to null, and a missing one leaves it absent.
+var statsRows = []map[int]any{
+	{0: int32(-5), 1: uint16(7), 2: 1.5, 3: "héllo", 4: "2024-03-01T00:00:00Z", 5: true, 6: []byte{1, 2, 3}},
+	{0: int32(9), 1: uint16(2), 2: math.NaN(), 3: "", 4: "2023-12-31T23:00:00-02:00", 5: true},
+	{0: int32(-5), 2: -2.0, 3: "ab", 4: "not a date", 5: false, 6: []byte{}},
+	{0: nil, 1: nil, 2: math.Inf(1), 4: "2024-01-01T00:30:00+01:00"},
+}
+
+func TestStats(t *testing.T) {
+	s := NewStats(statsSchema, 2)
+	for i, row := range statsRows {
+		p := NewProps(statsSchema)
+		for col, v := range row {
+			var err error
+			if text, ok := v.(string); ok && col == 4 {
+				err = p.SetDateTimeString(col, text)
+			} else {
+				err = p.SetValue(col, v)
+			}
+			if err != nil {
+				t.Fatalf("row %d: %v", i, err)
+			}
+		}
+		if err := s.Add(p); err != nil {
+			t.Fatalf("row %d: Add: %v", i, err)
+		}
+	}
+	if s.Features() != 4 {
+		t.Errorf("got Features %d, want 4", s.Features())
+	}
+	summary := s.Summary()
+	want := []ColumnStats{
+		{
+			Name: "i", Type: flat.ColumnTypeInt, Count: 3, NullCount: 1,
+			Min: int64(-5), Max: int64(9), Distinct: 2,
+			Top: []ValueCount{{int64(-5), 2}, {int64(9), 1}},
+		},
+		{
+			Name: "u", Type: flat.ColumnTypeUShort, Count: 2, NullCount: 2,
+			Min: uint64(2), Max: uint64(7), Distinct: 2,
+			Top: []ValueCount{{uint64(2), 1}, {uint64(7), 1}},
+		},
+		{
+			Name: "d", Type: flat.ColumnTypeDouble, Count: 4, NullCount: 0,
+			Min: -2.0, Max: 1.5, Distinct: 4,
+			Top: []ValueCount{{-2.0, 1}, {1.5, 1}},
+		},
+		{
+			Name: "s", Type: flat.ColumnTypeString, Count: 3, NullCount: 1,
+			MinLength: 0, MaxLength: 5, Distinct: 3,
+			Top: []ValueCount{{"", 1}, {"ab", 1}},
+		},
+		{
+			Name: "t", Type: flat.ColumnTypeDateTime, Count: 4, NullCount: 0,
+			// Min and Max compare instants, not text, and ignore values
+			// which aren't ISO 8601.
+			Min: "2024-01-01T00:30:00+01:00", Max: "2024-03-01T00:00:00Z", Distinct: 4,
+			Top: []ValueCount{{"not a date", 1}, {"2024-03-01T00:00:00Z", 1}},
+		},
+		{
+			Name: "b", Type: flat.ColumnTypeBool, Count: 3, NullCount: 1, Distinct: 2,
+			Top: []ValueCount{{true, 2}, {false, 1}},
+		},
+		{
+			Name: "x", Type: flat.ColumnTypeBinary, Count: 2, NullCount: 2,
+			MinLength: 0, MaxLength: 3, Distinct: 2,
+			Top: []ValueCount{{[]byte{}, 1}, {[]byte{1, 2, 3}, 1}},
+		},
+	}
+	if summary.Features != 4 || len(summary.Columns) != len(want) {
+		t.Fatalf("got %d features and %d columns, want 4 and %d", summary.Features, len(summary.Columns), len(want))
+	}
+	for i := range want {
+		if got := summary.Columns[i]; !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("column %q:\n got %+v\nwant %+v", want[i].Name, got, want[i])
+		}
+	}
+}
+
+func TestStatsAddErrors(t *testing.T) {
+	s := NewStats(statsSchema, 0)
+	if err := s.Add(NewProps(parseSchema)); !errors.Is(err, ErrSchemaMismatch) {
+		t.Errorf("Add: got %v for wrong number of columns, want ErrSchemaMismatch", err)
+	}
+	cols := append([]Column(nil), statsColumns...)
+	cols[6].Type = flat.ColumnTypeString
+	other := NewProps(NewSchema(cols))
+	if err := other.SetInt(0, 1); err != nil {
+		t.Fatal(err)
+	}
+	var typeErr *TypeError
+	if err := s.Add(other); !errors.As(err, &typeErr) || typeErr.Col != 6 {
+		t.Errorf("Add: got %v, want *TypeError for column 6", err)
+	}
+	summary := s.Summary()
+	if summary.Features != 0 || summary.Columns[0].Count != 0 || summary.Columns[0].Top != nil {
+		t.Errorf("failed Add changed statistics: %+v", summary)
+	}
+}
+
+func TestHyperLogLog(t *testing.T) {
+	for _, n := range []int{0, 1, 10, 1000, 100000} {
+		h := new(hyperLogLog)
+		for i := 0; i < n; i++ {
+			b := []byte(strconv.Itoa(i))
+			h.add(b)
+			h.add(b) // Duplicates don't count.
+		}
+		got := float64(h.estimate())
+		if math.Abs(got-float64(n)) > 0.05*float64(n)+0.5 {
+			t.Errorf("%d distinct values: got estimate %v", n, got)
+		}
+	}
+}
+
+func TestSpaceSaving(t *testing.T) {
+	// Two frequent values among many which occur once. Each frequent
+	// value makes up more than 1/capacity of the stream, so the
+	// algorithm is guaranteed to keep it.
+	s := newSpaceSaving(8)
+	add := func(v string) {
+		s.add(stringEntry(0, uint32(len(v)), v)[2:])
+	}
+	var rare int
+	for i := 0; i < 300; i++ {
+		switch {
+		case i%3 == 0:
+			add("a")
+		case i%5 == 1:
+			add("b")
+		default:
+			add("rare" + strconv.Itoa(rare))
+			rare++
+		}
+	}
+	top := s.top(2, flat.ColumnTypeString)
+	if len(top) != 2 || top[0].Value != "a" || top[1].Value != "b" {
+		t.Fatalf("got top %v, want a then b", top)
+	}
+	// Counts may overestimate, but never underestimate.
+	if top[0].Count < 100 || top[1].Count < 40 {
+		t.Errorf("got counts %d and %d, want at least 100 and 40", top[0].Count, top[1].Count)
+	}
+	if len(s.counts) > 8 {
+		t.Errorf("got %d counters, want at most 8", len(s.counts))
+	}
+	if got := s.top(100, flat.ColumnTypeString); len(got) != 8 {
+		t.Errorf("got %d values, want all 8 counters", len(got))
+	}
+}
+
+func TestStatsMetadataRoundTrip(t *testing.T) {
+	s := NewStats(statsSchema, 2)
+	for i := 0; i < 3; i++ {
+		p := NewProps(statsSchema)
+		if err := errors.Join(p.SetInt(0, int32(i)), p.SetDouble(2, 0.5), p.SetString(3, "x"), p.SetBool(5, true)); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Add(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	summary := s.Summary()
+	metadata, err := summary.AddToMetadata(`{"source":"test","stats":"old"}`)
+	if err != nil {
+		t.Fatalf("AddToMetadata: %v", err)
+	}
+	var m map[string]json.RawMessage
+	if err = json.Unmarshal([]byte(metadata), &m); err != nil || string(m["source"]) != `"test"` {
+		t.Errorf("other members not kept: %s", metadata)
+	}
+	got, err := StatsFromMetadata(metadata)
+	if err != nil || got == nil {
+		t.Fatalf("StatsFromMetadata: got %v, %v", got, err)
+	}
+	// Decoded numbers are json.Number values, so compare the JSON.
+	a, _ := json.Marshal(summary)
+	b, _ := json.Marshal(got)
+	if string(a) != string(b) {
+		t.Errorf("round trip:\n got %s\nwant %s", b, a)
+	}
+	if got.Columns[0].Min != json.Number("0") || got.Columns[3].MaxLength != 1 || got.Columns[5].Type != flat.ColumnTypeBool {
+		t.Errorf("got decoded columns %+v", got.Columns)
+	}
+	// Only String, Json, and Binary columns have lengths.
+	if stats := string(m["stats"]); !strings.Contains(stats, `{"name":"i","type":"Int","count":3,"nullCount":0,"min":0,"max":2,"distinct":3,`) ||
+		!strings.Contains(stats, `"minLength":1,"maxLength":1`) {
+		t.Errorf("got stats JSON %s", stats)
+	}
+
+	if metadata, err = summary.AddToMetadata(""); err != nil || !strings.HasPrefix(metadata, `{"stats":`) {
+		t.Errorf("empty metadata: got %s, %v", metadata, err)
+	}
+	for _, metadata := range []string{"[1]", "null", "{bad"} {
+		if _, err = summary.AddToMetadata(metadata); !errors.Is(err, ErrInvalidJSON) {
+			t.Errorf("AddToMetadata(%q): got %v, want ErrInvalidJSON", metadata, err)
+		}
+	}
+	for _, metadata := range []string{"", "plain text", `{"other":1}`, "{bad"} {
+		if got, err := StatsFromMetadata(metadata); got != nil || err != nil {
+			t.Errorf("StatsFromMetadata(%q): got %v, %v, want nil, nil", metadata, got, err)
+		}
+	}
+	if _, err = StatsFromMetadata(`{"stats":{"columns":[{"type":"Nope"}]}}`); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("StatsFromMetadata: got %v for invalid stats, want ErrInvalidJSON", err)
+	}
+}
@@ -0,0 +1,214 @@
+package props
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// ViolationKind identifies which column constraint a Violation breaks.
+type ViolationKind int
+
+const (
+	// ViolationRequired indicates a required or primary key column is
+	// absent or null.
+	ViolationRequired ViolationKind = iota + 1
+	// ViolationWidth indicates a string value has more characters than
+	// the column width.
+	ViolationWidth
+	// ViolationPrecision indicates a decimal value has more decimal
+	// digits than the column precision allows.
+	ViolationPrecision
+	// ViolationScale indicates a decimal value has more digits after
+	// the decimal point than the column scale.
+	ViolationScale
+	// ViolationUnique indicates a value in a unique column was already
+	// seen in an earlier Props.
+	ViolationUnique
+	// ViolationPrimaryKey indicates a value in a primary key column was
+	// already seen in an earlier Props.
+	ViolationPrimaryKey
+)
+
+var violationKindNames = [...]string{
+	ViolationRequired:   "Required",
+	ViolationWidth:      "Width",
+	ViolationPrecision:  "Precision",
+	ViolationScale:      "Scale",
+	ViolationUnique:     "Unique",
+	ViolationPrimaryKey: "PrimaryKey",
+}
+
+func (k ViolationKind) String() string {
+	if 0 < k && int(k) < len(violationKindNames) {
+		return violationKindNames[k]
+	}
+	return "ViolationKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Violation describes one column of one Props which breaks a
+// constraint of its Column.
+type Violation struct {
+	// Col is the index of the column in the schema.
+	Col int
+	// Name is the name of the column.
+	Name string
+	// Kind is the constraint which was violated.
+	Kind ViolationKind
+	// Detail is a human-readable description of the violation.
+	Detail string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("column %d (%q): %s: %s", v.Col, v.Name, v.Kind, v.Detail)
+}
+
+// Validator checks Props against the constraints declared in the
+// columns of a Schema: Required, Width, Precision, Scale, Unique, and
+// PrimaryKey.
+//
+// Required, width, precision, and scale constraints are checked for
+// each Props in isolation. Unique and primary key constraints apply
+// across every Props given to the same Validator, so a Validator
+// should be fed each feature of a stream in turn. As in SQL, absent and
+// null values do not take part in uniqueness checks, but primary key
+// columns are also required.
+//
+// Width is measured in characters (Unicode code points) and applies to
+// string columns. Precision and scale apply to double columns, and are
+// measured on the shortest decimal representation of the value which
+// round trips. A width or precision less than or equal to zero, and a
+// negative scale, means the constraint is not set. Scale is only
+// checked if precision is set.
+//
+// A Validator is not safe for concurrent use.
+type Validator struct {
+	schema *Schema
+	seen   []map[string]struct{}
+}
+
+// NewValidator creates a Validator for Props under a schema.
+func NewValidator(schema *Schema) *Validator {
+	schema = orEmpty(schema)
+	v := &Validator{
+		schema: schema,
+		seen:   make([]map[string]struct{}, schema.ColumnsLength()),
+	}
+	for i := range v.seen {
+		if c := schema.Column(i); c.Unique || c.PrimaryKey {
+			v.seen[i] = make(map[string]struct{})
+		}
+	}
+	return v
+}
+
+// Validate checks one Props against the schema constraints and returns
+// the violations found, in column order. The values of unique and
+// primary key columns in p are remembered for checking later Props.
+//
+// The error return value is non-nil only if p cannot be checked, for
+// example because its property buffer is corrupt or it does not have
+// the same number of columns as the schema. If a column of p has a
+// different type from the schema column, the error is a *TypeError.
+func (v *Validator) Validate(p *Props) ([]Violation, error) {
+	n := v.schema.ColumnsLength()
+	if p.numColumns() != n {
+		return nil, fmt.Errorf("%w: props has %d columns but validator schema has %d", ErrSchemaMismatch, p.numColumns(), n)
+	}
+	for col := 0; col < n; col++ {
+		if t := p.columnType(col); t != v.schema.Type(col) {
+			return nil, &TypeError{Feature: p.feature, Col: col, Name: p.columnName(col), Expected: v.schema.Type(col), Actual: t}
+		}
+	}
+	var violations []Violation
+	add := func(col int, kind ViolationKind, format string, a ...any) {
+		violations = append(violations, Violation{
+			Col:    col,
+			Name:   v.schema.Name(col),
+			Kind:   kind,
+			Detail: fmt.Sprintf(format, a...),
+		})
+	}
+	for col := 0; col < n; col++ {
+		c := v.schema.Column(col)
+		raw, err := p.rawValue(col)
+		if err != nil {
+			return nil, err
+		}
+		if raw == nil {
+			if c.Required || c.PrimaryKey {
+				add(col, ViolationRequired, "no value")
+			}
+			continue
+		}
+		switch c.Type {
+		case flat.ColumnTypeString:
+			if c.Width > 0 {
+				if m := utf8.RuneCount(raw[flatbuffers.SizeUint32:]); m > int(c.Width) {
+					add(col, ViolationWidth, "value has %d characters but width is %d", m, c.Width)
+				}
+			}
+		case flat.ColumnTypeDouble:
+			if c.Precision > 0 {
+				checkDecimal(c, flatbuffers.GetFloat64(raw), func(kind ViolationKind, format string, a ...any) {
+					add(col, kind, format, a...)
+				})
+			}
+		}
+		if seen := v.seen[col]; seen != nil {
+			key := string(raw)
+			if _, dup := seen[key]; dup {
+				if c.PrimaryKey {
+					add(col, ViolationPrimaryKey, "duplicate primary key value")
+				} else {
+					add(col, ViolationUnique, "duplicate value")
+				}
+			} else {
+				seen[key] = struct{}{}
+			}
+		}
+	}
+	return violations, nil
+}
+
+// Reset forgets the unique and primary key values seen so far.
+func (v *Validator) Reset() {
+	for i := range v.seen {
+		if v.seen[i] != nil {
+			v.seen[i] = make(map[string]struct{})
+		}
+	}
+}
+
+func checkDecimal(c Column, x float64, add func(ViolationKind, string, ...any)) {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		add(ViolationPrecision, "value %v is not a finite decimal", x)
+		return
+	}
+	intDigits, fracDigits := decimalDigits(x)
+	if c.Scale >= 0 {
+		if fracDigits > int(c.Scale) {
+			add(ViolationScale, "value %v has %d digits after the decimal point but scale is %d", x, fracDigits, c.Scale)
+		}
+		if intDigits > int(c.Precision-c.Scale) {
+			add(ViolationPrecision, "value %v has %d digits before the decimal point but precision %d and scale %d allow %d", x, intDigits, c.Precision, c.Scale, c.Precision-c.Scale)
+		}
+	} else if intDigits+fracDigits > int(c.Precision) {
+		add(ViolationPrecision, "value %v has %d digits but precision is %d", x, intDigits+fracDigits, c.Precision)
+	}
+}
+
+// decimalDigits returns the number of digits before and after the
+// decimal point in the shortest decimal representation of x, ignoring
+// the sign and leading zeros before the decimal point.
+func decimalDigits(x float64) (intDigits, fracDigits int) {
+	s := strconv.FormatFloat(math.Abs(x), 'f', -1, 64)
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	intPart = strings.TrimLeft(intPart, "0")
+	return len(intPart), len(fracPart)
+}
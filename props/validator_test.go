@@ -0,0 +1,142 @@
+package props
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+)
+
+func TestValidator(t *testing.T) {
+	schema := NewSchema([]Column{
+		{Name: "id", Type: flat.ColumnTypeLong, PrimaryKey: true},
+		{Name: "name", Type: flat.ColumnTypeString, Width: 3, Required: true},
+		{Name: "price", Type: flat.ColumnTypeDouble, Precision: 5, Scale: 2},
+		{Name: "ratio", Type: flat.ColumnTypeDouble, Precision: 3, Scale: -1},
+		{Name: "code", Type: flat.ColumnTypeString, Unique: true},
+	})
+	type row struct {
+		id    any
+		name  any
+		price any
+		ratio any
+		code  any
+	}
+	testCases := []struct {
+		name string
+		rows []row
+		want [][]ViolationKind
+	}{
+		{
+			name: "valid",
+			rows: []row{{int64(1), "abc", 123.45, 1.25, "x"}, {int64(2), "ééé", -1.5, 0.5, "y"}},
+			want: [][]ViolationKind{nil, nil},
+		},
+		{
+			name: "absent required columns",
+			rows: []row{{nil, nil, nil, nil, nil}},
+			want: [][]ViolationKind{{ViolationRequired, ViolationRequired}},
+		},
+		{
+			name: "width",
+			rows: []row{{int64(1), "abcd", nil, nil, nil}},
+			want: [][]ViolationKind{{ViolationWidth}},
+		},
+		{
+			name: "precision and scale",
+			rows: []row{{int64(1), "a", 1234.5, nil, nil}, {int64(2), "a", 1.125, nil, nil}},
+			want: [][]ViolationKind{{ViolationPrecision}, {ViolationScale}},
+		},
+		{
+			name: "precision without scale",
+			rows: []row{{int64(1), "a", nil, 1.125, nil}},
+			want: [][]ViolationKind{{ViolationPrecision}},
+		},
+		{
+			name: "duplicates",
+			rows: []row{{int64(1), "a", nil, nil, "x"}, {int64(1), "a", nil, nil, "x"}, {int64(2), "a", nil, nil, nil}, {int64(3), "a", nil, nil, nil}},
+			want: [][]ViolationKind{nil, {ViolationPrimaryKey, ViolationUnique}, nil, nil},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			v := NewValidator(schema)
+			for i, r := range testCase.rows {
+				p := NewProps(schema)
+				for col, value := range []any{r.id, r.name, r.price, r.ratio, r.code} {
+					if value == nil {
+						continue
+					} else if err := p.SetValue(col, value); err != nil {
+						t.Fatalf("row %d: SetValue(%d, %v): %v", i, col, value, err)
+					}
+				}
+				violations, err := v.Validate(p)
+				if err != nil {
+					t.Fatalf("row %d: Validate: %v", i, err)
+				}
+				var kinds []ViolationKind
+				for _, violation := range violations {
+					kinds = append(kinds, violation.Kind)
+				}
+				if !reflect.DeepEqual(kinds, testCase.want[i]) {
+					t.Errorf("row %d: violations %v, want kinds %v", i, violations, testCase.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidatorReset(t *testing.T) {
+	schema := NewSchema([]Column{{Name: "k", Type: flat.ColumnTypeInt, Unique: true}})
+	v := NewValidator(schema)
+	p := NewProps(schema)
+	if err := p.SetInt(0, 7); err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []int{0, 1} {
+		if violations, err := v.Validate(p); err != nil || len(violations) != want {
+			t.Fatalf("Validate %d: %v, %v, want %d violations", i, violations, err, want)
+		}
+	}
+	v.Reset()
+	if violations, err := v.Validate(p); err != nil || len(violations) != 0 {
+		t.Errorf("Validate after Reset: %v, %v, want no violations", violations, err)
+	}
+}
+
+func TestValidatorErrors(t *testing.T) {
+	schema := NewSchema([]Column{
+		{Name: "s", Type: flat.ColumnTypeString},
+		{Name: "d", Type: flat.ColumnTypeDouble, Precision: 5},
+	})
+	v := NewValidator(schema)
+
+	// Same number of columns, different types. The props buffer holds
+	// a one byte value where the validator schema expects a string.
+	other := NewProps(NewSchema([]Column{
+		{Name: "s", Type: flat.ColumnTypeBool},
+		{Name: "d", Type: flat.ColumnTypeByte},
+	}))
+	if err := other.SetBool(0, true); err != nil {
+		t.Fatal(err)
+	} else if err = other.SetByte(1, 1); err != nil {
+		t.Fatal(err)
+	}
+	_, err := v.Validate(other)
+	var typeErr *TypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("Validate with different column types error = %v, want *TypeError", err)
+	} else if typeErr.Col != 0 || typeErr.Expected != flat.ColumnTypeString || typeErr.Actual != flat.ColumnTypeBool {
+		t.Errorf("TypeError = %+v", typeErr)
+	}
+
+	if _, err = v.Validate(NewProps(NewSchema(nil))); !errors.Is(err, ErrSchemaMismatch) {
+		t.Errorf("Validate with different column count error = %v, want %v", err, ErrSchemaMismatch)
+	}
+
+	corrupt := PropsFromFlat(schema, []byte{0, 0, 9, 0, 0, 0, 'a'}, WithParseMode(ParseStrict))
+	if _, err = v.Validate(corrupt); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Validate of corrupt props error = %v, want %v", err, ErrCorrupt)
+	}
+}
@@ -0,0 +1,145 @@
+package props
+
+import (
+	"bytes"
+	"unsafe"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+)
+
+// This file contains the borrowed-view accessors, which return strings
+// and byte slices aliasing the underlying property buffer instead of
+// copies of it.
+//
+// A view is only valid until the next mutation of the Props, i.e. any
+// call to a setter, Delete, SetNull, or other method that changes the
+// property values. After that, the view may refer to different or
+// freed data. If the Props was created by PropsFromFlat, the view also
+// aliases the byte slice that was passed in, and must not be used after
+// that slice is modified. Strings returned by view accessors must never
+// be retained: copy them with strings.Clone if they need to outlive the
+// view.
+//
+// A view may be passed back to a setter of the same Props, for example
+// to copy one column to another with SetString(j, view). The setter
+// detects that the value aliases the property buffer and copies it
+// before moving any data.
+
+// StringView returns the value of a string column as a borrowed view
+// which aliases the property buffer, avoiding the allocation made by
+// GetString. See the file comment for the rules on using views.
+func (p *Props) StringView(col int) (string, error) {
+	b, err := p.getBinary(col, flat.ColumnTypeString)
+	if err != nil {
+		return "", err
+	}
+	return viewString(b), nil
+}
+
+func (p *Props) StringViewName(name string) (string, error) {
+	col, err := p.name2Col(name)
+	if err != nil {
+		return "", err
+	}
+	return p.StringView(col)
+}
+
+// JSONView returns the value of a JSON column as a borrowed view which
+// aliases the property buffer, avoiding the allocation made by GetJSON.
+func (p *Props) JSONView(col int) (string, error) {
+	b, err := p.getBinary(col, flat.ColumnTypeJson)
+	if err != nil {
+		return "", err
+	}
+	return viewString(b), nil
+}
+
+func (p *Props) JSONViewName(name string) (string, error) {
+	col, err := p.name2Col(name)
+	if err != nil {
+		return "", err
+	}
+	return p.JSONView(col)
+}
+
+// DateTimeStringView returns the text of a date/time column as a
+// borrowed view which aliases the property buffer, avoiding the
+// allocation made by GetDateTimeString.
+func (p *Props) DateTimeStringView(col int) (string, error) {
+	b, err := p.getBinary(col, flat.ColumnTypeDateTime)
+	if err != nil {
+		return "", err
+	}
+	return viewString(b), nil
+}
+
+func (p *Props) DateTimeStringViewName(name string) (string, error) {
+	col, err := p.name2Col(name)
+	if err != nil {
+		return "", err
+	}
+	return p.DateTimeStringView(col)
+}
+
+// BinaryView returns the value of a binary column as a borrowed view
+// which aliases the property buffer, avoiding the copy made by
+// GetBinary. The returned slice must not be modified.
+func (p *Props) BinaryView(col int) ([]byte, error) {
+	return p.getBinary(col, flat.ColumnTypeBinary)
+}
+
+func (p *Props) BinaryViewName(name string) ([]byte, error) {
+	col, err := p.name2Col(name)
+	if err != nil {
+		return nil, err
+	}
+	return p.BinaryView(col)
+}
+
+// EqualString reports whether the value of a string column is equal to
+// s, without allocating. If the column has no value, EqualString
+// returns an error wrapping ErrNoValue or ErrNull in the same way as
+// GetString.
+func (p *Props) EqualString(col int, s string) (bool, error) {
+	v, err := p.StringView(col)
+	if err != nil {
+		return false, err
+	}
+	return v == s, nil
+}
+
+func (p *Props) EqualStringName(name string, s string) (bool, error) {
+	col, err := p.name2Col(name)
+	if err != nil {
+		return false, err
+	}
+	return p.EqualString(col, s)
+}
+
+// EqualBinary reports whether the value of a binary column is equal to
+// b, without allocating. If the column has no value, EqualBinary
+// returns an error wrapping ErrNoValue or ErrNull in the same way as
+// GetBinary.
+func (p *Props) EqualBinary(col int, b []byte) (bool, error) {
+	v, err := p.BinaryView(col)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(v, b), nil
+}
+
+func (p *Props) EqualBinaryName(name string, b []byte) (bool, error) {
+	col, err := p.name2Col(name)
+	if err != nil {
+		return false, err
+	}
+	return p.EqualBinary(col, b)
+}
+
+// viewString returns a string which aliases b.
+func viewString(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	return unsafe.String(&b[0], len(b))
+}
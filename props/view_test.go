@@ -0,0 +1,231 @@
+package props
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"unsafe"
+
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+)
+
+// viewSchema has one column of each type with a view accessor.
+var viewSchema = NewSchema([]Column{
+	{Name: "string", Type: flat.ColumnTypeString},
+	{Name: "json", Type: flat.ColumnTypeJson},
+	{Name: "datetime", Type: flat.ColumnTypeDateTime},
+	{Name: "binary", Type: flat.ColumnTypeBinary},
+	{Name: "int", Type: flat.ColumnTypeInt},
+})
+
+func TestViews(t *testing.T) {
+	data := bytesJoin(
+		stringEntry(0, 5, "hello"),
+		stringEntry(1, 7, `{"a":1}`),
+		stringEntry(2, 10, "2024-01-02"),
+		binaryEntry(3, []byte{1, 2, 3}),
+		intEntry(4, 9),
+	)
+	p := PropsFromFlat(viewSchema, data)
+
+	views := []struct {
+		name   string
+		get    func(col int) (string, error)
+		byName func(name string) (string, error)
+		col    int
+		want   string
+	}{
+		{"StringView", p.StringView, p.StringViewName, 0, "hello"},
+		{"JSONView", p.JSONView, p.JSONViewName, 1, `{"a":1}`},
+		{"DateTimeStringView", p.DateTimeStringView, p.DateTimeStringViewName, 2, "2024-01-02"},
+	}
+	for _, v := range views {
+		t.Run(v.name, func(t *testing.T) {
+			got, err := v.get(v.col)
+			if err != nil || got != v.want {
+				t.Fatalf("got %q, %v, want %q", got, err, v.want)
+			}
+			if !aliases(data, unsafe.Slice(unsafe.StringData(got), len(got))) {
+				t.Error("view does not alias the property buffer")
+			}
+			got, err = v.byName(viewSchema.Column(v.col).Name)
+			if err != nil || got != v.want {
+				t.Errorf("by name: got %q, %v, want %q", got, err, v.want)
+			}
+			if _, err = v.get(4); !errors.Is(err, ErrTypeMismatch) {
+				t.Errorf("Int column: got %v, want ErrTypeMismatch", err)
+			}
+			if _, err = v.byName("missing"); !errors.Is(err, ErrNoColumn) {
+				t.Errorf("missing column: got %v, want ErrNoColumn", err)
+			}
+		})
+	}
+
+	t.Run("BinaryView", func(t *testing.T) {
+		got, err := p.BinaryView(3)
+		if err != nil || !bytes.Equal(got, []byte{1, 2, 3}) {
+			t.Fatalf("got %v, %v, want [1 2 3]", got, err)
+		}
+		if !aliases(data, got) {
+			t.Error("view does not alias the property buffer")
+		}
+		if got, err = p.BinaryViewName("binary"); err != nil || !bytes.Equal(got, []byte{1, 2, 3}) {
+			t.Errorf("by name: got %v, %v, want [1 2 3]", got, err)
+		}
+		if _, err = p.BinaryView(0); !errors.Is(err, ErrTypeMismatch) {
+			t.Errorf("String column: got %v, want ErrTypeMismatch", err)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		q := PropsFromFlat(viewSchema, bytesJoin(stringEntry(0, 0, ""), binaryEntry(3, nil)))
+		if s, err := q.StringView(0); err != nil || s != "" {
+			t.Errorf("StringView: got %q, %v, want empty", s, err)
+		}
+		if b, err := q.BinaryView(3); err != nil || len(b) != 0 {
+			t.Errorf("BinaryView: got %v, %v, want empty", b, err)
+		}
+	})
+}
+
+func TestViewNoValue(t *testing.T) {
+	p := NewProps(viewSchema)
+	if _, err := p.StringView(0); !errors.Is(err, ErrNoValue) {
+		t.Errorf("absent: got %v, want ErrNoValue", err)
+	}
+	if err := p.SetNull(0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.StringView(0); !errors.Is(err, ErrNull) {
+		t.Errorf("null: got %v, want ErrNull", err)
+	}
+}
+
+func TestEqualString(t *testing.T) {
+	p := PropsFromFlat(viewSchema, bytesJoin(stringEntry(0, 5, "hello"), binaryEntry(3, []byte{1, 2})))
+
+	for _, testCase := range []struct {
+		s    string
+		want bool
+	}{
+		{"hello", true},
+		{"hell", false},
+		{"hello!", false},
+		{"", false},
+	} {
+		if got, err := p.EqualString(0, testCase.s); err != nil || got != testCase.want {
+			t.Errorf("EqualString(%q): got %v, %v, want %v", testCase.s, got, err, testCase.want)
+		}
+		if got, err := p.EqualStringName("string", testCase.s); err != nil || got != testCase.want {
+			t.Errorf("EqualStringName(%q): got %v, %v, want %v", testCase.s, got, err, testCase.want)
+		}
+	}
+	if got, err := p.EqualBinary(3, []byte{1, 2}); err != nil || !got {
+		t.Errorf("EqualBinary: got %v, %v, want true", got, err)
+	}
+	if got, err := p.EqualBinaryName("binary", []byte{1}); err != nil || got {
+		t.Errorf("EqualBinaryName: got %v, %v, want false", got, err)
+	}
+
+	if _, err := p.EqualString(4, "x"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Int column: got %v, want ErrTypeMismatch", err)
+	}
+	if _, err := p.EqualStringName("missing", "x"); !errors.Is(err, ErrNoColumn) {
+		t.Errorf("missing column: got %v, want ErrNoColumn", err)
+	}
+	q := NewProps(viewSchema)
+	if _, err := q.EqualString(0, "x"); !errors.Is(err, ErrNoValue) {
+		t.Errorf("absent: got %v, want ErrNoValue", err)
+	}
+	if err := q.SetNull(0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.EqualString(0, ""); !errors.Is(err, ErrNull) {
+		t.Errorf("null: got %v, want ErrNull", err)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = p.EqualString(0, "hello")
+	})
+	if allocs != 0 {
+		t.Errorf("got %v allocations per EqualString, want 0", allocs)
+	}
+}
+
+// TestViewSetAliasing checks that a view passed back to a setter of the
+// same Props is copied before the buffer is resized under it.
+func TestViewSetAliasing(t *testing.T) {
+	const long = "a value long enough to move the columns after it"
+	schema := NewSchema([]Column{
+		{Name: "x", Type: flat.ColumnTypeString},
+		{Name: "y", Type: flat.ColumnTypeString},
+		{Name: "z", Type: flat.ColumnTypeInt},
+	})
+	for _, testCase := range []struct {
+		name     string
+		x, y     string
+		from, to int
+		view     func(s string) string
+		want     [2]string
+	}{
+		{"grow before source", "short", long, 1, 0, nil, [2]string{long, long}},
+		{"shrink before source", long, "short", 1, 0, nil, [2]string{"short", "short"}},
+		{"grow after source", "short", long, 0, 1, nil, [2]string{"short", "short"}},
+		{"self suffix", "short", long, 1, 1, func(s string) string { return s[2:] }, [2]string{"short", long[2:]}},
+		{"self prefix", long, "short", 0, 0, func(s string) string { return s[:5] }, [2]string{long[:5], "short"}},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			p := NewProps(schema)
+			for _, err := range []error{p.SetString(0, testCase.x), p.SetString(1, testCase.y), p.SetInt(2, 7)} {
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			view, err := p.StringView(testCase.from)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if testCase.view != nil {
+				view = testCase.view(view)
+			}
+			if err = p.SetString(testCase.to, view); err != nil {
+				t.Fatal(err)
+			}
+			for col, want := range testCase.want {
+				if got, err := p.GetString(col); err != nil || got != want {
+					t.Errorf("column %d: got %q, %v, want %q", col, got, err, want)
+				}
+			}
+			if got, err := p.GetInt(2); err != nil || got != 7 {
+				t.Errorf("Int column: got %v, %v, want 7", got, err)
+			}
+		})
+	}
+
+	t.Run("binary", func(t *testing.T) {
+		p := NewProps(viewSchema)
+		for _, err := range []error{p.SetString(0, "x"), p.SetBinary(3, []byte("abc")), p.SetInt(4, 7)} {
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		view, err := p.BinaryView(3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = p.SetBinary(3, view[1:]); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := p.GetBinary(3); err != nil || string(got) != "bc" {
+			t.Errorf("got %q, %v, want %q", got, err, "bc")
+		}
+		if got, err := p.GetInt(4); err != nil || got != 7 {
+			t.Errorf("Int column: got %v, %v, want 7", got, err)
+		}
+	})
+}
+
+// aliases reports whether b points into data.
+func aliases(data, b []byte) bool {
+	return len(b) > 0 && overlaps(b, data)
+}
@@ -0,0 +1,103 @@
+package safeflat
+
+import (
+	"github.com/gogama/flatgeobuf-convert/props"
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// Column is a bounds-checked reader for a flat.Column table, in the same
+// way as Header is for flat.Header.
+type Column struct {
+	t table
+}
+
+// Init points rcv at the Column table at position i of buf.
+func (rcv *Column) Init(buf []byte, i flatbuffers.UOffsetT) error {
+	return rcv.t.init("Column", buf, int(i))
+}
+
+func (rcv *Column) Name() ([]byte, error) {
+	return rcv.t.bytes(4, "name", true)
+}
+
+func (rcv *Column) Type() (flat.ColumnType, error) {
+	b, err := rcv.t.getByte(6, "type", 0)
+	return flat.ColumnType(b), err
+}
+
+func (rcv *Column) Title() ([]byte, error) {
+	return rcv.t.bytes(8, "title", true)
+}
+
+func (rcv *Column) Description() ([]byte, error) {
+	return rcv.t.bytes(10, "description", true)
+}
+
+func (rcv *Column) Width() (int32, error) {
+	return rcv.t.getInt32(12, "width", -1)
+}
+
+func (rcv *Column) Precision() (int32, error) {
+	return rcv.t.getInt32(14, "precision", -1)
+}
+
+func (rcv *Column) Scale() (int32, error) {
+	return rcv.t.getInt32(16, "scale", -1)
+}
+
+func (rcv *Column) Nullable() (bool, error) {
+	return rcv.t.getBool(18, "nullable", true)
+}
+
+func (rcv *Column) Unique() (bool, error) {
+	return rcv.t.getBool(20, "unique", false)
+}
+
+func (rcv *Column) PrimaryKey() (bool, error) {
+	return rcv.t.getBool(22, "primary_key", false)
+}
+
+func (rcv *Column) Metadata() ([]byte, error) {
+	return rcv.t.bytes(24, "metadata", true)
+}
+
+// Column reads every field of the column into a props.Column, in the
+// same way as props.ColumnFromFlat.
+func (rcv *Column) Column() (col props.Column, err error) {
+	var b []byte
+	var nullable bool
+	if b, err = rcv.Name(); err != nil {
+		return
+	}
+	col.Name = string(b)
+	if col.Type, err = rcv.Type(); err != nil {
+		return
+	} else if b, err = rcv.Title(); err != nil {
+		return
+	}
+	col.Title = string(b)
+	if b, err = rcv.Description(); err != nil {
+		return
+	}
+	col.Description = string(b)
+	if col.Width, err = rcv.Width(); err != nil {
+		return
+	} else if col.Precision, err = rcv.Precision(); err != nil {
+		return
+	} else if col.Scale, err = rcv.Scale(); err != nil {
+		return
+	} else if nullable, err = rcv.Nullable(); err != nil {
+		return
+	}
+	col.Required = !nullable
+	if col.Unique, err = rcv.Unique(); err != nil {
+		return
+	} else if col.PrimaryKey, err = rcv.PrimaryKey(); err != nil {
+		return
+	} else if b, err = rcv.Metadata(); err != nil {
+		return
+	}
+	col.Metadata = string(b)
+	return
+}
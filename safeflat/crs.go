@@ -0,0 +1,40 @@
+package safeflat
+
+import (
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// Crs is a bounds-checked reader for a flat.Crs table, in the same way
+// as Header is for flat.Header.
+type Crs struct {
+	t table
+}
+
+// Init points rcv at the Crs table at position i of buf.
+func (rcv *Crs) Init(buf []byte, i flatbuffers.UOffsetT) error {
+	return rcv.t.init("Crs", buf, int(i))
+}
+
+func (rcv *Crs) Org() ([]byte, error) {
+	return rcv.t.bytes(4, "org", true)
+}
+
+func (rcv *Crs) Code() (int32, error) {
+	return rcv.t.getInt32(6, "code", 0)
+}
+
+func (rcv *Crs) Name() ([]byte, error) {
+	return rcv.t.bytes(8, "name", true)
+}
+
+func (rcv *Crs) Description() ([]byte, error) {
+	return rcv.t.bytes(10, "description", true)
+}
+
+func (rcv *Crs) Wkt() ([]byte, error) {
+	return rcv.t.bytes(12, "wkt", true)
+}
+
+func (rcv *Crs) CodeString() ([]byte, error) {
+	return rcv.t.bytes(14, "code_string", true)
+}
@@ -0,0 +1,55 @@
+package safeflat
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrCorrupt = textErr("corrupt flatbuffer")
+)
+
+const packageName = "safeflat: "
+
+// Error describes a problem found reading a FlatBuffers table, such as
+// an offset which points outside the buffer or a vector which is longer
+// than the buffer. It wraps ErrCorrupt.
+type Error struct {
+	// Table is the name of the table type being read, such as "Header".
+	Table string
+	// Field is the name of the field being read, or empty if the
+	// problem is with the table itself.
+	Field string
+	// Offset is the byte offset within the buffer where the problem was
+	// found.
+	Offset int
+	// Reason describes the problem.
+	Reason string
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	_, _ = b.WriteString(ErrCorrupt.Error())
+	_, _ = b.WriteString(": ")
+	_, _ = b.WriteString(e.Table)
+	if e.Field != "" {
+		_, _ = b.WriteString(".")
+		_, _ = b.WriteString(e.Field)
+	}
+	_, _ = fmt.Fprintf(&b, ": byte %d: %s", e.Offset, e.Reason)
+	return b.String()
+}
+
+func (e *Error) Unwrap() error {
+	return ErrCorrupt
+}
+
+// err returns an Error for a problem with field of t at offset.
+func (t *table) err(field string, offset int, format string, a ...any) error {
+	return &Error{Table: t.name, Field: field, Offset: offset, Reason: fmt.Sprintf(format, a...)}
+}
+
+func textErr(text string) error {
+	return errors.New(packageName + text)
+}
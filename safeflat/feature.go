@@ -0,0 +1,80 @@
+package safeflat
+
+import (
+	"github.com/gogama/flatgeobuf-convert/props"
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// Feature is a bounds-checked reader for a flat.Feature table, in the
+// same way as Header is for flat.Header.
+type Feature struct {
+	t table
+}
+
+// GetRootAsFeature returns the Feature which is the root table of the
+// buffer starting at offset within buf.
+func GetRootAsFeature(buf []byte, offset flatbuffers.UOffsetT) (*Feature, error) {
+	buf, pos, err := root("Feature", buf, offset, false)
+	if err != nil {
+		return nil, err
+	}
+	x := &Feature{}
+	return x, x.t.init("Feature", buf, pos)
+}
+
+// GetSizePrefixedRootAsFeature returns the Feature which is the root
+// table of the size-prefixed buffer starting at offset within buf,
+// which is how each feature is stored in a FlatGeobuf file. The Feature
+// does not read past the size given by the prefix.
+func GetSizePrefixedRootAsFeature(buf []byte, offset flatbuffers.UOffsetT) (*Feature, error) {
+	buf, pos, err := root("Feature", buf, offset, true)
+	if err != nil {
+		return nil, err
+	}
+	x := &Feature{}
+	return x, x.t.init("Feature", buf, pos)
+}
+
+// Init points rcv at the Feature table at position i of buf.
+func (rcv *Feature) Init(buf []byte, i flatbuffers.UOffsetT) error {
+	return rcv.t.init("Feature", buf, int(i))
+}
+
+// Geometry points obj at the geometry table and returns it, or returns
+// nil if the feature has no geometry.
+func (rcv *Feature) Geometry(obj *Geometry) (*Geometry, error) {
+	i, err := rcv.t.indirect(4, "geometry")
+	if i < 0 {
+		return nil, err
+	} else if err = obj.t.init("Geometry", rcv.t.buf, i); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// PropertiesBytes returns the property buffer of the feature, which
+// refers to the underlying buffer, or nil if the feature has none.
+func (rcv *Feature) PropertiesBytes() ([]byte, error) {
+	return rcv.t.bytes(6, "properties", false)
+}
+
+func (rcv *Feature) PropertiesLength() (int, error) {
+	return rcv.t.vectorLen(6, 1, "properties")
+}
+
+// Columns points obj at column j of the feature's own schema. It
+// returns an error if j is out of range.
+func (rcv *Feature) Columns(obj *Column, j int) error {
+	return rcv.t.subtable(&obj.t, "Column", 8, j, "columns")
+}
+
+func (rcv *Feature) ColumnsLength() (int, error) {
+	return rcv.t.vectorLen(8, flatbuffers.SizeUOffsetT, "columns")
+}
+
+// Schema returns the feature's own columns as a props.Schema. Most
+// features have no columns of their own, and use the columns of the
+// header.
+func (rcv *Feature) Schema() (*props.Schema, error) {
+	return schema(&rcv.t, 8)
+}
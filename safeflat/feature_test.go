@@ -0,0 +1,221 @@
+package safeflat
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/gogama/flatgeobuf-convert/props"
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// readGeometry reads every field of g and its parts, and returns the
+// first error.
+func readGeometry(g *Geometry) error {
+	type vector struct {
+		length func() (int, error)
+		at     func(j int) error
+	}
+	vectors := []vector{
+		{g.EndsLength, func(j int) error { _, err := g.Ends(j); return err }},
+		{g.XyLength, func(j int) error { _, err := g.Xy(j); return err }},
+		{g.ZLength, func(j int) error { _, err := g.Z(j); return err }},
+		{g.MLength, func(j int) error { _, err := g.M(j); return err }},
+		{g.TLength, func(j int) error { _, err := g.T(j); return err }},
+		{g.TmLength, func(j int) error { _, err := g.Tm(j); return err }},
+	}
+	for _, v := range vectors {
+		n, err := v.length()
+		if err != nil {
+			return err
+		}
+		for j := 0; j < n; j++ {
+			if err = v.at(j); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := g.Type(); err != nil {
+		return err
+	}
+	n, err := g.PartsLength()
+	if err != nil {
+		return err
+	}
+	for j := 0; j < n; j++ {
+		var part Geometry
+		if err = g.Parts(&part, j); err != nil {
+			return err
+		} else if err = readGeometry(&part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readFeature reads every field of f, and returns the first error.
+func readFeature(f *Feature) error {
+	var obj Geometry
+	g, err := f.Geometry(&obj)
+	if err != nil {
+		return err
+	} else if g != nil {
+		if err = readGeometry(g); err != nil {
+			return err
+		}
+	}
+	if _, err = f.PropertiesBytes(); err != nil {
+		return err
+	} else if _, err = f.PropertiesLength(); err != nil {
+		return err
+	}
+	_, err = f.Schema()
+	return err
+}
+
+// multiLine is a valid geometry with parts.
+var multiLine = geom{
+	typ: flat.GeometryTypeMultiLineString,
+	parts: []geom{
+		{typ: flat.GeometryTypeLineString, xy: []float64{0, 0, 1, 1}, m: []float64{5, 6}},
+		{typ: flat.GeometryTypeLineString, xy: []float64{2, 2, 3, 3, 4, 4}, ends: []uint32{3}, tm: []uint64{1, 2, 3}},
+	},
+}
+
+func TestFeature(t *testing.T) {
+	properties := testProperties(t)
+	buf := buildFeature(&multiLine, properties, testColumns)
+	f, err := GetSizePrefixedRootAsFeature(buf, 0)
+	if err != nil {
+		t.Fatalf("GetSizePrefixedRootAsFeature: %v", err)
+	}
+	want := flat.GetSizePrefixedRootAsFeature(buf, 0)
+	if b, err := f.PropertiesBytes(); err != nil || !bytes.Equal(b, properties) {
+		t.Errorf("PropertiesBytes: got %x, %v, want %x", b, err, properties)
+	}
+	if n, err := f.ColumnsLength(); err != nil || n != want.ColumnsLength() {
+		t.Errorf("ColumnsLength: got %d, %v, want %d", n, err, want.ColumnsLength())
+	}
+	var col Column
+	if err := f.Columns(&col, 2); err != nil {
+		t.Errorf("Columns: %v", err)
+	} else if c, err := col.Column(); err != nil || c.Name != "price" || c.Precision != 8 {
+		t.Errorf("Column: got %+v, %v", c, err)
+	}
+	var obj Geometry
+	g, err := f.Geometry(&obj)
+	if err != nil || g == nil {
+		t.Fatalf("Geometry: got %v, %v", g, err)
+	}
+	wantGeom := want.Geometry(nil)
+	if typ, err := g.Type(); err != nil || typ != wantGeom.Type() {
+		t.Errorf("Type: got %s, %v, want %s", typ, err, wantGeom.Type())
+	}
+	if n, err := g.PartsLength(); err != nil || n != wantGeom.PartsLength() {
+		t.Fatalf("PartsLength: got %d, %v, want %d", n, err, wantGeom.PartsLength())
+	}
+	for j := 0; j < wantGeom.PartsLength(); j++ {
+		var part Geometry
+		var wantPart flat.Geometry
+		wantGeom.Parts(&wantPart, j)
+		if err := g.Parts(&part, j); err != nil {
+			t.Fatalf("Parts(%d): %v", j, err)
+		}
+		if n, err := part.XyLength(); err != nil || n != wantPart.XyLength() {
+			t.Errorf("part %d: XyLength: got %d, %v, want %d", j, n, err, wantPart.XyLength())
+		}
+		for k := 0; k < wantPart.XyLength(); k++ {
+			if v, err := part.Xy(k); err != nil || v != wantPart.Xy(k) {
+				t.Errorf("part %d: Xy(%d): got %v, %v, want %v", j, k, v, err, wantPart.Xy(k))
+			}
+		}
+		if n, err := part.MLength(); err != nil || n != wantPart.MLength() {
+			t.Errorf("part %d: MLength: got %d, %v, want %d", j, n, err, wantPart.MLength())
+		}
+		if n, err := part.TmLength(); err != nil || n != wantPart.TmLength() {
+			t.Errorf("part %d: TmLength: got %d, %v, want %d", j, n, err, wantPart.TmLength())
+		}
+		if _, err := part.Xy(wantPart.XyLength()); !errors.Is(err, ErrCorrupt) {
+			t.Errorf("part %d: Xy out of range: got error %v, want ErrCorrupt", j, err)
+		}
+	}
+	if err := readFeature(f); err != nil {
+		t.Errorf("readFeature: %v", err)
+	}
+}
+
+func TestFeatureAbsentFields(t *testing.T) {
+	f, err := GetSizePrefixedRootAsFeature(buildFeature(nil, nil, nil), 0)
+	if err != nil {
+		t.Fatalf("GetSizePrefixedRootAsFeature: %v", err)
+	}
+	var obj Geometry
+	if g, err := f.Geometry(&obj); err != nil || g != nil {
+		t.Errorf("Geometry: got %v, %v, want nil", g, err)
+	}
+	if b, err := f.PropertiesBytes(); err != nil || b != nil {
+		t.Errorf("PropertiesBytes: got %x, %v, want nil", b, err)
+	}
+	if s, err := f.Schema(); err != nil || s.ColumnsLength() != 0 {
+		t.Errorf("Schema: got %v, %v, want no columns", s, err)
+	}
+}
+
+func TestFeatureTruncated(t *testing.T) {
+	buf := buildFeature(&multiLine, testProperties(t), testColumns)[flatbuffers.SizeUint32:]
+	for n := 0; n < len(buf); n++ {
+		f, err := GetRootAsFeature(buf[:n], 0)
+		if err == nil {
+			err = readFeature(f)
+		}
+		if err != nil && !errors.Is(err, ErrCorrupt) {
+			t.Errorf("%d bytes: got error %v, want ErrCorrupt", n, err)
+		} else if err == nil && n < len(buf)-flatbuffers.SizeFloat64 {
+			t.Errorf("%d of %d bytes: got no error", n, len(buf))
+		}
+	}
+}
+
+func TestFeatureMutated(t *testing.T) {
+	mutations(buildFeature(&multiLine, testProperties(t), testColumns), func(b []byte) {
+		f, err := GetSizePrefixedRootAsFeature(b, 0)
+		if err == nil {
+			err = readFeature(f)
+		}
+		if err != nil && !errors.Is(err, ErrCorrupt) && !errors.Is(err, props.ErrInvalidSchema) {
+			t.Fatalf("%x: got error %v, want ErrCorrupt", b, err)
+		}
+	})
+}
+
+func TestInvalidOffsets(t *testing.T) {
+	testCases := []struct {
+		name string
+		buf  []byte
+	}{
+		{"empty", nil},
+		{"short root offset", []byte{1, 0}},
+		{"root offset past end", []byte{0xff, 0xff, 0xff, 0x7f}},
+		{"root offset max", []byte{0xff, 0xff, 0xff, 0xff}},
+		{"vtable before buffer", []byte{8, 0, 0, 0, 0, 0, 0, 0, 0x7f, 0, 0, 0}},
+		{"vtable after buffer", []byte{4, 0, 0, 0, 0, 0, 0, 0x80}},
+		{"vtable size odd", []byte{8, 0, 0, 0, 5, 0, 4, 0, 4, 0, 0, 0}},
+		{"table size too large", []byte{8, 0, 0, 0, 4, 0, 0x40, 0, 4, 0, 0, 0}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if _, err := GetRootAsFeature(testCase.buf, 0); !errors.Is(err, ErrCorrupt) {
+				t.Errorf("GetRootAsFeature: got error %v, want ErrCorrupt", err)
+			}
+			if _, err := GetRootAsHeader(testCase.buf, 0); !errors.Is(err, ErrCorrupt) {
+				t.Errorf("GetRootAsHeader: got error %v, want ErrCorrupt", err)
+			}
+			var e *Error
+			_, err := GetRootAsHeader(testCase.buf, 0)
+			if !errors.As(err, &e) || e.Table != "Header" {
+				t.Errorf("got error %#v, want *Error for table Header", err)
+			}
+		})
+	}
+}
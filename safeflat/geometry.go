@@ -0,0 +1,83 @@
+package safeflat
+
+import (
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// Geometry is a bounds-checked reader for a flat.Geometry table, in the
+// same way as Header is for flat.Header.
+//
+// Element accessors such as Xy check their index against the vector
+// length and return an error if it is out of range, so loop over
+// XyLength rather than relying on the error.
+type Geometry struct {
+	t table
+}
+
+// Init points rcv at the Geometry table at position i of buf.
+func (rcv *Geometry) Init(buf []byte, i flatbuffers.UOffsetT) error {
+	return rcv.t.init("Geometry", buf, int(i))
+}
+
+func (rcv *Geometry) Ends(j int) (uint32, error) {
+	return rcv.t.uint32At(4, j, "ends")
+}
+
+func (rcv *Geometry) EndsLength() (int, error) {
+	return rcv.t.vectorLen(4, flatbuffers.SizeUint32, "ends")
+}
+
+func (rcv *Geometry) Xy(j int) (float64, error) {
+	return rcv.t.float64At(6, j, "xy")
+}
+
+func (rcv *Geometry) XyLength() (int, error) {
+	return rcv.t.vectorLen(6, flatbuffers.SizeFloat64, "xy")
+}
+
+func (rcv *Geometry) Z(j int) (float64, error) {
+	return rcv.t.float64At(8, j, "z")
+}
+
+func (rcv *Geometry) ZLength() (int, error) {
+	return rcv.t.vectorLen(8, flatbuffers.SizeFloat64, "z")
+}
+
+func (rcv *Geometry) M(j int) (float64, error) {
+	return rcv.t.float64At(10, j, "m")
+}
+
+func (rcv *Geometry) MLength() (int, error) {
+	return rcv.t.vectorLen(10, flatbuffers.SizeFloat64, "m")
+}
+
+func (rcv *Geometry) T(j int) (float64, error) {
+	return rcv.t.float64At(12, j, "t")
+}
+
+func (rcv *Geometry) TLength() (int, error) {
+	return rcv.t.vectorLen(12, flatbuffers.SizeFloat64, "t")
+}
+
+func (rcv *Geometry) Tm(j int) (uint64, error) {
+	return rcv.t.uint64At(14, j, "tm")
+}
+
+func (rcv *Geometry) TmLength() (int, error) {
+	return rcv.t.vectorLen(14, flatbuffers.SizeUint64, "tm")
+}
+
+func (rcv *Geometry) Type() (flat.GeometryType, error) {
+	b, err := rcv.t.getByte(16, "type", 0)
+	return flat.GeometryType(b), err
+}
+
+// Parts points obj at part j. It returns an error if j is out of range.
+func (rcv *Geometry) Parts(obj *Geometry, j int) error {
+	return rcv.t.subtable(&obj.t, "Geometry", 18, j, "parts")
+}
+
+func (rcv *Geometry) PartsLength() (int, error) {
+	return rcv.t.vectorLen(18, flatbuffers.SizeUOffsetT, "parts")
+}
@@ -0,0 +1,143 @@
+package safeflat
+
+import (
+	"github.com/gogama/flatgeobuf-convert/props"
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// Header is a bounds-checked reader for a flat.Header table. Its
+// accessors return the same values as those of flat.Header, but return
+// an error wrapping ErrCorrupt, instead of panicking or reading out of
+// bounds, if the buffer is corrupt.
+type Header struct {
+	t table
+}
+
+// GetRootAsHeader returns the Header which is the root table of the
+// buffer starting at offset within buf.
+func GetRootAsHeader(buf []byte, offset flatbuffers.UOffsetT) (*Header, error) {
+	buf, pos, err := root("Header", buf, offset, false)
+	if err != nil {
+		return nil, err
+	}
+	x := &Header{}
+	return x, x.t.init("Header", buf, pos)
+}
+
+// GetSizePrefixedRootAsHeader returns the Header which is the root table
+// of the size-prefixed buffer starting at offset within buf, which is
+// how the header is stored in a FlatGeobuf file after the magic bytes.
+// The Header does not read past the size given by the prefix.
+func GetSizePrefixedRootAsHeader(buf []byte, offset flatbuffers.UOffsetT) (*Header, error) {
+	buf, pos, err := root("Header", buf, offset, true)
+	if err != nil {
+		return nil, err
+	}
+	x := &Header{}
+	return x, x.t.init("Header", buf, pos)
+}
+
+// Init points rcv at the Header table at position i of buf. To check a
+// flat.Header h, use rcv.Init(h.Table().Bytes, h.Table().Pos).
+func (rcv *Header) Init(buf []byte, i flatbuffers.UOffsetT) error {
+	return rcv.t.init("Header", buf, int(i))
+}
+
+func (rcv *Header) Name() ([]byte, error) {
+	return rcv.t.bytes(4, "name", true)
+}
+
+func (rcv *Header) Envelope(j int) (float64, error) {
+	return rcv.t.float64At(6, j, "envelope")
+}
+
+func (rcv *Header) EnvelopeLength() (int, error) {
+	return rcv.t.vectorLen(6, flatbuffers.SizeFloat64, "envelope")
+}
+
+func (rcv *Header) GeometryType() (flat.GeometryType, error) {
+	b, err := rcv.t.getByte(8, "geometry_type", 0)
+	return flat.GeometryType(b), err
+}
+
+func (rcv *Header) HasZ() (bool, error) {
+	return rcv.t.getBool(10, "has_z", false)
+}
+
+func (rcv *Header) HasM() (bool, error) {
+	return rcv.t.getBool(12, "has_m", false)
+}
+
+func (rcv *Header) HasT() (bool, error) {
+	return rcv.t.getBool(14, "has_t", false)
+}
+
+func (rcv *Header) HasTm() (bool, error) {
+	return rcv.t.getBool(16, "has_tm", false)
+}
+
+// Columns points obj at column j. Unlike flat.Header, it returns an
+// error if j is out of range.
+func (rcv *Header) Columns(obj *Column, j int) error {
+	return rcv.t.subtable(&obj.t, "Column", 18, j, "columns")
+}
+
+func (rcv *Header) ColumnsLength() (int, error) {
+	return rcv.t.vectorLen(18, flatbuffers.SizeUOffsetT, "columns")
+}
+
+func (rcv *Header) FeaturesCount() (uint64, error) {
+	return rcv.t.getUint64(20, "features_count", 0)
+}
+
+func (rcv *Header) IndexNodeSize() (uint16, error) {
+	return rcv.t.getUint16(22, "index_node_size", 16)
+}
+
+// Crs points obj at the CRS table and returns it, or returns nil if
+// the header has no CRS.
+func (rcv *Header) Crs(obj *Crs) (*Crs, error) {
+	i, err := rcv.t.indirect(24, "crs")
+	if i < 0 {
+		return nil, err
+	} else if err = obj.t.init("Crs", rcv.t.buf, i); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (rcv *Header) Title() ([]byte, error) {
+	return rcv.t.bytes(26, "title", true)
+}
+
+func (rcv *Header) Description() ([]byte, error) {
+	return rcv.t.bytes(28, "description", true)
+}
+
+func (rcv *Header) Metadata() ([]byte, error) {
+	return rcv.t.bytes(30, "metadata", true)
+}
+
+// Schema returns the columns of the header as a props.Schema.
+func (rcv *Header) Schema() (*props.Schema, error) {
+	return schema(&rcv.t, 18)
+}
+
+// schema reads the vector of columns in vtable slot slot of t.
+func schema(t *table, slot int) (*props.Schema, error) {
+	n, err := t.vectorLen(slot, flatbuffers.SizeUOffsetT, "columns")
+	if err != nil {
+		return nil, err
+	}
+	cols := make([]props.Column, n)
+	var obj Column
+	for j := range cols {
+		if err = t.subtable(&obj.t, "Column", slot, j, "columns"); err != nil {
+			return nil, err
+		} else if cols[j], err = obj.Column(); err != nil {
+			return nil, err
+		}
+	}
+	return props.NewSchema(cols), nil
+}
@@ -0,0 +1,180 @@
+package safeflat
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gogama/flatgeobuf-convert/props"
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// readHeader reads every field of h, including the columns and CRS,
+// and returns the first error.
+func readHeader(h *Header) error {
+	if _, err := h.Name(); err != nil {
+		return err
+	}
+	n, err := h.EnvelopeLength()
+	if err != nil {
+		return err
+	}
+	for j := 0; j < n; j++ {
+		if _, err = h.Envelope(j); err != nil {
+			return err
+		}
+	}
+	if _, err = h.GeometryType(); err != nil {
+		return err
+	} else if _, err = h.HasZ(); err != nil {
+		return err
+	} else if _, err = h.HasM(); err != nil {
+		return err
+	} else if _, err = h.HasT(); err != nil {
+		return err
+	} else if _, err = h.HasTm(); err != nil {
+		return err
+	} else if _, err = h.Schema(); err != nil {
+		return err
+	} else if _, err = h.FeaturesCount(); err != nil {
+		return err
+	} else if _, err = h.IndexNodeSize(); err != nil {
+		return err
+	}
+	var obj Crs
+	crs, err := h.Crs(&obj)
+	if err != nil {
+		return err
+	} else if crs != nil {
+		if _, err = crs.Org(); err != nil {
+			return err
+		} else if _, err = crs.Code(); err != nil {
+			return err
+		} else if _, err = crs.Name(); err != nil {
+			return err
+		} else if _, err = crs.Description(); err != nil {
+			return err
+		} else if _, err = crs.Wkt(); err != nil {
+			return err
+		} else if _, err = crs.CodeString(); err != nil {
+			return err
+		}
+	}
+	if _, err = h.Title(); err != nil {
+		return err
+	} else if _, err = h.Description(); err != nil {
+		return err
+	}
+	_, err = h.Metadata()
+	return err
+}
+
+func TestHeader(t *testing.T) {
+	buf := buildHeader(testColumns)
+	h, err := GetSizePrefixedRootAsHeader(buf, 0)
+	if err != nil {
+		t.Fatalf("GetSizePrefixedRootAsHeader: %v", err)
+	}
+	want := flat.GetSizePrefixedRootAsHeader(buf, 0)
+	if name, err := h.Name(); err != nil || string(name) != string(want.Name()) {
+		t.Errorf("Name: got %q, %v, want %q", name, err, want.Name())
+	}
+	if n, err := h.EnvelopeLength(); err != nil || n != want.EnvelopeLength() {
+		t.Fatalf("EnvelopeLength: got %d, %v, want %d", n, err, want.EnvelopeLength())
+	}
+	for j := 0; j < want.EnvelopeLength(); j++ {
+		if v, err := h.Envelope(j); err != nil || v != want.Envelope(j) {
+			t.Errorf("Envelope(%d): got %v, %v, want %v", j, v, err, want.Envelope(j))
+		}
+	}
+	if _, err := h.Envelope(want.EnvelopeLength()); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Envelope out of range: got error %v, want ErrCorrupt", err)
+	}
+	if g, err := h.GeometryType(); err != nil || g != want.GeometryType() {
+		t.Errorf("GeometryType: got %s, %v, want %s", g, err, want.GeometryType())
+	}
+	if z, err := h.HasZ(); err != nil || !z {
+		t.Errorf("HasZ: got %t, %v, want true", z, err)
+	}
+	if m, err := h.HasM(); err != nil || m {
+		t.Errorf("HasM: got %t, %v, want false", m, err)
+	}
+	if n, err := h.FeaturesCount(); err != nil || n != 12 {
+		t.Errorf("FeaturesCount: got %d, %v, want 12", n, err)
+	}
+	if n, err := h.IndexNodeSize(); err != nil || n != want.IndexNodeSize() {
+		t.Errorf("IndexNodeSize: got %d, %v, want %d", n, err, want.IndexNodeSize())
+	}
+	var crs Crs
+	if c, err := h.Crs(&crs); err != nil || c == nil {
+		t.Errorf("Crs: got %v, %v", c, err)
+	} else if code, err := c.Code(); err != nil || code != 4326 {
+		t.Errorf("Crs.Code: got %d, %v, want 4326", code, err)
+	} else if org, err := c.Org(); err != nil || string(org) != "EPSG" {
+		t.Errorf("Crs.Org: got %q, %v, want EPSG", org, err)
+	} else if wkt, err := c.Wkt(); err != nil || wkt != nil {
+		t.Errorf("Crs.Wkt: got %q, %v, want nil for absent field", wkt, err)
+	}
+	if s, err := h.Description(); err != nil || s != nil {
+		t.Errorf("Description: got %q, %v, want nil for absent field", s, err)
+	}
+	if s, err := h.Metadata(); err != nil || string(s) != string(want.Metadata()) {
+		t.Errorf("Metadata: got %q, %v, want %q", s, err, want.Metadata())
+	}
+	schema, err := h.Schema()
+	if err != nil {
+		t.Fatalf("Schema: %v", err)
+	}
+	if schema.ColumnsLength() != want.ColumnsLength() {
+		t.Fatalf("Schema: got %d columns, want %d", schema.ColumnsLength(), want.ColumnsLength())
+	}
+	for i := 0; i < want.ColumnsLength(); i++ {
+		var obj flat.Column
+		want.Columns(&obj, i)
+		wantCol, err := props.ColumnFromFlat(&obj)
+		if err != nil {
+			t.Fatalf("ColumnFromFlat: %v", err)
+		}
+		if got := schema.Column(i); !reflect.DeepEqual(got, wantCol) {
+			t.Errorf("column %d: got %+v, want %+v", i, got, wantCol)
+		}
+	}
+	var col Column
+	if err := h.Columns(&col, len(testColumns)); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Columns out of range: got error %v, want ErrCorrupt", err)
+	}
+}
+
+func TestHeaderTruncated(t *testing.T) {
+	buf := buildHeader(testColumns)[flatbuffers.SizeUint32:]
+	for n := 0; n < len(buf); n++ {
+		h, err := GetRootAsHeader(buf[:n], 0)
+		if err == nil {
+			err = readHeader(h)
+		}
+		if err != nil && !errors.Is(err, ErrCorrupt) {
+			t.Errorf("%d bytes: got error %v, want ErrCorrupt", n, err)
+		} else if err == nil && n < len(buf)-flatbuffers.SizeFloat64 {
+			t.Errorf("%d of %d bytes: got no error", n, len(buf))
+		}
+	}
+	// The size prefix must not let the header read past the end of the
+	// buffer, nor past the size it gives.
+	sized := buildHeader(testColumns)
+	if _, err := GetSizePrefixedRootAsHeader(sized[:len(sized)-1], 0); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("short size-prefixed buffer: got error %v, want ErrCorrupt", err)
+	}
+}
+
+func TestHeaderMutated(t *testing.T) {
+	mutations(buildHeader(testColumns), func(b []byte) {
+		h, err := GetSizePrefixedRootAsHeader(b, 0)
+		if err == nil {
+			err = readHeader(h)
+		}
+		if err != nil && !errors.Is(err, ErrCorrupt) && !errors.Is(err, props.ErrInvalidSchema) {
+			t.Fatalf("%x: got error %v, want ErrCorrupt", b, err)
+		}
+	})
+}
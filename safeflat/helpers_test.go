@@ -0,0 +1,204 @@
+package safeflat
+
+import (
+	"testing"
+
+	"github.com/gogama/flatgeobuf-convert/props"
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// testColumns are the columns of the header built by buildHeader.
+var testColumns = []props.Column{
+	{Name: "id", Type: flat.ColumnTypeLong, Required: true, PrimaryKey: true},
+	{Name: "name", Type: flat.ColumnTypeString, Width: 32, Title: "Name"},
+	{Name: "price", Type: flat.ColumnTypeDouble, Precision: 8, Scale: 2, Metadata: `{"unit":"EUR"}`},
+}
+
+// geom describes a geometry table for buildFeature.
+type geom struct {
+	typ   flat.GeometryType
+	ends  []uint32
+	xy    []float64
+	z     []float64
+	m     []float64
+	tm    []uint64
+	parts []geom
+}
+
+// point is a valid single point geometry.
+var point = geom{typ: flat.GeometryTypePoint, xy: []float64{1, 2}, z: []float64{3}}
+
+func float64Vector(b *flatbuffers.Builder, v []float64) flatbuffers.UOffsetT {
+	b.StartVector(flatbuffers.SizeFloat64, len(v), flatbuffers.SizeFloat64)
+	for i := len(v) - 1; i >= 0; i-- {
+		b.PrependFloat64(v[i])
+	}
+	return b.EndVector(len(v))
+}
+
+func offsetVector(b *flatbuffers.Builder, v []flatbuffers.UOffsetT) flatbuffers.UOffsetT {
+	b.StartVector(flatbuffers.SizeUOffsetT, len(v), flatbuffers.SizeUOffsetT)
+	for i := len(v) - 1; i >= 0; i-- {
+		b.PrependUOffsetT(v[i])
+	}
+	return b.EndVector(len(v))
+}
+
+func columnVector(b *flatbuffers.Builder, cols []props.Column) flatbuffers.UOffsetT {
+	offsets := make([]flatbuffers.UOffsetT, len(cols))
+	for i := range cols {
+		offsets[i] = cols[i].ToBuilder(b)
+	}
+	return offsetVector(b, offsets)
+}
+
+func addGeometry(b *flatbuffers.Builder, g geom) flatbuffers.UOffsetT {
+	parts := make([]flatbuffers.UOffsetT, len(g.parts))
+	for i := range g.parts {
+		parts[i] = addGeometry(b, g.parts[i])
+	}
+	var ends, xy, z, m, tm, partsVec flatbuffers.UOffsetT
+	if g.ends != nil {
+		b.StartVector(flatbuffers.SizeUint32, len(g.ends), flatbuffers.SizeUint32)
+		for i := len(g.ends) - 1; i >= 0; i-- {
+			b.PrependUint32(g.ends[i])
+		}
+		ends = b.EndVector(len(g.ends))
+	}
+	if g.xy != nil {
+		xy = float64Vector(b, g.xy)
+	}
+	if g.z != nil {
+		z = float64Vector(b, g.z)
+	}
+	if g.m != nil {
+		m = float64Vector(b, g.m)
+	}
+	if g.tm != nil {
+		b.StartVector(flatbuffers.SizeUint64, len(g.tm), flatbuffers.SizeUint64)
+		for i := len(g.tm) - 1; i >= 0; i-- {
+			b.PrependUint64(g.tm[i])
+		}
+		tm = b.EndVector(len(g.tm))
+	}
+	if g.parts != nil {
+		partsVec = offsetVector(b, parts)
+	}
+	flat.GeometryStart(b)
+	if ends != 0 {
+		flat.GeometryAddEnds(b, ends)
+	}
+	if xy != 0 {
+		flat.GeometryAddXy(b, xy)
+	}
+	if z != 0 {
+		flat.GeometryAddZ(b, z)
+	}
+	if m != 0 {
+		flat.GeometryAddM(b, m)
+	}
+	if tm != 0 {
+		flat.GeometryAddTm(b, tm)
+	}
+	flat.GeometryAddType(b, g.typ)
+	if partsVec != 0 {
+		flat.GeometryAddParts(b, partsVec)
+	}
+	return flat.GeometryEnd(b)
+}
+
+// buildHeader returns a size-prefixed header buffer with every field
+// set, and the columns cols.
+func buildHeader(cols []props.Column) []byte {
+	b := flatbuffers.NewBuilder(0)
+	name := b.CreateString("roads")
+	envelope := float64Vector(b, []float64{-1, -2, 3, 4})
+	columns := columnVector(b, cols)
+	org := b.CreateString("EPSG")
+	crsName := b.CreateString("WGS 84")
+	flat.CrsStart(b)
+	flat.CrsAddOrg(b, org)
+	flat.CrsAddCode(b, 4326)
+	flat.CrsAddName(b, crsName)
+	crs := flat.CrsEnd(b)
+	title := b.CreateString("Roads")
+	metadata := b.CreateString(`{"source":"test"}`)
+	flat.HeaderStart(b)
+	flat.HeaderAddName(b, name)
+	flat.HeaderAddEnvelope(b, envelope)
+	flat.HeaderAddGeometryType(b, flat.GeometryTypeLineString)
+	flat.HeaderAddHasZ(b, true)
+	flat.HeaderAddColumns(b, columns)
+	flat.HeaderAddFeaturesCount(b, 12)
+	flat.HeaderAddIndexNodeSize(b, 0)
+	flat.HeaderAddCrs(b, crs)
+	flat.HeaderAddTitle(b, title)
+	flat.HeaderAddMetadata(b, metadata)
+	flat.FinishSizePrefixedHeaderBuffer(b, flat.HeaderEnd(b))
+	return b.FinishedBytes()
+}
+
+// buildFeature returns a size-prefixed feature buffer with geometry g,
+// if it isn't nil, the property buffer properties, and its own columns
+// cols.
+func buildFeature(g *geom, properties []byte, cols []props.Column) []byte {
+	b := flatbuffers.NewBuilder(0)
+	var geometry, propertiesVec, columns flatbuffers.UOffsetT
+	if g != nil {
+		geometry = addGeometry(b, *g)
+	}
+	if properties != nil {
+		propertiesVec = b.CreateByteVector(properties)
+	}
+	if cols != nil {
+		columns = columnVector(b, cols)
+	}
+	flat.FeatureStart(b)
+	if geometry != 0 {
+		flat.FeatureAddGeometry(b, geometry)
+	}
+	if propertiesVec != 0 {
+		flat.FeatureAddProperties(b, propertiesVec)
+	}
+	if columns != 0 {
+		flat.FeatureAddColumns(b, columns)
+	}
+	flat.FinishSizePrefixedFeatureBuffer(b, flat.FeatureEnd(b))
+	return b.FinishedBytes()
+}
+
+// testProperties returns a property buffer under testColumns with a
+// value in every column.
+func testProperties(t *testing.T) []byte {
+	t.Helper()
+	p := props.NewProps(props.NewSchema(testColumns))
+	if err := p.SetLong(0, 7); err != nil {
+		t.Fatal(err)
+	} else if err = p.SetString(1, "Main Street"); err != nil {
+		t.Fatal(err)
+	} else if err = p.SetDouble(2, 9.99); err != nil {
+		t.Fatal(err)
+	}
+	b, err := p.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// mutations calls f with copies of buf in which each byte in turn is
+// replaced by a value chosen to push offsets and lengths out of range.
+func mutations(buf []byte, f func(b []byte)) {
+	b := make([]byte, len(buf))
+	for i := range buf {
+		for _, x := range []byte{0x00, 0x01, 0x7f, 0x80, 0xff} {
+			if buf[i] == x {
+				continue
+			}
+			copy(b, buf)
+			b[i] = x
+			f(b)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package safeflat
+
+import (
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// table is a bounds-checked view of a FlatBuffers table. Every position
+// it hands out has been checked against the buffer, so the accessors
+// built on it never read outside the buffer.
+type table struct {
+	// name is the table type name, used to describe problems.
+	name string
+	buf  []byte
+	// pos is the position of the table within buf.
+	pos int
+	// vtable is the position of the table's vtable within buf.
+	vtable int
+	// vsize is the size of the vtable in bytes, including its two
+	// 16-bit size fields.
+	vsize int
+	// tsize is the size of the inline part of the table in bytes.
+	tsize int
+}
+
+// inRange reports whether the n bytes starting at pos are within buf.
+// It is written so that neither addition can overflow.
+func inRange(buf []byte, pos, n int) bool {
+	return pos >= 0 && n >= 0 && pos <= len(buf) && n <= len(buf)-pos
+}
+
+// init points t at the table at position pos of buf, checking that the
+// table and its vtable are within buf.
+func (t *table) init(name string, buf []byte, pos int) error {
+	*t = table{name: name, buf: buf, pos: pos}
+	if !inRange(buf, pos, flatbuffers.SizeSOffsetT) {
+		return t.err("", pos, "table offset is out of range")
+	}
+	vtable := int64(pos) - int64(flatbuffers.GetSOffsetT(buf[pos:]))
+	if vtable < 0 || vtable > int64(len(buf)) || !inRange(buf, int(vtable), 2*flatbuffers.SizeVOffsetT) {
+		return t.err("", pos, "vtable offset is out of range")
+	}
+	t.vtable = int(vtable)
+	t.vsize = int(flatbuffers.GetVOffsetT(buf[t.vtable:]))
+	t.tsize = int(flatbuffers.GetVOffsetT(buf[t.vtable+flatbuffers.SizeVOffsetT:]))
+	if t.vsize < 2*flatbuffers.SizeVOffsetT || t.vsize%flatbuffers.SizeVOffsetT != 0 {
+		return t.err("", t.vtable, "invalid vtable size %d", t.vsize)
+	} else if !inRange(buf, t.vtable, t.vsize) {
+		return t.err("", t.vtable, "vtable of %d bytes extends past end of buffer", t.vsize)
+	} else if t.tsize < flatbuffers.SizeSOffsetT {
+		return t.err("", t.vtable, "invalid table size %d", t.tsize)
+	} else if !inRange(buf, pos, t.tsize) {
+		return t.err("", pos, "table of %d bytes extends past end of buffer", t.tsize)
+	}
+	return nil
+}
+
+// field returns the position of the size-byte field in vtable slot
+// slot, or -1 if the field is absent.
+func (t *table) field(slot, size int, field string) (int, error) {
+	if slot+flatbuffers.SizeVOffsetT > t.vsize {
+		return -1, nil
+	}
+	off := int(flatbuffers.GetVOffsetT(t.buf[t.vtable+slot:]))
+	if off == 0 {
+		return -1, nil
+	} else if off < flatbuffers.SizeSOffsetT || off+size > t.tsize {
+		return -1, t.err(field, t.vtable+slot, "field offset %d is outside table of %d bytes", off, t.tsize)
+	}
+	return t.pos + off, nil
+}
+
+// indirect returns the position referred to by the offset field in
+// vtable slot slot, or -1 if the field is absent.
+func (t *table) indirect(slot int, field string) (int, error) {
+	i, err := t.field(slot, flatbuffers.SizeUOffsetT, field)
+	if i < 0 {
+		return -1, err
+	}
+	return t.follow(i, field)
+}
+
+// follow returns the position referred to by the offset at position i.
+func (t *table) follow(i int, field string) (int, error) {
+	target := int64(i) + int64(flatbuffers.GetUOffsetT(t.buf[i:]))
+	if target >= int64(len(t.buf)) {
+		return -1, t.err(field, i, "offset %d is out of range", target-int64(i))
+	}
+	return int(target), nil
+}
+
+// vector returns the position of the first element, and the number of
+// elements, of the vector in vtable slot slot whose elements are size
+// bytes each. The return values are -1 and 0 if the field is absent.
+func (t *table) vector(slot, size int, field string) (int, int, error) {
+	i, err := t.indirect(slot, field)
+	if i < 0 {
+		return -1, 0, err
+	}
+	return t.vectorAt(i, size, field)
+}
+
+// vectorAt checks the vector at position i, in the same way as vector.
+func (t *table) vectorAt(i, size int, field string) (int, int, error) {
+	if !inRange(t.buf, i, flatbuffers.SizeUOffsetT) {
+		return -1, 0, t.err(field, i, "vector length is out of range")
+	}
+	n := flatbuffers.GetUOffsetT(t.buf[i:])
+	start := i + flatbuffers.SizeUOffsetT
+	if uint64(n)*uint64(size) > uint64(len(t.buf)-start) {
+		return -1, 0, t.err(field, i, "vector of %d elements of %d bytes extends past end of buffer", n, size)
+	}
+	return start, int(n), nil
+}
+
+// bytes returns the contents of the string or byte vector in vtable slot
+// slot, or nil if the field is absent. Strings must have the zero byte
+// terminator which FlatBuffers writes after them.
+func (t *table) bytes(slot int, field string, str bool) ([]byte, error) {
+	start, n, err := t.vector(slot, 1, field)
+	if start < 0 {
+		return nil, err
+	} else if str && (!inRange(t.buf, start, n+1) || t.buf[start+n] != 0) {
+		return nil, t.err(field, start, "string of %d bytes is not terminated", n)
+	}
+	return t.buf[start : start+n : start+n], nil
+}
+
+// element returns the position of element j of a vector of n elements
+// of size bytes starting at start, checking j.
+func (t *table) element(start, n, j, size int, field string) (int, error) {
+	if j < 0 || j >= n {
+		return -1, t.err(field, start, "index %d out of range for vector of %d elements", j, n)
+	}
+	return start + j*size, nil
+}
+
+// subtable initializes sub to the table at element j of the vector of
+// tables in vtable slot slot.
+func (t *table) subtable(sub *table, name string, slot, j int, field string) error {
+	start, n, err := t.vector(slot, flatbuffers.SizeUOffsetT, field)
+	if err != nil {
+		return err
+	}
+	i, err := t.element(start, n, j, flatbuffers.SizeUOffsetT, field)
+	if err != nil {
+		return err
+	}
+	target, err := t.follow(i, field)
+	if err != nil {
+		return err
+	}
+	return sub.init(name, t.buf, target)
+}
+
+func (t *table) getBool(slot int, field string, def bool) (bool, error) {
+	i, err := t.field(slot, flatbuffers.SizeBool, field)
+	if i < 0 {
+		return def, err
+	}
+	return flatbuffers.GetBool(t.buf[i:]), nil
+}
+
+func (t *table) getByte(slot int, field string, def byte) (byte, error) {
+	i, err := t.field(slot, flatbuffers.SizeByte, field)
+	if i < 0 {
+		return def, err
+	}
+	return flatbuffers.GetByte(t.buf[i:]), nil
+}
+
+func (t *table) getUint16(slot int, field string, def uint16) (uint16, error) {
+	i, err := t.field(slot, flatbuffers.SizeUint16, field)
+	if i < 0 {
+		return def, err
+	}
+	return flatbuffers.GetUint16(t.buf[i:]), nil
+}
+
+func (t *table) getInt32(slot int, field string, def int32) (int32, error) {
+	i, err := t.field(slot, flatbuffers.SizeInt32, field)
+	if i < 0 {
+		return def, err
+	}
+	return flatbuffers.GetInt32(t.buf[i:]), nil
+}
+
+func (t *table) getUint64(slot int, field string, def uint64) (uint64, error) {
+	i, err := t.field(slot, flatbuffers.SizeUint64, field)
+	if i < 0 {
+		return def, err
+	}
+	return flatbuffers.GetUint64(t.buf[i:]), nil
+}
+
+// vectorLen returns the number of elements of the vector in vtable slot
+// slot, or 0 if the field is absent.
+func (t *table) vectorLen(slot, size int, field string) (int, error) {
+	_, n, err := t.vector(slot, size, field)
+	return n, err
+}
+
+func (t *table) uint32At(slot, j int, field string) (uint32, error) {
+	start, n, err := t.vector(slot, flatbuffers.SizeUint32, field)
+	if err != nil {
+		return 0, err
+	}
+	i, err := t.element(start, n, j, flatbuffers.SizeUint32, field)
+	if err != nil {
+		return 0, err
+	}
+	return flatbuffers.GetUint32(t.buf[i:]), nil
+}
+
+func (t *table) uint64At(slot, j int, field string) (uint64, error) {
+	start, n, err := t.vector(slot, flatbuffers.SizeUint64, field)
+	if err != nil {
+		return 0, err
+	}
+	i, err := t.element(start, n, j, flatbuffers.SizeUint64, field)
+	if err != nil {
+		return 0, err
+	}
+	return flatbuffers.GetUint64(t.buf[i:]), nil
+}
+
+func (t *table) float64At(slot, j int, field string) (float64, error) {
+	start, n, err := t.vector(slot, flatbuffers.SizeFloat64, field)
+	if err != nil {
+		return 0, err
+	}
+	i, err := t.element(start, n, j, flatbuffers.SizeFloat64, field)
+	if err != nil {
+		return 0, err
+	}
+	return flatbuffers.GetFloat64(t.buf[i:]), nil
+}
+
+// root returns the position of the root table of a buffer which starts
+// at offset, optionally with a 32-bit size prefix. If there is a size
+// prefix, buf is cut down to the size it gives.
+func root(name string, buf []byte, offset flatbuffers.UOffsetT, sizePrefixed bool) ([]byte, int, error) {
+	t := table{name: name}
+	i := int64(offset)
+	if sizePrefixed {
+		if !inRange(buf, int(i), flatbuffers.SizeUint32) || i > int64(len(buf)) {
+			return nil, 0, t.err("", int(offset), "size prefix is out of range")
+		}
+		size := int64(flatbuffers.GetUint32(buf[i:]))
+		i += flatbuffers.SizeUint32
+		if size > int64(len(buf))-i {
+			return nil, 0, t.err("", int(offset), "size prefix %d exceeds buffer of %d bytes", size, int64(len(buf))-i)
+		}
+		buf = buf[:i+size]
+	}
+	if i > int64(len(buf)) || !inRange(buf, int(i), flatbuffers.SizeUOffsetT) {
+		return nil, 0, t.err("", int(offset), "root offset is out of range")
+	}
+	pos := i + int64(flatbuffers.GetUOffsetT(buf[i:]))
+	if pos >= int64(len(buf)) {
+		return nil, 0, t.err("", int(i), "root table offset is out of range")
+	}
+	return buf, int(pos), nil
+}
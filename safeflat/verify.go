@@ -0,0 +1,363 @@
+package safeflat
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/gogama/flatgeobuf-convert/props"
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+const (
+	// maxDepth is the deepest nesting of geometry parts which a
+	// Verifier accepts.
+	maxDepth = 64
+	// maxTables is the most tables a Verifier visits in one buffer. It
+	// bounds the work done on a buffer whose offsets make many tables
+	// share the same subtables.
+	maxTables = 1_000_000
+)
+
+// Report lists the problems found by a Verifier in one buffer.
+type Report struct {
+	// Problems lists the problems found, in the order they were found.
+	// It is empty if the buffer is valid.
+	Problems []*Error
+}
+
+// OK reports whether the buffer is valid.
+func (r Report) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// Err returns the problems joined into one error using errors.Join, or
+// nil if the buffer is valid.
+func (r Report) Err() error {
+	if r.OK() {
+		return nil
+	}
+	errs := make([]error, len(r.Problems))
+	for i := range r.Problems {
+		errs[i] = r.Problems[i]
+	}
+	return errors.Join(errs...)
+}
+
+// Verifier checks complete FlatGeobuf header and feature buffers before
+// they are decoded, in the same spirit as the FlatBuffers verifier. It
+// checks that every table, vector, and string offset is within the
+// buffer, that geometries are consistent, and that property buffers
+// suit the schema.
+//
+// For each geometry, the Verifier checks that the Xy vector has an even
+// length, that the Ends vector is monotonically non-decreasing and does
+// not go past the last point, and that the Z, M, T, and Tm vectors are
+// either empty or have one element per point. Parts are checked in the
+// same way, to a nesting depth of 64.
+//
+// Property buffers are checked as props.ParseLenient would parse them,
+// for required columns which have no value, and for string, JSON, and
+// date/time values which aren't valid UTF-8.
+//
+// The Verifier doesn't decode values, and reuses its storage between
+// calls, so verifying every feature of a file reaches zero steady-state
+// allocations for valid features. The zero value is ready to use.
+//
+// A Verifier is not safe for concurrent use.
+type Verifier struct {
+	// schema is the schema of the last header verified, used to check
+	// the properties of features which have no columns of their own.
+	schema   *props.Schema
+	props    props.Props
+	problems []*Error
+	tables   int
+}
+
+// SetSchema sets the schema used to check the properties of features
+// which have no columns of their own. VerifyHeader sets it to the
+// schema of the header, so there is only need to call SetSchema if
+// the header was read some other way.
+func (v *Verifier) SetSchema(schema *props.Schema) {
+	v.schema = schema
+}
+
+// VerifyHeader checks a size-prefixed header buffer, which is how the
+// header is stored in a FlatGeobuf file after the magic bytes. If the
+// header is valid, its schema is used to check the properties of the
+// features verified after it.
+//
+// The returned Report belongs to the Verifier and is only valid until
+// the next call to VerifyHeader or VerifyFeature.
+func (v *Verifier) VerifyHeader(buf []byte) Report {
+	v.start()
+	v.schema = nil
+	var h Header
+	if !v.root(&h.t, "Header", buf) {
+		return v.report()
+	}
+	t := &h.t
+	v.str(t, 4, "name")
+	v.vector(t, 6, flatbuffers.SizeFloat64, "envelope")
+	v.geometryType(t, 8, "geometry_type")
+	v.scalar(t, 10, flatbuffers.SizeBool, "has_z")
+	v.scalar(t, 12, flatbuffers.SizeBool, "has_m")
+	v.scalar(t, 14, flatbuffers.SizeBool, "has_t")
+	v.scalar(t, 16, flatbuffers.SizeBool, "has_tm")
+	columnsOK := v.columns(t, 18)
+	v.scalar(t, 20, flatbuffers.SizeUint64, "features_count")
+	v.scalar(t, 22, flatbuffers.SizeUint16, "index_node_size")
+	v.crs(t, 24)
+	v.str(t, 26, "title")
+	v.str(t, 28, "description")
+	v.str(t, 30, "metadata")
+	if columnsOK {
+		schema, err := h.Schema()
+		if v.add(err) {
+			v.schema = schema
+		}
+	}
+	return v.report()
+}
+
+// VerifyFeature checks a size-prefixed feature buffer, which is how each
+// feature is stored in a FlatGeobuf file. The properties are checked
+// against the feature's own columns if it has any, and otherwise
+// against the schema of the last header verified or the schema set by
+// SetSchema.
+//
+// The returned Report belongs to the Verifier and is only valid until
+// the next call to VerifyHeader or VerifyFeature.
+func (v *Verifier) VerifyFeature(buf []byte) Report {
+	v.start()
+	var f Feature
+	if !v.root(&f.t, "Feature", buf) {
+		return v.report()
+	}
+	t := &f.t
+	i, err := t.indirect(4, "geometry")
+	var g table
+	if v.add(err) && i >= 0 && v.init(&g, "Geometry", t.buf, i) {
+		v.geometry(g, 1)
+	}
+	start, n, ok := v.vector(t, 6, 1, "properties")
+	if !v.columns(t, 8) || !ok || n == 0 {
+		return v.report()
+	}
+	schema := v.schema
+	if own, _ := t.vectorLen(8, flatbuffers.SizeUOffsetT, "columns"); own > 0 {
+		if schema, err = f.Schema(); !v.add(err) {
+			return v.report()
+		}
+	}
+	v.properties(t, schema, start, n)
+	return v.report()
+}
+
+// start clears the problems found in the previous buffer.
+func (v *Verifier) start() {
+	for i := range v.problems {
+		v.problems[i] = nil
+	}
+	v.problems = v.problems[:0]
+	v.tables = 0
+}
+
+func (v *Verifier) report() Report {
+	return Report{Problems: v.problems}
+}
+
+// add records err, if it isn't nil, as a problem. It reports whether
+// err is nil.
+func (v *Verifier) add(err error) bool {
+	if err == nil {
+		return true
+	}
+	var e *Error
+	if !errors.As(err, &e) {
+		e = &Error{Reason: err.Error()}
+	}
+	v.problems = append(v.problems, e)
+	return false
+}
+
+// root points t at the root table of the size-prefixed buffer buf.
+func (v *Verifier) root(t *table, name string, buf []byte) bool {
+	buf, pos, err := root(name, buf, 0, true)
+	return v.add(err) && v.init(t, name, buf, pos)
+}
+
+// init points t at the table at position pos of buf, counting it
+// towards maxTables.
+func (v *Verifier) init(t *table, name string, buf []byte, pos int) bool {
+	if v.tables++; v.tables > maxTables {
+		if v.tables == maxTables+1 {
+			t.name = name
+			v.add(t.err("", pos, "more than %d tables", maxTables))
+		}
+		return false
+	}
+	return v.add(t.init(name, buf, pos))
+}
+
+func (v *Verifier) scalar(t *table, slot, size int, field string) {
+	_, err := t.field(slot, size, field)
+	v.add(err)
+}
+
+func (v *Verifier) str(t *table, slot int, field string) {
+	_, err := t.bytes(slot, field, true)
+	v.add(err)
+}
+
+// vector checks the vector in vtable slot slot, returning the position
+// of its first element and its length, and reporting whether it is
+// valid.
+func (v *Verifier) vector(t *table, slot, size int, field string) (int, int, bool) {
+	start, n, err := t.vector(slot, size, field)
+	return start, n, v.add(err)
+}
+
+func (v *Verifier) geometryType(t *table, slot int, field string) {
+	i, err := t.field(slot, flatbuffers.SizeByte, field)
+	if !v.add(err) || i < 0 {
+		return
+	} else if g := flat.GeometryType(t.buf[i]); g > flat.GeometryTypeTriangle {
+		v.add(t.err(field, i, "invalid geometry type %d", g))
+	}
+}
+
+// columns checks the vector of columns in vtable slot slot, and reports
+// whether every column is valid.
+func (v *Verifier) columns(t *table, slot int) bool {
+	start, n, ok := v.vector(t, slot, flatbuffers.SizeUOffsetT, "columns")
+	for j := 0; j < n; j++ {
+		i, err := t.follow(start+j*flatbuffers.SizeUOffsetT, "columns")
+		var c table
+		if !v.add(err) || !v.init(&c, "Column", t.buf, i) {
+			ok = false
+			continue
+		}
+		count := len(v.problems)
+		v.str(&c, 4, "name")
+		i, err = c.field(6, flatbuffers.SizeByte, "type")
+		if v.add(err) && i >= 0 && flat.ColumnType(c.buf[i]) > flat.ColumnTypeBinary {
+			v.add(c.err("type", i, "invalid column type %d", c.buf[i]))
+		}
+		v.str(&c, 8, "title")
+		v.str(&c, 10, "description")
+		v.scalar(&c, 12, flatbuffers.SizeInt32, "width")
+		v.scalar(&c, 14, flatbuffers.SizeInt32, "precision")
+		v.scalar(&c, 16, flatbuffers.SizeInt32, "scale")
+		v.scalar(&c, 18, flatbuffers.SizeBool, "nullable")
+		v.scalar(&c, 20, flatbuffers.SizeBool, "unique")
+		v.scalar(&c, 22, flatbuffers.SizeBool, "primary_key")
+		v.str(&c, 24, "metadata")
+		ok = ok && len(v.problems) == count
+	}
+	return ok
+}
+
+func (v *Verifier) crs(t *table, slot int) {
+	i, err := t.indirect(slot, "crs")
+	var c table
+	if !v.add(err) || i < 0 || !v.init(&c, "Crs", t.buf, i) {
+		return
+	}
+	v.str(&c, 4, "org")
+	v.scalar(&c, 6, flatbuffers.SizeInt32, "code")
+	v.str(&c, 8, "name")
+	v.str(&c, 10, "description")
+	v.str(&c, 12, "wkt")
+	v.str(&c, 14, "code_string")
+}
+
+// geometry checks the geometry table t, which is nested depth levels
+// deep, and its parts. It takes t by value, since escape analysis would
+// move a table passed by pointer through the recursion to the heap.
+func (v *Verifier) geometry(tv table, depth int) {
+	t := &tv
+	_, xy, ok := v.vector(t, 6, flatbuffers.SizeFloat64, "xy")
+	if ok && xy%2 != 0 {
+		v.add(t.err("xy", t.pos, "odd length %d", xy))
+	}
+	points := xy / 2
+	if start, n, ok := v.vector(t, 4, flatbuffers.SizeUint32, "ends"); ok {
+		prev := uint32(0)
+		for j := 0; j < n; j++ {
+			i := start + j*flatbuffers.SizeUint32
+			end := flatbuffers.GetUint32(t.buf[i:])
+			if end < prev {
+				v.add(t.err("ends", i, "ends[%d] is %d, less than the previous end %d", j, end, prev))
+			} else if uint64(end) > uint64(points) {
+				v.add(t.err("ends", i, "ends[%d] is %d, more than the %d points", j, end, points))
+			}
+			prev = end
+		}
+	}
+	v.ordinates(t, 8, flatbuffers.SizeFloat64, "z", points)
+	v.ordinates(t, 10, flatbuffers.SizeFloat64, "m", points)
+	v.ordinates(t, 12, flatbuffers.SizeFloat64, "t", points)
+	v.ordinates(t, 14, flatbuffers.SizeUint64, "tm", points)
+	v.geometryType(t, 16, "type")
+	start, n, _ := v.vector(t, 18, flatbuffers.SizeUOffsetT, "parts")
+	if n > 0 && depth >= maxDepth {
+		v.add(t.err("parts", start, "parts nested more than %d deep", maxDepth))
+		return
+	}
+	for j := 0; j < n; j++ {
+		i, err := t.follow(start+j*flatbuffers.SizeUOffsetT, "parts")
+		var part table
+		if v.add(err) && v.init(&part, "Geometry", t.buf, i) {
+			v.geometry(part, depth+1)
+		}
+	}
+}
+
+// ordinates checks that the vector of per-point values in vtable slot
+// slot is either empty or has one element per point.
+func (v *Verifier) ordinates(t *table, slot, size int, field string, points int) {
+	start, n, ok := v.vector(t, slot, size, field)
+	if ok && n != 0 && n != points {
+		v.add(t.err(field, start, "length %d does not match %d points", n, points))
+	}
+}
+
+// properties checks the property buffer of n bytes at position start of
+// the feature table t under schema.
+func (v *Verifier) properties(t *table, schema *props.Schema, start, n int) {
+	if schema == nil {
+		v.add(t.err("properties", start, "no schema to check properties against"))
+		return
+	}
+	p := &v.props
+	p.Reset(schema, t.buf[start:start+n])
+	_ = p.Parse()
+	for _, w := range p.Warnings() {
+		reason := w.Reason
+		if w.Col >= 0 {
+			reason = fmt.Sprintf("column %d: %s", w.Col, w.Reason)
+		}
+		v.add(t.err("properties", start+w.Offset, "%s", reason))
+	}
+	for col := 0; col < schema.ColumnsLength(); col++ {
+		var s string
+		var err error
+		switch schema.Type(col) {
+		case flat.ColumnTypeString:
+			s, err = p.StringView(col)
+		case flat.ColumnTypeJson:
+			s, err = p.JSONView(col)
+		case flat.ColumnTypeDateTime:
+			s, err = p.DateTimeStringView(col)
+		}
+		if err == nil && !utf8.ValidString(s) {
+			v.add(t.err("properties", start, "column %d (%q): value is not valid UTF-8", col, schema.Name(col)))
+		}
+		if schema.Column(col).Required && !p.Has(col) {
+			v.add(t.err("properties", start, "column %d (%q): required column has no value", col, schema.Name(col)))
+		}
+	}
+	p.Reset(schema, nil)
+}
@@ -0,0 +1,255 @@
+package safeflat
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gogama/flatgeobuf-convert/props"
+	"github.com/gogama/flatgeobuf/flatgeobuf/flat"
+)
+
+// nested returns a geometry whose parts are nested depth levels deep.
+func nested(depth int) geom {
+	g := point
+	for i := 1; i < depth; i++ {
+		g = geom{typ: flat.GeometryTypeGeometryCollection, parts: []geom{g}}
+	}
+	return g
+}
+
+// propertiesWith returns a property buffer under testColumns, set by f.
+func propertiesWith(t *testing.T, f func(p *props.Props) error) []byte {
+	t.Helper()
+	p := props.NewProps(props.NewSchema(testColumns))
+	if err := f(p); err != nil {
+		t.Fatal(err)
+	}
+	b, err := p.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestVerifyHeader(t *testing.T) {
+	var v Verifier
+	if r := v.VerifyHeader(buildHeader(testColumns)); !r.OK() || r.Err() != nil {
+		t.Fatalf("valid header: got problems %v", r.Err())
+	}
+	if v.schema == nil || v.schema.ColumnsLength() != len(testColumns) {
+		t.Errorf("got schema %v, want the header's %d columns", v.schema, len(testColumns))
+	}
+	bad := append([]props.Column{}, testColumns...)
+	bad[1].Type = flat.ColumnType(99)
+	r := v.VerifyHeader(buildHeader(bad))
+	if r.OK() || !errors.Is(r.Err(), ErrCorrupt) {
+		t.Fatalf("invalid column type: got problems %v, want ErrCorrupt", r.Err())
+	} else if e := r.Problems[0]; e.Table != "Column" || e.Field != "type" {
+		t.Errorf("invalid column type: got problem %v, want Column.type", e)
+	}
+	if v.schema != nil {
+		t.Errorf("got schema %v after invalid header, want nil", v.schema)
+	}
+}
+
+func TestVerifyFeature(t *testing.T) {
+	valid := testProperties(t)
+	testCases := []struct {
+		name string
+		g    *geom
+		// properties and cols are the property buffer and the feature's
+		// own columns.
+		properties []byte
+		cols       []props.Column
+		// table and field are those of the first problem, or empty if
+		// the feature is valid.
+		table string
+		field string
+	}{
+		{name: "valid", g: &multiLine, properties: valid},
+		{name: "no geometry or properties"},
+		{name: "own columns", g: &point, properties: valid, cols: testColumns},
+		{name: "nested to limit", g: ptr(nested(maxDepth))},
+		{
+			name:  "odd xy",
+			g:     &geom{typ: flat.GeometryTypeLineString, xy: []float64{0, 0, 1}},
+			table: "Geometry", field: "xy",
+		},
+		{
+			name:  "ends decrease",
+			g:     &geom{typ: flat.GeometryTypePolygon, xy: []float64{0, 0, 1, 1, 2, 2, 3, 3}, ends: []uint32{3, 2, 4}},
+			table: "Geometry", field: "ends",
+		},
+		{
+			name:  "ends past points",
+			g:     &geom{typ: flat.GeometryTypePolygon, xy: []float64{0, 0, 1, 1}, ends: []uint32{3}},
+			table: "Geometry", field: "ends",
+		},
+		{
+			name:  "z length",
+			g:     &geom{typ: flat.GeometryTypeLineString, xy: []float64{0, 0, 1, 1}, z: []float64{1}},
+			table: "Geometry", field: "z",
+		},
+		{
+			name:  "m length",
+			g:     &geom{typ: flat.GeometryTypeLineString, xy: []float64{0, 0, 1, 1}, m: []float64{1, 2, 3}},
+			table: "Geometry", field: "m",
+		},
+		{
+			name:  "tm length",
+			g:     &geom{typ: flat.GeometryTypePoint, xy: []float64{0, 0}, tm: []uint64{1, 2}},
+			table: "Geometry", field: "tm",
+		},
+		{
+			name:  "invalid geometry type",
+			g:     &geom{typ: flat.GeometryType(200), xy: []float64{0, 0}},
+			table: "Geometry", field: "type",
+		},
+		{
+			name: "invalid part",
+			g: &geom{typ: flat.GeometryTypeMultiPoint, parts: []geom{
+				point,
+				{typ: flat.GeometryTypePoint, xy: []float64{1}},
+			}},
+			table: "Geometry", field: "xy",
+		},
+		{
+			name:  "nested too deep",
+			g:     ptr(nested(maxDepth + 1)),
+			table: "Geometry", field: "parts",
+		},
+		{
+			name:       "truncated properties",
+			properties: valid[:len(valid)-1],
+			table:      "Feature", field: "properties",
+		},
+		{
+			name:       "unknown column",
+			properties: []byte{9, 0, 1, 2, 3, 4},
+			table:      "Feature", field: "properties",
+		},
+		{
+			name: "duplicate column",
+			properties: append(propertiesWith(t, func(p *props.Props) error { return p.SetLong(0, 1) }),
+				propertiesWith(t, func(p *props.Props) error { return p.SetLong(0, 2) })...),
+			table: "Feature", field: "properties",
+		},
+		{
+			name:       "required column missing",
+			properties: propertiesWith(t, func(p *props.Props) error { return p.SetString(1, "x") }),
+			table:      "Feature", field: "properties",
+		},
+		{
+			name: "invalid UTF-8",
+			properties: propertiesWith(t, func(p *props.Props) error {
+				if err := p.SetLong(0, 1); err != nil {
+					return err
+				}
+				return p.SetString(1, "\xff\xfe")
+			}),
+			table: "Feature", field: "properties",
+		},
+	}
+	var v Verifier
+	v.SetSchema(props.NewSchema(testColumns))
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			r := v.VerifyFeature(buildFeature(testCase.g, testCase.properties, testCase.cols))
+			if testCase.table == "" {
+				if !r.OK() {
+					t.Errorf("got problems %v", r.Err())
+				}
+				return
+			}
+			if r.OK() {
+				t.Fatal("got no problems")
+			} else if !errors.Is(r.Err(), ErrCorrupt) {
+				t.Errorf("got error %v, want ErrCorrupt", r.Err())
+			}
+			if e := r.Problems[0]; e.Table != testCase.table || e.Field != testCase.field {
+				t.Errorf("got problem %v, want one in %s.%s", e, testCase.table, testCase.field)
+			}
+		})
+	}
+}
+
+func ptr(g geom) *geom {
+	return &g
+}
+
+func TestVerifyFeatureNoSchema(t *testing.T) {
+	var v Verifier
+	r := v.VerifyFeature(buildFeature(&point, testProperties(t), nil))
+	if r.OK() || r.Problems[0].Field != "properties" {
+		t.Errorf("got problems %v, want a properties problem", r.Err())
+	}
+	if r = v.VerifyFeature(buildFeature(&point, nil, nil)); !r.OK() {
+		t.Errorf("feature without properties: got problems %v", r.Err())
+	}
+}
+
+func TestVerifyTruncated(t *testing.T) {
+	var v Verifier
+	testCases := []struct {
+		name   string
+		buf    []byte
+		verify func(buf []byte) Report
+	}{
+		{"header", buildHeader(testColumns), v.VerifyHeader},
+		{"feature", buildFeature(&multiLine, testProperties(t), testColumns), v.VerifyFeature},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			for n := 0; n < len(testCase.buf); n++ {
+				r := testCase.verify(testCase.buf[:n])
+				if r.OK() {
+					t.Errorf("%d of %d bytes: got no problems", n, len(testCase.buf))
+				} else if !errors.Is(r.Err(), ErrCorrupt) {
+					t.Errorf("%d of %d bytes: got error %v, want ErrCorrupt", n, len(testCase.buf), r.Err())
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyMutated(t *testing.T) {
+	var v Verifier
+	v.SetSchema(props.NewSchema(testColumns))
+	mutations(buildHeader(testColumns), func(b []byte) {
+		if err := v.VerifyHeader(b).Err(); err != nil && !errors.Is(err, ErrCorrupt) {
+			t.Fatalf("%x: got error %v, want ErrCorrupt", b, err)
+		}
+	})
+	v.SetSchema(props.NewSchema(testColumns))
+	mutations(buildFeature(&multiLine, testProperties(t), testColumns), func(b []byte) {
+		if err := v.VerifyFeature(b).Err(); err != nil && !errors.Is(err, ErrCorrupt) {
+			t.Fatalf("%x: got error %v, want ErrCorrupt", b, err)
+		}
+	})
+}
+
+func TestVerifyReportReused(t *testing.T) {
+	var v Verifier
+	if r := v.VerifyFeature([]byte{1, 2}); r.OK() {
+		t.Fatal("got no problems for 2 byte buffer")
+	}
+	if r := v.VerifyHeader(buildHeader(testColumns)); !r.OK() {
+		t.Errorf("got problems %v carried over from previous buffer", r.Err())
+	}
+}
+
+func TestVerifyAllocations(t *testing.T) {
+	var v Verifier
+	if r := v.VerifyHeader(buildHeader(testColumns)); !r.OK() {
+		t.Fatal(r.Err())
+	}
+	buf := buildFeature(&multiLine, testProperties(t), nil)
+	allocs := testing.AllocsPerRun(100, func() {
+		if !v.VerifyFeature(buf).OK() {
+			t.Fatal("got problems")
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("got %v allocations per valid feature, want 0", allocs)
+	}
+}